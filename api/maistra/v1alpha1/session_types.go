@@ -42,6 +42,10 @@ type SessionSpec struct {
 	Route Route `json:"route,omitempty"`
 	// Who should participate in the given session
 	Refs []Ref `json:"ref,omitempty"`
+	// Label key holding the version of the targets, e.g. app.kubernetes.io/version. The operator default is used if not provided.
+	VersionLabel string `json:"versionLabel,omitempty"`
+	// Undo all the changes applied for the ref when any of them fails
+	Transactional bool `json:"transactional,omitempty"`
 }
 
 // Ref defines how to target a single Deployment or DeploymentConfig.
@@ -58,12 +62,16 @@ type Ref struct {
 // Route defines the strategy for how the traffic is routed to the Ref.
 // +k8s:openapi-gen=true
 type Route struct {
-	// The type of route to use, e.g. header
+	// The type of route to use, e.g. header or baggage
 	Type string `json:"type,omitempty"`
 	// Name of the key, e.g. http header
 	Name string `json:"name,omitempty"`
 	// The value to use for routing
 	Value string `json:"value,omitempty"`
+	// Propagate the route header across services which do not forward it, using baggage and trace context headers
+	Propagate bool `json:"propagate,omitempty"`
+	// Expose in-mesh alias host <service>-<session> for each target, routing directly to the Session without the need of the route
+	Alias bool `json:"alias,omitempty"`
 }
 
 func (r *Route) String() string {
@@ -107,27 +115,26 @@ type StatusComponents struct {
 func (s *StatusComponents) SetPending(comp string) {
 	s.Ready = s.removeFrom(s.Ready, comp)
 	s.Unready = s.removeFrom(s.Unready, comp)
-	s.Pending = append(s.Pending, comp)
+	s.Pending = append(s.removeFrom(s.Pending, comp), comp)
 }
 
 func (s *StatusComponents) SetReady(comp string) {
 	s.Pending = s.removeFrom(s.Pending, comp)
 	s.Unready = s.removeFrom(s.Unready, comp)
-	s.Ready = append(s.Ready, comp)
+	s.Ready = append(s.removeFrom(s.Ready, comp), comp)
 }
 
 func (s *StatusComponents) SetUnready(comp string) {
 	s.Ready = s.removeFrom(s.Ready, comp)
 	s.Pending = s.removeFrom(s.Pending, comp)
-	s.Unready = append(s.Unready, comp)
+	s.Unready = append(s.removeFrom(s.Unready, comp), comp)
 }
 
+// removeFrom drops the component from the list, keeping the order of the others.
 func (s *StatusComponents) removeFrom(list []string, comp string) []string {
 	for i := range list {
 		if list[i] == comp {
-			list[i] = list[len(list)-1]
-
-			return list[:len(list)-1]
+			return append(list[:i], list[i+1:]...)
 		}
 	}
 
@@ -158,7 +165,9 @@ type Source struct {
 	Name      string `json:"name,omitempty"`
 	Namespace string `json:"namespace,omitempty"`
 	Ref       string `json:"ref,omitempty"`
-	Kind      string `json:"kind,omitempty"`
+	// RefHash identifies the version of the ref the condition was reported for, when it matters
+	RefHash string `json:"refHash,omitempty"`
+	Kind    string `json:"kind,omitempty"`
 }
 
 type Target struct {
@@ -218,7 +227,8 @@ func (s *Session) RemoveFinalizer(finalizer string) {
 	s.Finalizers = finalizers
 }
 
-// AddCondition adds or replaces a condition based on Name, Kind and Ref as a key.
+// AddCondition adds or replaces a condition based on Name, Kind and Ref as a key. Conditions of the Session itself are
+// also keyed by Type and Target, so the ones reported for different resources do not replace each other.
 func (s *Session) AddCondition(condition Condition) {
 	replaced := false
 
@@ -234,7 +244,8 @@ func (s *Session) AddCondition(condition Condition) {
 
 		if (stored.Source.Kind == sessionKind &&
 			matchSource &&
-			*stored.Type == *condition.Type) ||
+			*stored.Type == *condition.Type &&
+			sameTarget(stored.Target, condition.Target)) ||
 			(stored.Source.Kind != sessionKind &&
 				matchSource) {
 			s.Status.Conditions[i] = &condition
@@ -246,6 +257,14 @@ func (s *Session) AddCondition(condition Condition) {
 	}
 }
 
+func sameTarget(stored, target *Target) bool {
+	if stored == nil || target == nil {
+		return stored == target
+	}
+
+	return *stored == *target
+}
+
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
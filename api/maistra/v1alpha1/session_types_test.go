@@ -64,5 +64,42 @@ var _ = Describe("API manipulation", func() {
 			Expect(components.Unready).To(HaveLen(1))
 			Expect(components.Ready).To(HaveLen(1))
 		})
+
+		It("should keep the order of the other components", func() {
+			components.SetReady(componentOne)
+			components.SetReady(componentTwo)
+			components.SetReady("three")
+			components.SetUnready(componentOne)
+
+			Expect(components.Ready).To(Equal([]string{componentTwo, "three"}))
+		})
+	})
+
+	Context("when adding conditions", func() {
+
+		sessionCondition := func(typeName, targetName, message string) v1alpha1.Condition {
+			return v1alpha1.Condition{
+				Source:  v1alpha1.Source{Kind: "Session", Name: "session", Ref: "ref"},
+				Target:  &v1alpha1.Target{Kind: "VirtualService", Name: targetName},
+				Type:    &typeName,
+				Message: &message,
+			}
+		}
+
+		It("should replace condition of the session for the same target", func() {
+			session := v1alpha1.Session{}
+			session.AddCondition(sessionCondition("Drifted", "one", "first"))
+			session.AddCondition(sessionCondition("Drifted", "one", "second"))
+
+			Expect(session.Status.Conditions).To(HaveLen(1))
+			Expect(*session.Status.Conditions[0].Message).To(Equal("second"))
+		})
+		It("should keep conditions of the session for different targets", func() {
+			session := v1alpha1.Session{}
+			session.AddCondition(sessionCondition("Drifted", "one", "first"))
+			session.AddCondition(sessionCondition("Drifted", "two", "second"))
+
+			Expect(session.Status.Conditions).To(HaveLen(2))
+		})
 	})
 })
@@ -11,6 +11,11 @@ import (
 	"github.com/maistra/istio-workspace/pkg/cmd/delete"
 	"github.com/maistra/istio-workspace/pkg/cmd/develop"
 	"github.com/maistra/istio-workspace/pkg/cmd/execute"
+	"github.com/maistra/istio-workspace/pkg/cmd/join"
+	"github.com/maistra/istio-workspace/pkg/cmd/leave"
+	"github.com/maistra/istio-workspace/pkg/cmd/list"
+	"github.com/maistra/istio-workspace/pkg/cmd/propagation"
+	"github.com/maistra/istio-workspace/pkg/cmd/route"
 	"github.com/maistra/istio-workspace/pkg/cmd/serve"
 	"github.com/maistra/istio-workspace/pkg/cmd/version"
 	"github.com/maistra/istio-workspace/pkg/hook"
@@ -37,6 +42,11 @@ func main() {
 		version.NewCmd(),
 		create.NewCmd(),
 		delete.NewCmd(),
+		list.NewCmd(),
+		join.NewCmd(),
+		leave.NewCmd(),
+		route.NewCmd(),
+		propagation.NewCmd(),
 		develop.NewCmd(),
 		execute.NewCmd(),
 		serve.NewCmd(),
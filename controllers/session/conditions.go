@@ -2,6 +2,7 @@ package session
 
 import (
 	"strconv"
+	"time"
 
 	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
 	"github.com/maistra/istio-workspace/pkg/model"
@@ -9,6 +10,23 @@ import (
 	"golang.org/x/text/language"
 )
 
+const (
+	// WarningReason marks conditions which do not fail the session, but point to a problem the user should be aware of.
+	WarningReason = "Warning"
+
+	// DriftedReason marks conditions of the resources which had to be restored, as they no longer carried the session changes.
+	DriftedReason = "Drifted"
+
+	// PendingReason marks conditions of the changes made, but not in effect yet, e.g. the cloned workload still rolling out.
+	PendingReason = "Pending"
+
+	// RolledBackReason marks the condition of the ref which changes were undone after it failed in the transactional session.
+	RolledBackReason = "RolledBack"
+
+	// driftedConditionTTL is the time the condition of the restored resource is kept for, unless the resource drifts again.
+	driftedConditionTTL = 10 * time.Minute
+)
+
 func createConditionForLocatedRef(ref model.Ref, located model.LocatorStatus) istiov1alpha1.Condition {
 	message := located.GetNamespaceName() + "[" + located.Kind + "] status " + ref.KindName.String() + ": "
 	reason := "Scheduled"
@@ -31,9 +49,14 @@ func createConditionForLocatedRef(ref model.Ref, located model.LocatorStatus) is
 
 func createConditionForModifiedRef(ref model.Ref, modified model.ModificatorStatus) istiov1alpha1.Condition {
 	message := modified.GetNamespaceName() + "[" + modified.Kind + "] modified to satisfy " + ref.KindName.String() + ": "
-	if modified.Error != nil {
+	reason := "Applied"
+	switch {
+	case modified.Error != nil:
 		message += modified.Error.Error()
-	} else {
+	case modified.Prop[model.PropPending] == "true":
+		message += "rollout in progress"
+		reason = PendingReason
+	default:
 		message += "ok"
 	}
 	var target *istiov1alpha1.Target
@@ -45,8 +68,6 @@ func createConditionForModifiedRef(ref model.Ref, modified model.ModificatorStat
 		}
 	}
 	status := strconv.FormatBool(modified.Success)
-
-	reason := "Applied"
 	typeStr := createType(modified.Action, modified.Kind)
 
 	return istiov1alpha1.Condition{
@@ -64,6 +85,154 @@ func createConditionForModifiedRef(ref model.Ref, modified model.ModificatorStat
 	}
 }
 
+// createConditionForUncoveredHosts warns that the session hosts exposed through TLS servers of the Gateway are not covered
+// by the server certificate, so clients will fail to verify them.
+func createConditionForUncoveredHosts(ctx model.SessionContext, ref model.Ref, modified model.ModificatorStatus) istiov1alpha1.Condition {
+	message := "hosts " + modified.Prop["uncoveredHosts"] + " exposed through " + modified.GetNamespaceName() + "[" + modified.Kind + "]" +
+		" are not covered by the TLS certificate of the gateway"
+	reason := WarningReason
+	typeStr := "HostCertificate"
+	status := istiov1alpha1.StatusFailed
+
+	return istiov1alpha1.Condition{
+		Source: istiov1alpha1.Source{
+			Kind:      "Session",
+			Name:      ctx.Name,
+			Namespace: ctx.Namespace,
+			Ref:       ref.KindName.String(),
+		},
+		Message: &message,
+		Reason:  &reason,
+		Status:  &status,
+		Type:    &typeStr,
+	}
+}
+
+// createConditionForDrift reports the resource which no longer carried the changes of the session and was restored.
+func createConditionForDrift(ctx model.SessionContext, ref model.Ref, modified model.ModificatorStatus) istiov1alpha1.Condition {
+	message := modified.GetNamespaceName() + "[" + modified.Kind + "] drifted from the changes applied for " + ref.KindName.String() + ", restored"
+	reason := DriftedReason
+	typeStr := DriftedReason
+	status := strconv.FormatBool(true)
+
+	return istiov1alpha1.Condition{
+		Source: istiov1alpha1.Source{
+			Kind:      "Session",
+			Name:      ctx.Name,
+			Namespace: ctx.Namespace,
+			Ref:       ref.KindName.String(),
+		},
+		Target: &istiov1alpha1.Target{
+			Kind:      modified.Kind,
+			Name:      modified.Name,
+			Namespace: modified.Namespace,
+		},
+		Message: &message,
+		Reason:  &reason,
+		Status:  &status,
+		Type:    &typeStr,
+	}
+}
+
+// createConditionForRollback reports the outcome of undoing the changes applied for the ref which failed.
+func createConditionForRollback(ctx model.SessionContext, ref model.Ref, err error) istiov1alpha1.Condition {
+	message := "changes applied for " + ref.KindName.String() + " rolled back: "
+	if err != nil {
+		message += err.Error()
+	} else {
+		message += "ok"
+	}
+	reason := RolledBackReason
+	typeStr := RolledBackReason
+	status := strconv.FormatBool(err == nil)
+
+	return istiov1alpha1.Condition{
+		Source: istiov1alpha1.Source{
+			Kind:      "Session",
+			Name:      ctx.Name,
+			Namespace: ctx.Namespace,
+			Ref:       ref.KindName.String(),
+			RefHash:   ref.Hash(),
+		},
+		Message: &message,
+		Reason:  &reason,
+		Status:  &status,
+		Type:    &typeStr,
+	}
+}
+
+// driftedConditions returns the conditions of the resources restored within the driftedConditionTTL, so they are kept when the
+// session is reconciled again, e.g. right after the restored resource is written. The older ones are dropped, as the resources
+// have matched the session changes since, otherwise the conditions would have been reported again.
+func driftedConditions(conditions []*istiov1alpha1.Condition, now time.Time) []*istiov1alpha1.Condition {
+	drifted := []*istiov1alpha1.Condition{}
+	for _, condition := range conditions {
+		if condition.Reason != nil && *condition.Reason == DriftedReason &&
+			(condition.LastTransitionTime == nil || condition.LastTransitionTime.Add(driftedConditionTTL).After(now)) {
+			drifted = append(drifted, condition)
+		}
+	}
+
+	return drifted
+}
+
+// driftedConditionsExpiry returns the time until the first of the drifted conditions is dropped, zero when there are none.
+func driftedConditionsExpiry(conditions []*istiov1alpha1.Condition, now time.Time) time.Duration {
+	var expiry time.Duration
+	for _, condition := range driftedConditions(conditions, now) {
+		if condition.LastTransitionTime == nil {
+			continue
+		}
+		if left := condition.LastTransitionTime.Add(driftedConditionTTL).Sub(now); expiry == 0 || left < expiry {
+			expiry = left
+		}
+	}
+
+	return expiry
+}
+
+// rolledBackRefs returns the names of the refs which were rolled back and have not changed since. They are not applied again
+// until their spec changes, as they would only fail and be rolled back on every reconcile.
+func rolledBackRefs(conditions []*istiov1alpha1.Condition, refs []model.Ref) map[string]bool {
+	rolledBack := map[string]bool{}
+	for _, ref := range refs {
+		if ref.Remove {
+			continue
+		}
+		for _, condition := range conditions {
+			if condition.Type != nil && *condition.Type == RolledBackReason && condition.Status != nil && *condition.Status == "true" &&
+				condition.Source.Ref == ref.KindName.String() && condition.Source.RefHash == ref.Hash() {
+				rolledBack[ref.KindName.String()] = true
+			}
+		}
+	}
+
+	return rolledBack
+}
+
+// refConditions returns the conditions reported for the given refs.
+func refConditions(conditions []*istiov1alpha1.Condition, refs map[string]bool) []*istiov1alpha1.Condition {
+	reported := []*istiov1alpha1.Condition{}
+	for _, condition := range conditions {
+		if refs[condition.Source.Ref] {
+			reported = append(reported, condition)
+		}
+	}
+
+	return reported
+}
+
+// reportedBefore tells if the same condition of the resource has been reported already by the previous reconcile.
+func reportedBefore(previous []*istiov1alpha1.Condition, condition istiov1alpha1.Condition) bool {
+	for _, stored := range previous {
+		if stored.Source == condition.Source && stored.Message != nil && condition.Message != nil && *stored.Message == *condition.Message {
+			return true
+		}
+	}
+
+	return false
+}
+
 func createType(action model.StatusAction, kindName string) string {
 	title := cases.Title(language.English)
 
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"context"
+	"strings"
+
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	"github.com/maistra/istio-workspace/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	k8sRecord "k8s.io/client-go/tools/record"
+	k8sReference "k8s.io/client-go/tools/reference"
+)
+
+const (
+	// EventRecorderName is the component name the session events are reported by.
+	EventRecorderName = "istio-workspace"
+
+	// FailedEventReason marks events of the actions which could not be performed.
+	FailedEventReason = "Failed"
+
+	// ValidationFailedEventReason marks events of the refs failing validation.
+	ValidationFailedEventReason = "ValidationFailed"
+
+	// DriftedEventReason marks events of the resources restored after drifting from the session changes.
+	DriftedEventReason = "Drifted"
+
+	// PodFailedEventReason marks events describing the containers of the cloned workloads which fail to run.
+	PodFailedEventReason = "PodFailed"
+
+	// FinalizerRemovedEventReason marks the event of the session being cleaned up.
+	FinalizerRemovedEventReason = "FinalizerRemoved"
+)
+
+var eventReasons = map[model.StatusAction]string{
+	model.ActionCreate: "Created",
+	model.ActionDelete: "Deleted",
+	model.ActionModify: "Modified",
+	model.ActionRevert: "Reverted",
+}
+
+// recordModification lets both the session and the changed resource know about the action performed, so the users of the
+// namespace can see who changed their resources. Resources found already in the desired state are not reported, so
+// reconciling the session again does not repeat the events.
+func recordModification(recorder k8sRecord.EventRecorder, session *istiov1alpha1.Session, ref model.Ref, modified model.ModificatorStatus,
+	writes *WriteTrackingClient) {
+	if recorder == nil || modified.Action == model.ActionLocated {
+		return
+	}
+
+	reason, known := eventReasons[modified.Action]
+	if !known {
+		reason = string(modified.Action)
+	}
+	subject := modified.Kind + " " + modified.GetNamespaceName()
+	if !modified.Success {
+		recorder.Eventf(session, corev1.EventTypeWarning, FailedEventReason, "failed to %s %s for %s: %v",
+			modified.Action, subject, ref.KindName.String(), modified.Error)
+
+		return
+	}
+
+	target := modified.Resource
+	written := writes.Written(target)
+	if modified.Target != nil && writes.Written(*modified.Target) != nil {
+		target = *modified.Target
+		written = writes.Written(target)
+	}
+	if written == nil {
+		return
+	}
+	recorder.Eventf(session, corev1.EventTypeNormal, reason, "%s %s for %s", subject, strings.ToLower(reason), ref.KindName.String())
+
+	involved, err := k8sReference.GetReference(writes.Scheme(), written)
+	if err != nil {
+		return
+	}
+	recorder.Eventf(involved, corev1.EventTypeNormal, reason, "%s %s %s by session %s/%s",
+		target.Kind, target.Name, strings.ToLower(reason), session.Namespace, session.Name)
+}
+
+// recordDrift reports the resource restored after drifting from the session changes.
+func recordDrift(recorder k8sRecord.EventRecorder, session *istiov1alpha1.Session, ref model.Ref, modified model.ModificatorStatus) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Eventf(session, corev1.EventTypeWarning, DriftedEventReason, "%s %s drifted from the changes applied for %s, restored",
+		modified.Kind, modified.GetNamespaceName(), ref.KindName.String())
+}
+
+// recordValidation reports the ref failing validation, including the warnings.
+func recordValidation(recorder k8sRecord.EventRecorder, session *istiov1alpha1.Session, ref model.Ref, typeName string, err error) {
+	if recorder == nil {
+		return
+	}
+
+	reason := ValidationFailedEventReason
+	if isWarning(err) {
+		reason = WarningReason
+	}
+	recorder.Eventf(session, corev1.EventTypeWarning, reason, "%s check of %s: %v", typeName, ref.KindName.String(), err)
+}
+
+// recordPodFailures reports the details of the containers failing to run, such as their restarts and last log lines. They
+// change all the time, so they are reported once the failure is found instead of being part of the condition of the ref.
+func recordPodFailures(ctx context.Context, recorder k8sRecord.EventRecorder, session *istiov1alpha1.Session, ref model.Ref,
+	failed *k8s.PodsFailedError, logs k8s.PodLogsReader) {
+	if recorder == nil {
+		return
+	}
+
+	for _, failure := range failed.Failures {
+		recorder.Eventf(session, corev1.EventTypeWarning, PodFailedEventReason, "%s fails to run: %s", ref.KindName.String(), failure.Details(ctx, logs))
+	}
+}
+
+// recordFinalizerRemoval reports the session being cleaned up.
+func recordFinalizerRemoval(recorder k8sRecord.EventRecorder, session *istiov1alpha1.Session) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Event(session, corev1.EventTypeNormal, FinalizerRemovedEventReason, "all changes reverted, finalizer removed")
+}
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	drifts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "session_drift_total",
+			Help: "Number of resources found drifted from the changes applied by the session and restored",
+		},
+		[]string{"kind", "namespace"},
+	)
+	activeSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "session_active",
+			Help: "Number of sessions in the namespace",
+		},
+		[]string{"namespace"},
+	)
+	refs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "session_refs",
+			Help: "Number of refs participating in the sessions using the strategy",
+		},
+		[]string{"strategy"},
+	)
+	readyDurations = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "session_ready_duration_seconds",
+			Help:    "Time from creating the session until all of its refs are applied for the first time",
+			Buckets: []float64{1, 2.5, 5, 10, 20, 30, 60, 120, 300, 600},
+		},
+	)
+
+	sessions = &sessionTracker{sessions: map[types.NamespacedName]trackedSession{}}
+)
+
+func init() {
+	metrics.Registry.MustRegister(drifts, activeSessions, refs, readyDurations)
+}
+
+// sessionTracker keeps the sessions known to the operator, so the gauges are computed from all of them.
+type sessionTracker struct {
+	lock     sync.Mutex
+	sessions map[types.NamespacedName]trackedSession
+}
+
+type trackedSession struct {
+	strategies []string // of the refs participating in the session
+	ready      bool
+}
+
+// track records the session and the strategies of its refs, returning true when the session is ready for the first time.
+// The session found ready already before the reconcile has been ready before the operator started tracking it.
+func (t *sessionTracker) track(name types.NamespacedName, strategies []string, wasReady, ready bool) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tracked := t.sessions[name]
+	becameReady := ready && !wasReady && !tracked.ready
+	t.sessions[name] = trackedSession{strategies: strategies, ready: tracked.ready || wasReady || ready}
+	t.update()
+
+	return becameReady
+}
+
+// forget removes the session which is gone.
+func (t *sessionTracker) forget(name types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, found := t.sessions[name]; !found {
+		return
+	}
+	delete(t.sessions, name)
+	t.update()
+}
+
+func (t *sessionTracker) update() {
+	activeSessions.Reset()
+	refs.Reset()
+	for name, tracked := range t.sessions {
+		activeSessions.WithLabelValues(name.Namespace).Inc()
+		for _, strategy := range tracked.strategies {
+			refs.WithLabelValues(strategy).Inc()
+		}
+	}
+}
@@ -10,7 +10,10 @@ const (
 	DefaultRouteHeaderName = "x-workspace-route"
 
 	// RouteStrategyHeader holds the Route Type keyword for a Header based Route strategy.
-	RouteStrategyHeader = "header"
+	RouteStrategyHeader = model.RouteTypeHeader
+
+	// RouteStrategyBaggage holds the Route Type keyword for a W3C baggage based Route strategy.
+	RouteStrategyBaggage = model.RouteTypeBaggage
 )
 
 // ConvertAPIRefToModelRef converts a Session.Spec.Ref to a model.Ref.
@@ -21,9 +24,11 @@ func ConvertAPIRefToModelRef(ref istiov1alpha1.Ref, namespace string) model.Ref
 // ConvertModelRouteToAPIRoute returns Model route as a session Route.
 func ConvertModelRouteToAPIRoute(route model.Route) *istiov1alpha1.Route {
 	return &istiov1alpha1.Route{
-		Type:  route.Type,
-		Name:  route.Name,
-		Value: route.Value,
+		Type:      route.Type,
+		Name:      route.Name,
+		Value:     route.Value,
+		Propagate: route.Propagate,
+		Alias:     route.Alias,
 	}
 }
 
@@ -31,15 +36,19 @@ func ConvertModelRouteToAPIRoute(route model.Route) *istiov1alpha1.Route {
 func ConvertAPIRouteToModelRoute(session *istiov1alpha1.Session) model.Route {
 	if session.Spec.Route.Type == "" {
 		return model.Route{
-			Type:  RouteStrategyHeader,
-			Name:  DefaultRouteHeaderName,
-			Value: session.Name,
+			Type:      RouteStrategyHeader,
+			Name:      DefaultRouteHeaderName,
+			Value:     session.Name,
+			Propagate: session.Spec.Route.Propagate,
+			Alias:     session.Spec.Route.Alias,
 		}
 	}
 
 	return model.Route{
-		Type:  session.Spec.Route.Type,
-		Name:  session.Spec.Route.Name,
-		Value: session.Spec.Route.Value,
+		Type:      session.Spec.Route.Type,
+		Name:      session.Spec.Route.Name,
+		Value:     session.Spec.Route.Value,
+		Propagate: session.Spec.Route.Propagate,
+		Alias:     session.Spec.Route.Alias,
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"emperror.dev/errors"
@@ -18,10 +20,16 @@ import (
 	"github.com/maistra/istio-workspace/pkg/reference"
 	"github.com/maistra/istio-workspace/pkg/template"
 	"github.com/operator-framework/operator-lib/handler"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
 	errorsK8s "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	k8sRecord "k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -33,6 +41,35 @@ import (
 const (
 	// Finalizer defines the Finalizer name owned by the Session reconciler.
 	Finalizer = "finalizers.istio.workspace.session"
+
+	// VersionLabelEnvVar holds the name of the environment variable defining the operator wide version label key.
+	VersionLabelEnvVar = "VERSION_LABEL"
+
+	// VirtualServiceNamespacesEnvVar holds the name of the environment variable defining comma-separated list of additional
+	// namespaces searched for VirtualServices routing to the targets, "*" stands for all the namespaces in the mesh.
+	VirtualServiceNamespacesEnvVar = "VIRTUAL_SERVICE_NAMESPACES"
+
+	// GitOpsModeEnvVar holds the name of the environment variable enabling GitOps mode, in which sessions only create
+	// resources of their own and never change the existing ones.
+	GitOpsModeEnvVar = "GITOPS_MODE"
+
+	// MaxConcurrentReconcilesEnvVar holds the name of the environment variable limiting the number of sessions reconciled
+	// at the same time.
+	MaxConcurrentReconcilesEnvVar = "MAX_CONCURRENT_RECONCILES"
+
+	// MaxConcurrentRefsEnvVar holds the name of the environment variable limiting the number of refs of a single session
+	// synced at the same time.
+	MaxConcurrentRefsEnvVar = "MAX_CONCURRENT_REFS"
+
+	// RolloutTimeoutEnvVar holds the name of the environment variable limiting the time the cloned workloads have to become
+	// available in, before the session routes are switched to them. Zero disables waiting for the rollout.
+	RolloutTimeoutEnvVar = "ROLLOUT_TIMEOUT"
+
+	// DefaultRolloutTimeout is the time the cloned workloads have to become available in, unless configured otherwise.
+	DefaultRolloutTimeout = 5 * time.Minute
+
+	// rolloutCheckInterval is the time after which the session waiting for the rollout of its workloads is reconciled again.
+	rolloutCheckInterval = 5 * time.Second
 )
 
 var (
@@ -46,6 +83,8 @@ func DefaultValidators() []Validator {
 		TargetFound,
 		ResourceFound("DestinationRule"),
 		ResourceFound("VirtualService"),
+		RouteVisibility,
+		RoutePropagation,
 	}
 }
 
@@ -66,6 +105,9 @@ func DefaultManipulators() Manipulators {
 			istio.VirtualServiceLocator,
 			istio.DestinationRuleLocator,
 			istio.VirtualServiceGatewayLocator,
+			istio.EnvoyFilterLocator,
+			openshift.RouteLocator,
+			k8s.IngressLocator,
 		},
 		Handlers: []model.ModificatorRegistrar{
 			k8s.DeploymentRegistrar(engine),
@@ -74,6 +116,9 @@ func DefaultManipulators() Manipulators {
 			istio.DestinationRuleRegistrar,
 			istio.GatewayRegistrar,
 			istio.VirtualServiceRegistrar,
+			istio.EnvoyFilterRegistrar,
+			openshift.RouteRegistrar,
+			k8s.IngressRegistrar,
 		},
 	}
 }
@@ -86,18 +131,89 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler.
 func newReconciler(mgr manager.Manager) *ReconcileSession {
-	return &ReconcileSession{client: mgr.GetClient(), scheme: mgr.GetScheme(), manipulators: DefaultManipulators(), validators: DefaultValidators()}
+	gitOps, _ := strconv.ParseBool(os.Getenv(GitOpsModeEnvVar))
+	maxConcurrentReconciles, _ := strconv.Atoi(os.Getenv(MaxConcurrentReconcilesEnvVar))
+	maxConcurrentRefs, _ := strconv.Atoi(os.Getenv(MaxConcurrentRefsEnvVar))
+	rolloutTimeout, err := time.ParseDuration(os.Getenv(RolloutTimeoutEnvVar))
+	if err != nil {
+		rolloutTimeout = DefaultRolloutTimeout
+	}
+	var podLogs k8s.PodLogsReader
+	if clientset, errClientset := kubernetes.NewForConfig(mgr.GetConfig()); errClientset == nil {
+		podLogs = k8s.NewPodLogsReader(clientset)
+	} else {
+		logger().Error(errClientset, "failed creating clientset, logs of the failing pods are not reported")
+	}
+
+	return &ReconcileSession{
+		client:       mgr.GetClient(),
+		apiReader:    mgr.GetAPIReader(),
+		scheme:       mgr.GetScheme(),
+		manipulators: DefaultManipulators(),
+		validators:   DefaultValidators(),
+		versionLabel: os.Getenv(VersionLabelEnvVar),
+		vsNamespaces: parseNamespaces(os.Getenv(VirtualServiceNamespacesEnvVar)),
+		gitOps:       gitOps,
+		recorder:     mgr.GetEventRecorderFor(EventRecorderName),
+
+		maxConcurrentReconciles: maxConcurrentReconciles,
+		maxConcurrentRefs:       maxConcurrentRefs,
+		rolloutTimeout:          rolloutTimeout,
+		podLogs:                 podLogs,
+	}
 }
 
 // NewStandaloneReconciler returns a new reconcile.Reconciler. Primarily used for unit testing outside of the Manager.
 func NewStandaloneReconciler(c client.Client, m Manipulators, validators ...Validator) *ReconcileSession {
-	return &ReconcileSession{client: c, manipulators: m, validators: validators}
+	return &ReconcileSession{client: c, apiReader: c, manipulators: m, validators: validators}
+}
+
+// WithAPIReader sets the reader the latest version of the Session is read with after a conflicting update.
+func (r *ReconcileSession) WithAPIReader(reader client.Reader) *ReconcileSession {
+	r.apiReader = reader
+
+	return r
+}
+
+// WithEventRecorder sets the recorder the session events are reported through.
+func (r *ReconcileSession) WithEventRecorder(recorder k8sRecord.EventRecorder) *ReconcileSession {
+	r.recorder = recorder
+
+	return r
+}
+
+// WithMaxConcurrentReconciles sets the number of sessions reconciled at the same time.
+func (r *ReconcileSession) WithMaxConcurrentReconciles(maxConcurrentReconciles int) *ReconcileSession {
+	r.maxConcurrentReconciles = maxConcurrentReconciles
+
+	return r
+}
+
+// WithMaxConcurrentRefs sets the number of refs of the session synced at the same time.
+func (r *ReconcileSession) WithMaxConcurrentRefs(maxConcurrentRefs int) *ReconcileSession {
+	r.maxConcurrentRefs = maxConcurrentRefs
+
+	return r
+}
+
+// WithRolloutTimeout sets the time the cloned workloads have to become available in, zero disables waiting for them.
+func (r *ReconcileSession) WithRolloutTimeout(rolloutTimeout time.Duration) *ReconcileSession {
+	r.rolloutTimeout = rolloutTimeout
+
+	return r
+}
+
+// WithPodLogsReader sets the reader of the last lines logged by the containers of the cloned workloads which fail to run.
+func (r *ReconcileSession) WithPodLogsReader(podLogs k8s.PodLogsReader) *ReconcileSession {
+	r.podLogs = podLogs
+
+	return r
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler.
 func add(mgr manager.Manager, r *ReconcileSession) error {
 	// Create a new controller
-	c, err := controller.New("session-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("session-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: atLeastOne(r.maxConcurrentReconciles)})
 	if err != nil {
 		return errors.Wrap(err, "failed creating session-controller")
 	}
@@ -128,6 +244,15 @@ func add(mgr manager.Manager, r *ReconcileSession) error {
 		}
 	}
 
+	// pods of the cloned workloads report their failures through the status, which does not change their generation. The
+	// manager is expected to cache only these pods, see k8s.ClonePodsSelector
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &reference.EnqueueRequestForAnnotation{
+		Type: schema.GroupKind{Group: "workspace.maistra.io", Kind: "Session"},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed creating session-controller")
+	}
+
 	return nil
 }
 
@@ -143,10 +268,22 @@ var _ reconcile.Reconciler = &ReconcileSession{}
 type ReconcileSession struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client       client.Client
+	client client.Client
+	// apiReader reads the latest version of the Session directly from the apiserver, as the cached one can be as stale
+	// as the one the conflicting update has been made with
+	apiReader    client.Reader
 	scheme       *runtime.Scheme
 	manipulators Manipulators
 	validators   []Validator
+	versionLabel string
+	vsNamespaces []string
+	gitOps       bool
+	recorder     k8sRecord.EventRecorder
+
+	maxConcurrentReconciles int
+	maxConcurrentRefs       int
+	rolloutTimeout          time.Duration
+	podLogs                 k8s.PodLogsReader
 }
 
 // WatchTypes returns a list of client.Objects to watch for changes.
@@ -160,13 +297,37 @@ func (r ReconcileSession) WatchTypes() []client.Object {
 	return objects
 }
 
+// parseNamespaces splits comma-separated list of namespaces, skipping the empty entries.
+func parseNamespaces(namespaces string) []string {
+	parsed := []string{}
+	for _, namespace := range strings.Split(namespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			parsed = append(parsed, namespace)
+		}
+	}
+
+	return parsed
+}
+
+// getVersionLabel returns the version label key defined by the Session, falling back to the one configured for the operator.
+func (r ReconcileSession) getVersionLabel(session *istiov1alpha1.Session) string {
+	if session.Spec.VersionLabel != "" {
+		return session.Spec.VersionLabel
+	}
+
+	return r.versionLabel
+}
+
 // +kubebuilder:rbac:groups=workspace.maistra.io,resources=sessions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=workspace.maistra.io,resources=sessions/finalizers,verbs=update
 // +kubebuilder:rbac:groups=workspace.maistra.io,resources=sessions/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 // +kubebuilder:rbac:groups="",resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets,verbs=*
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets,verbs=*
 // +kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs,verbs=*
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=*
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=*
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create
 // +kubebuilder:rbac:groups=istio.openshift.com,resources=*,verbs=*
 // +kubebuilder:rbac:groups=networking.istio.io,resources=*,verbs=*
@@ -179,7 +340,7 @@ func (r *ReconcileSession) Reconcile(orgCtx context.Context, request reconcile.R
 	reqLogger := logger().WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling Session")
 
-	c := NewInstrumentedClient(r.client)
+	c := NewSnapshotClient(NewInstrumentedClient(r.client))
 
 	// Fetch the Session instance
 	session := &istiov1alpha1.Session{}
@@ -187,6 +348,8 @@ func (r *ReconcileSession) Reconcile(orgCtx context.Context, request reconcile.R
 
 	if err != nil {
 		if errorsK8s.IsNotFound(err) {
+			sessions.forget(request.NamespacedName)
+
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -195,18 +358,20 @@ func (r *ReconcileSession) Reconcile(orgCtx context.Context, request reconcile.R
 
 	route := ConvertAPIRouteToModelRoute(session)
 	ctx := model.SessionContext{
-		Context:   orgCtx,
-		Name:      request.Name,
-		Namespace: request.Namespace,
-		Route:     route,
-		Log:       reqLogger,
-		Client:    c,
+		Context:                  orgCtx,
+		Name:                     request.Name,
+		Namespace:                request.Namespace,
+		Route:                    route,
+		VersionLabel:             r.getVersionLabel(session),
+		VirtualServiceNamespaces: r.vsNamespaces,
+		GitOps:                   r.gitOps,
+		RolloutTimeout:           r.rolloutTimeout,
+		Log:                      reqLogger,
+		Client:                   c,
 	}
 
-	err = updateSessionRoute(ctx, session, route, c.Status())
-	if err != nil {
-		ctx.Log.Error(err, "Failed to update session.status.route")
-	}
+	wasReady := session.Status.State != nil && *session.Status.State == istiov1alpha1.StateSuccess
+	updateSessionRoute(session, route)
 
 	deleted := session.DeletionTimestamp != nil
 	if deleted {
@@ -217,92 +382,271 @@ func (r *ReconcileSession) Reconcile(orgCtx context.Context, request reconcile.R
 	} else {
 		reqLogger.Info("Added session")
 		if !session.HasFinalizer(Finalizer) {
-			session.AddFinalizer(Finalizer)
-			err = c.Update(ctx, session)
-			if err != nil {
-				ctx.Log.Error(err, "Failed to add finalizer on session")
+			if err = r.updateFinalizers(ctx, session, func() { session.AddFinalizer(Finalizer) }); err != nil {
+				return reconcile.Result{}, errors.WrapWithDetails(err, "failed adding finalizer on session", "session", request.Name)
 			}
 		}
 	}
 
 	refs := calculateReferences(ctx, session)
-	sync := model.NewSync(r.manipulators.Locators, extractModificators(r.manipulators.Handlers))
+	kept := driftedConditions(session.Status.Conditions, time.Now())
+	rolledBack := map[string]bool{}
+	if session.Spec.Transactional { // the outcome of the failed attempt is kept as long as the rolled back ref is skipped
+		rolledBack = rolledBackRefs(session.Status.Conditions, refs)
+		kept = append(kept, refConditions(session.Status.Conditions, rolledBack)...)
+	}
+	previous := session.Status.Conditions
 	session.Status.Conditions = []*istiov1alpha1.Condition{}
+	for _, condition := range kept {
+		session.AddCondition(*condition)
+	}
 	session.Status.Hosts = []string{}
 	session.Status.RefNames = []string{}
 	session.Status.Strategies = []string{}
 
 	for _, ref := range refs {
-		ref := ref // pin
-
 		if !ref.Remove {
 			session.Status.RefNames = unique(append(session.Status.RefNames, ref.KindName.String()))
 			session.Status.Strategies = unique(append(session.Status.Strategies, ref.Strategy))
 		}
-
-		emptyStore := func(kind ...string) []model.LocatorStatus { return []model.LocatorStatus{} }
-		chainValidator(ctx, ref, session, r.validators...)(emptyStore)
-		sync(ctx, ref,
-			chainValidator(ctx, ref, session, r.validators...),
-			func(located model.LocatorStatusStore) {
-				for _, stored := range located() {
-					stored := stored // pin
-					session.Status.Readiness.Components.SetPending(stored.Kind + "/" + stored.Name)
-					session.AddCondition(createConditionForLocatedRef(ref, stored))
-				}
-				err = c.Status().Update(ctx, session)
-				if err != nil {
-					ctx.Log.Error(err, "could not update session", "name", session.Name, "namespace", session.Namespace)
-				}
-			},
-			func(modified model.ModificatorStatus) {
-				if !ref.Remove {
-					if modified.Kind == istio.GatewayKind {
-						session.Status.Hosts = splitAndUnique(session.Status.Hosts, modified.Prop["hosts"])
-					}
-				}
-				if modified.Success {
-					session.Status.Readiness.Components.SetReady(modified.Kind + "/" + modified.Name)
-				} else {
-					session.Status.Readiness.Components.SetUnready(modified.Kind + "/" + modified.Name)
-				}
-				session.AddCondition(createConditionForModifiedRef(ref, modified))
-				err = ctx.Client.Status().Update(ctx, session)
-				if err != nil {
-					ctx.Log.Error(err, "could not update session", "name", session.Name, "namespace", session.Namespace)
-				}
-			})
-		cleanupRelatedConditionsOnRemoval(ref, session)
+	}
+	// refs are merged in order, so the status does not depend on the order they were synced in
+	for i, refSession := range r.syncRefs(ctx, session, previous, refs, rolledBack) {
+		mergeRefStatus(session, refSession)
+		cleanupRelatedConditionsOnRemoval(refs[i], session)
 	}
 	session.Status.State = calculateSessionState(session)
-	err = ctx.Client.Status().Update(ctx, session)
-	if err != nil {
-		ctx.Log.Error(err, "could not update session", "name", session.Name, "namespace", session.Namespace)
+	if err = r.updateStatus(ctx, session); err != nil {
+		return reconcile.Result{}, errors.WrapWithDetails(err, "failed updating session status", "session", request.Name)
 	}
 
 	if deleted {
 		if allConditionsSuccessful(session.Status.Conditions) {
-			session.RemoveFinalizer(Finalizer)
-			if err := c.Update(ctx, session); err != nil {
-				ctx.Log.Error(err, "Failed to remove finalizer on session")
+			if err = r.updateFinalizers(ctx, session, func() { session.RemoveFinalizer(Finalizer) }); err != nil {
+				return reconcile.Result{}, errors.WrapWithDetails(err, "failed removing finalizer on session", "session", request.Name)
 			}
+			recordFinalizerRemoval(r.recorder, session)
+			sessions.forget(request.NamespacedName)
 		}
 
 		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
 	}
 
-	return reconcile.Result{}, nil
+	strategies := []string{}
+	for _, ref := range refs {
+		if !ref.Remove {
+			strategies = append(strategies, ref.Strategy)
+		}
+	}
+	ready := *session.Status.State == istiov1alpha1.StateSuccess
+	if sessions.track(request.NamespacedName, strategies, wasReady, ready) && !session.CreationTimestamp.IsZero() {
+		readyDurations.Observe(time.Since(session.CreationTimestamp.Time).Seconds())
+	}
+
+	if *session.Status.State == istiov1alpha1.StateProcessing { // status changes of the workloads are not watched
+		return reconcile.Result{RequeueAfter: rolloutCheckInterval}, nil
+	}
+
+	// reconciled again to drop the drifted conditions once they expire
+	return reconcile.Result{RequeueAfter: driftedConditionsExpiry(session.Status.Conditions, time.Now())}, nil
 }
 
-func updateSessionRoute(ctx model.SessionContext, session *istiov1alpha1.Session, route model.Route, c client.StatusWriter) error {
+// syncRefs syncs the refs, up to maxConcurrentRefs of them at once. The removed refs are reverted before the others
+// are applied, the skipped ones are not synced at all. Each ref reports its status to a copy of the session of its own,
+// returned in the order of the refs. The previous conditions are the ones reported by the last reconcile.
+func (r *ReconcileSession) syncRefs(ctx model.SessionContext, session *istiov1alpha1.Session, previous []*istiov1alpha1.Condition,
+	refs []model.Ref, skipped map[string]bool) []*istiov1alpha1.Session {
+	syncRef := model.NewSync(r.manipulators.Locators, extractModificators(r.manipulators.Handlers), rolloutGate(r.manipulators.Handlers))
+	refSessions := make([]*istiov1alpha1.Session, len(refs))
+	slots := make(chan struct{}, atLeastOne(r.maxConcurrentRefs))
+	for _, removed := range []bool{true, false} {
+		var wg sync.WaitGroup
+		for i, ref := range refs {
+			if ref.Remove != removed {
+				continue
+			}
+			ref := ref // pin
+			refSession := session.DeepCopy()
+			refSession.Status.Conditions = []*istiov1alpha1.Condition{}
+			refSession.Status.Hosts = []string{}
+			refSession.Status.Readiness = istiov1alpha1.StatusReadiness{Components: istiov1alpha1.StatusComponents{}}
+			refSessions[i] = refSession
+			if skipped[ref.KindName.String()] {
+				ctx.Log.Info("Skipping ref rolled back before", "ref", ref.KindName.String())
+
+				continue
+			}
+
+			wg.Add(1)
+			slots <- struct{}{}
+			go func() {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				r.syncRef(ctx, syncRef, refSession, previous, ref)
+			}()
+		}
+		wg.Wait()
+	}
+
+	return refSessions
+}
+
+func (r *ReconcileSession) syncRef(ctx model.SessionContext, syncRef model.Sync, session *istiov1alpha1.Session,
+	previous []*istiov1alpha1.Condition, ref model.Ref) {
+	writes := NewWriteTrackingClient(ctx.Client)
+	ctx.Client = writes
+	emptyStore := func(kind ...string) []model.LocatorStatus { return []model.LocatorStatus{} }
+	chainValidator(ctx, ref, session, nil, r.validators...)(emptyStore)
+	modificationFailed := false
+	syncRef(ctx, ref,
+		chainValidator(ctx, ref, session, r.recorder, r.validators...),
+		func(located model.LocatorStatusStore) {
+			for _, stored := range located() {
+				stored := stored // pin
+				session.Status.Readiness.Components.SetPending(stored.Kind + "/" + stored.Name)
+				session.AddCondition(createConditionForLocatedRef(ref, stored))
+			}
+		},
+		func(modified model.ModificatorStatus) {
+			if !ref.Remove {
+				if modified.Kind == istio.GatewayKind {
+					session.Status.Hosts = splitAndUnique(session.Status.Hosts, modified.Prop["hosts"])
+					if modified.Prop["uncoveredHosts"] != "" {
+						session.AddCondition(createConditionForUncoveredHosts(ctx, ref, modified))
+					}
+				}
+				if modified.Kind == k8s.ServiceKind && modified.Prop["hosts"] != "" { // in-mesh aliases
+					session.Status.Hosts = splitAndUnique(session.Status.Hosts, modified.Prop["hosts"])
+				}
+			}
+			switch {
+			case !modified.Success:
+				modificationFailed = true
+				session.Status.Readiness.Components.SetUnready(modified.Kind + "/" + modified.Name)
+			case modified.Prop[model.PropPending] == "true":
+				session.Status.Readiness.Components.SetPending(modified.Kind + "/" + modified.Name)
+			default:
+				session.Status.Readiness.Components.SetReady(modified.Kind + "/" + modified.Name)
+			}
+			condition := createConditionForModifiedRef(ref, modified)
+			podsFailed := &k8s.PodsFailedError{}
+			if errors.As(modified.Error, &podsFailed) && !reportedBefore(previous, condition) {
+				recordPodFailures(ctx, r.recorder, session, ref, podsFailed, r.podLogs)
+			}
+			session.AddCondition(condition)
+			recordModification(r.recorder, session, ref, modified, writes)
+			if modified.Prop[istio.PropDrifted] == "true" {
+				session.AddCondition(createConditionForDrift(ctx, ref, modified))
+				recordDrift(r.recorder, session, ref, modified)
+				drifts.WithLabelValues(modified.Kind, modified.Namespace).Inc()
+			}
+		})
+	if modificationFailed && session.Spec.Transactional && !ref.Remove {
+		r.rollbackRef(ctx, syncRef, session, ref)
+	}
+}
+
+// rollbackRef undoes everything already applied for the ref which failed, so the traffic is not routed to partially
+// created resources. The conditions of the failed attempt are kept, as they carry the original error.
+func (r *ReconcileSession) rollbackRef(ctx model.SessionContext, syncRef model.Sync, session *istiov1alpha1.Session, ref model.Ref) {
+	removed := ref
+	removed.Remove = true
+	writes := NewWriteTrackingClient(ctx.Client)
+	ctx.Client = writes
+	var rollbackErr error
+	syncRef(ctx, removed,
+		func(located model.LocatorStatusStore) bool { return true },
+		func(located model.LocatorStatusStore) {},
+		func(modified model.ModificatorStatus) {
+			if !modified.Success {
+				rollbackErr = errors.Append(rollbackErr, modified.Error)
+			}
+			recordModification(r.recorder, session, removed, modified, writes)
+		})
+
+	session.Status.Hosts = []string{}
+	for _, component := range append([]string{}, session.Status.Readiness.Components.Ready...) {
+		session.Status.Readiness.Components.SetUnready(component)
+	}
+	session.AddCondition(createConditionForRollback(ctx, ref, rollbackErr))
+}
+
+// mergeRefStatus adds the status reported by the ref to the session.
+func mergeRefStatus(session, refSession *istiov1alpha1.Session) {
+	for _, condition := range refSession.Status.Conditions {
+		session.AddCondition(*condition)
+	}
+	session.Status.Hosts = unique(append(session.Status.Hosts, refSession.Status.Hosts...))
+	components := refSession.Status.Readiness.Components
+	for _, component := range components.Pending {
+		session.Status.Readiness.Components.SetPending(component)
+	}
+	for _, component := range components.Ready {
+		session.Status.Readiness.Components.SetReady(component)
+	}
+	for _, component := range components.Unready {
+		session.Status.Readiness.Components.SetUnready(component)
+	}
+}
+
+// atLeastOne returns the configured concurrency, which is 1 unless set to a greater value.
+func atLeastOne(concurrency int) int {
+	if concurrency < 1 {
+		return 1
+	}
+
+	return concurrency
+}
+
+func updateSessionRoute(session *istiov1alpha1.Session, route model.Route) {
 	session.Status.Route = ConvertModelRouteToAPIRoute(route)
 	session.Status.RouteExpression = session.Status.Route.String()
 	processing := istiov1alpha1.StateProcessing
 	session.Status.State = &processing
 	session.Status.Readiness = istiov1alpha1.StatusReadiness{Components: istiov1alpha1.StatusComponents{}}
-	err := c.Update(ctx, session)
+}
+
+// updateStatus writes the status computed during the reconcile at once. When the Session has been changed in the meantime
+// the status is written to its latest version instead.
+func (r *ReconcileSession) updateStatus(ctx model.SessionContext, session *istiov1alpha1.Session) error {
+	status := session.Status.DeepCopy()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		session.Status = *status.DeepCopy()
+		err := ctx.Client.Status().Update(ctx, session)
+		if errorsK8s.IsConflict(err) {
+			return r.refreshSession(ctx, session, err)
+		}
+
+		return errors.Wrap(err, "failed updating session status")
+	})
+}
+
+// updateFinalizers applies the finalizers change to the latest version of the Session, keeping the status computed so far.
+func (r *ReconcileSession) updateFinalizers(ctx model.SessionContext, session *istiov1alpha1.Session, change func()) error {
+	status := session.Status.DeepCopy()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		change()
+		err := ctx.Client.Update(ctx, session)
+		session.Status = *status.DeepCopy()
+		if errorsK8s.IsConflict(err) {
+			return r.refreshSession(ctx, session, err)
+		}
+
+		return errors.Wrap(err, "failed updating session finalizers")
+	})
+}
+
+// refreshSession reads the latest version of the Session after the conflict, so the change can be retried.
+func (r *ReconcileSession) refreshSession(ctx model.SessionContext, session *istiov1alpha1.Session, conflict error) error {
+	if err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: session.Namespace, Name: session.Name}, session); err != nil {
+		return errors.Wrap(err, "failed reading session")
+	}
 
-	return errors.Wrap(err, "failed updating session route")
+	return conflict
 }
 
 func allConditionsSuccessful(conditions []*istiov1alpha1.Condition) bool {
@@ -310,7 +654,8 @@ func allConditionsSuccessful(conditions []*istiov1alpha1.Condition) bool {
 		condition := conditions[i]
 		conditionFailed := condition.Status != nil && *condition.Status == istiov1alpha1.StatusFailed
 		validation := condition.Reason != nil && *condition.Reason == ValidationReason
-		if conditionFailed && !validation {
+		warning := condition.Reason != nil && *condition.Reason == WarningReason
+		if conditionFailed && !validation && !warning {
 			return false
 		}
 	}
@@ -333,11 +678,15 @@ func refSuccessful(ref model.Ref, conditions []*istiov1alpha1.Condition) bool {
 func calculateSessionState(session *istiov1alpha1.Session) *istiov1alpha1.SessionState {
 	state := istiov1alpha1.StateSuccess
 	for _, con := range session.Status.Conditions {
-		if con.Status != nil && *con.Status == istiov1alpha1.StatusFailed {
+		warning := con.Reason != nil && *con.Reason == WarningReason
+		if con.Status != nil && *con.Status == istiov1alpha1.StatusFailed && !warning {
 			state = istiov1alpha1.StateFailed
 
 			break
 		}
+		if con.Reason != nil && *con.Reason == PendingReason {
+			state = istiov1alpha1.StateProcessing
+		}
 	}
 
 	return &state
@@ -351,11 +700,11 @@ func calculateReferences(ctx model.SessionContext, session *istiov1alpha1.Sessio
 		refs = append(refs, modelRef)
 	}
 
-	uniqueOldRefs := make(map[string]bool, 2)
+	oldRefs := []string{}
 	for _, condition := range session.Status.Conditions {
-		uniqueOldRefs[condition.Source.Ref] = true
+		oldRefs = append(oldRefs, condition.Source.Ref)
 	}
-	for key := range uniqueOldRefs {
+	for _, key := range unique(oldRefs) {
 		found := false
 		for _, ref := range refs {
 			if ref.KindName.String() == key {
@@ -371,15 +720,9 @@ func calculateReferences(ctx model.SessionContext, session *istiov1alpha1.Sessio
 		}
 	}
 
+	// removed refs go first, otherwise the order of the spec is kept
 	sort.SliceStable(refs, func(i, j int) bool {
-		if refs[i].Remove && !refs[j].Remove {
-			return true
-		}
-		if !refs[i].Remove && refs[j].Remove {
-			return false
-		}
-
-		return true
+		return refs[i].Remove && !refs[j].Remove
 	})
 
 	return refs
@@ -392,19 +735,44 @@ func splitAndUnique(all []string, hosts string) []string {
 	return unique(all)
 }
 
+// unique drops the repeated entries, keeping the first occurrence of each of them in place, so the status listing them
+// does not change between the reconciles.
 func unique(s []string) []string {
 	uniqueSlice := []string{}
 	entries := make(map[string]bool)
 	for _, entry := range s {
-		entries[entry] = true
-	}
-	for k := range entries {
-		uniqueSlice = append(uniqueSlice, k)
+		if !entries[entry] {
+			entries[entry] = true
+			uniqueSlice = append(uniqueSlice, entry)
+		}
 	}
 
 	return uniqueSlice
 }
 
+// rolloutGate keeps the Gateway and VirtualService modificators from running while the cloned workload is not available, either
+// still rolling out or failing, so the routes are not switched to it.
+func rolloutGate(registrars []model.ModificatorRegistrar) model.Gate {
+	guarded := make([]bool, len(registrars))
+	for i, reg := range registrars {
+		switch target, _ := reg(); target.(type) {
+		case *istionetwork.Gateway, *istionetwork.VirtualService:
+			guarded[i] = true
+		}
+	}
+
+	return model.Gate{
+		Closes: func(status model.ModificatorStatus) bool {
+			workload := status.Kind == k8s.DeploymentKind || status.Kind == openshift.DeploymentConfigKind
+
+			return workload && (!status.Success || status.Prop[model.PropPending] == "true")
+		},
+		Guards: func(modificator int) bool {
+			return guarded[modificator]
+		},
+	}
+}
+
 func extractModificators(registrars []model.ModificatorRegistrar) []model.Modificator {
 	mods := make([]model.Modificator, len(registrars))
 	for i, reg := range registrars {
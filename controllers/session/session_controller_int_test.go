@@ -5,9 +5,11 @@ import (
 	"context"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/maistra/istio-workspace/api/maistra/v1alpha1"
 	"github.com/maistra/istio-workspace/controllers/session"
+	"github.com/maistra/istio-workspace/pkg/istio"
 	"github.com/maistra/istio-workspace/pkg/log"
 	"github.com/maistra/istio-workspace/pkg/model"
 	"github.com/maistra/istio-workspace/pkg/template"
@@ -130,6 +132,40 @@ var _ = Describe("Complete session manipulation", func() {
 			})
 		})
 
+		Context("when the cloned workload is rolling out", func() {
+			It("should change all resources but the routes", func() {
+				controller = session.NewStandaloneReconciler(c, session.DefaultManipulators(), session.DefaultValidators()...).
+					WithRolloutTimeout(time.Minute)
+				req := reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-session1",
+						Namespace: "test",
+					},
+				}
+
+				res, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+				sess := get.Session("test", "test-session1")
+				Expect(*sess.Status.State).To(Equal(v1alpha1.StateProcessing))
+				reasonOf := func(kind string) []string {
+					reasons := []string{}
+					for _, condition := range sess.Status.Conditions {
+						if condition.Source.Kind == kind {
+							reasons = append(reasons, *condition.Reason)
+						}
+					}
+
+					return reasons
+				}
+				Expect(reasonOf("Deployment")).To(ConsistOf(session.PendingReason))
+				Expect(reasonOf("DestinationRule")).To(ConsistOf("Applied"))
+				Expect(reasonOf("VirtualService")).ToNot(BeEmpty())
+				Expect(reasonOf("VirtualService")).To(HaveEach("Scheduled"))
+			})
+		})
+
 		Context("when there are multiple sessions", func() {
 
 			It("should sync resources on delete", func() {
@@ -338,10 +374,6 @@ var _ = Describe("Complete session manipulation", func() {
 				testRegistrar := func() (client.Object, model.Modificator) {
 					return &corev1.Pod{}, func(context model.SessionContext, ref model.Ref, store model.LocatorStatusStore, reporter model.ModificatorStatusReporter) {
 
-						// Then - Verify all are in pending stage before modification stage
-						session := get.Session("test", "test-session1")
-						Expect(session.Status.Readiness.Components.Pending).To((HaveLen(3)))
-
 						// When - One is reported unsuccess
 						reporter(model.ModificatorStatus{
 							LocatorStatus: store("Pod")[0],
@@ -467,7 +499,7 @@ var _ = Describe("Complete session manipulation", func() {
 			BeforeEach(func() {
 				scenario = generator.IncompleteMissingDestinationRules
 			})
-			It("should fail on missing deployment", func() {
+			It("should synthesize destination rule", func() {
 				req := reconcile.Request{
 					NamespacedName: types.NamespacedName{
 						Name:      "test-session1",
@@ -481,11 +513,16 @@ var _ = Describe("Complete session manipulation", func() {
 				Expect(res1.Requeue).To(BeFalse())
 
 				session := get.Session("test", "test-session1")
-				Expect(*session.Status.State).To(Equal(v1alpha1.StateFailed))
+				Expect(*session.Status.State).To(Equal(v1alpha1.StateSuccess))
 
-				Expect(*getCondition(session, "FindDestinationRule").Status).To(Equal("false"))
+				Expect(*getCondition(session, "FindDestinationRule").Status).To(Equal("true"))
 				Expect(*getCondition(session, "FindVirtualService").Status).To(Equal("true"))
 				Expect(*getCondition(session, "FindTarget").Status).To(Equal("true"))
+
+				dr := get.DestinationRule("test", "dr-ratings-v1-ratings-test-session1")
+				Expect(dr.Labels).To(HaveKeyWithValue(istio.LabelIkeSynthesized, istio.LabelIkeSynthesizedValue))
+				Expect(dr.Spec.Subsets).To(HaveLen(1))
+				Expect(dr.Spec.Subsets[0].Labels).To(HaveKeyWithValue("version", dr.Spec.Subsets[0].Name))
 			})
 		})
 
@@ -493,7 +530,7 @@ var _ = Describe("Complete session manipulation", func() {
 			BeforeEach(func() {
 				scenario = generator.IncompleteMissingVirtualServices
 			})
-			It("should fail on missing deployment", func() {
+			It("should synthesize virtual service", func() {
 				req := reconcile.Request{
 					NamespacedName: types.NamespacedName{
 						Name:      "test-session1",
@@ -507,11 +544,15 @@ var _ = Describe("Complete session manipulation", func() {
 				Expect(res1.Requeue).To(BeFalse())
 
 				session := get.Session("test", "test-session1")
-				Expect(*session.Status.State).To(Equal(v1alpha1.StateFailed))
+				Expect(*session.Status.State).To(Equal(v1alpha1.StateSuccess))
 
 				Expect(*getCondition(session, "FindDestinationRule").Status).To(Equal("true"))
-				Expect(*getCondition(session, "FindVirtualService").Status).To(Equal("false"))
+				Expect(*getCondition(session, "FindVirtualService").Status).To(Equal("true"))
 				Expect(*getCondition(session, "FindTarget").Status).To(Equal("true"))
+
+				vs := get.VirtualService("test", "ratings-ike-base")
+				Expect(vs.Labels).To(HaveKeyWithValue(istio.LabelIkeSynthesized, istio.LabelIkeSynthesizedValue))
+				Expect(vs.Spec.Http).To(HaveLen(2))
 			})
 		})
 
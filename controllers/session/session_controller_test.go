@@ -3,19 +3,27 @@ package session_test
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"emperror.dev/errors"
 	"github.com/maistra/istio-workspace/api/maistra/v1alpha1"
 	"github.com/maistra/istio-workspace/controllers/session"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/k8s"
 	"github.com/maistra/istio-workspace/pkg/model"
 	"github.com/maistra/istio-workspace/test/testclient"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	k8sRecord "k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -31,7 +39,18 @@ var _ = Describe("Basic session manipulation", func() {
 		locator    *trackedLocator
 		mutator    *trackedMutator
 		get        *testclient.Getters
+		recorder   *k8sRecord.FakeRecorder
 	)
+
+	recordedEvents := func() []string {
+		events := []string{}
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+
+		return events
+	}
+
 	JustBeforeEach(func() {
 		locator = &trackedLocator{Action: notFoundTestLocator}
 		mutator = &trackedMutator{Action: noOpModifier}
@@ -45,6 +64,7 @@ var _ = Describe("Basic session manipulation", func() {
 		}
 
 		schema, _ = v1alpha1.SchemeBuilder.Build()
+		Expect(corev1.AddToScheme(schema)).To(Succeed())
 		req = reconcile.Request{
 			NamespacedName: types.NamespacedName{
 				Name:      "test-session",
@@ -52,7 +72,8 @@ var _ = Describe("Basic session manipulation", func() {
 			},
 		}
 		c = fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(objects...).Build()
-		controller = session.NewStandaloneReconciler(c, manipulators)
+		recorder = k8sRecord.NewFakeRecorder(100)
+		controller = session.NewStandaloneReconciler(c, manipulators).WithEventRecorder(recorder)
 		get = testclient.New(c)
 	})
 
@@ -116,6 +137,238 @@ var _ = Describe("Basic session manipulation", func() {
 				Expect(modified.Status.Conditions).To(HaveLen(1))
 				Expect(modified.Status.Conditions[0].Source.Name).To(Equal("test"))
 			})
+			It("should record events for the session and the modified resource", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = writeTarget()
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(recordedEvents()).To(ContainElements(
+					"Normal Created X /test created for details",
+					"Normal Created ConfigMap test created by session test/test-session",
+				))
+			})
+			It("should refer to the modified resource by its api version and uid", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = writeTarget()
+				involved := &involvedObjectsRecorder{FakeRecorder: recorder}
+				controller = session.NewStandaloneReconciler(c, session.Manipulators{
+					Locators: []model.Locator{locator.Do},
+					Handlers: []model.ModificatorRegistrar{func() (client.Object, model.Modificator) { return nil, mutator.Do }},
+				}).WithEventRecorder(involved)
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(involved.Objects).To(ContainElement(&corev1.ObjectReference{
+					Kind: "ConfigMap", APIVersion: "v1", Namespace: "test", Name: "test", UID: "test-uid", ResourceVersion: "1",
+				}))
+			})
+			It("should not record events when the resources are already modified", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = writeTarget()
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recordedEvents()).ToNot(BeEmpty())
+
+				_, err = controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recordedEvents()).To(BeEmpty())
+			})
+			It("should not record events for the located resources", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+					for _, l := range store() {
+						l.Action = model.ActionLocated
+						report(model.ModificatorStatus{LocatorStatus: l, Success: true})
+					}
+				}
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recordedEvents()).To(BeEmpty())
+			})
+			It("should record warning event when modification fails", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = reportFailure()
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(recordedEvents()).To(ContainElement("Warning Failed failed to create X /test for details: failed"))
+			})
+			It("should record details of the failing pods only once the failure is found", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+					for _, l := range store() {
+						report(model.ModificatorStatus{LocatorStatus: l, Success: false, Error: errors.Wrap(&k8s.PodsFailedError{Failures: []k8s.PodFailure{{
+							Namespace: "test", Pod: "test-abc", Container: "app", Reason: "CrashLoopBackOff", RestartCount: 3, Restarted: true,
+						}}}, "cloned X fails to run")})
+					}
+				}
+				logsRead := 0
+				controller = session.NewStandaloneReconciler(c, session.Manipulators{
+					Locators: []model.Locator{locator.Do},
+					Handlers: []model.ModificatorRegistrar{func() (client.Object, model.Modificator) { return nil, mutator.Do }},
+				}).WithEventRecorder(recorder).WithPodLogsReader(func(_ context.Context, namespace, pod, container string, previous bool) (string, error) {
+					logsRead++
+
+					return "panic: missing config", nil
+				})
+
+				for i := 0; i < 2; i++ {
+					_, err := controller.Reconcile(context.Background(), req)
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				Expect(logsRead).To(Equal(1))
+				Expect(recordedEvents()).To(ContainElement(
+					"Warning PodFailed details fails to run: test-abc/app CrashLoopBackOff (restarted 3 times), last log lines:\npanic: missing config"))
+				modified := get.Session("test", "test-session")
+				Expect(modified.Status.Conditions).To(ContainElement(
+					WithTransform(func(c *v1alpha1.Condition) string { return *c.Message }, HaveSuffix("cloned X fails to run: container app CrashLoopBackOff"))))
+			})
+			It("should record drifted condition when modified resource had to be restored", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = reportDrift()
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				modified := get.Session("test", "test-session")
+				Expect(modified.Status.Conditions).To(ContainElement(WithTransform(func(c *v1alpha1.Condition) string { return *c.Type }, Equal(session.DriftedReason))))
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateSuccess))
+				Expect(recordedEvents()).To(ContainElement(ContainSubstring(session.DriftedEventReason)))
+
+				// reconciled again without drift
+				mutator.Action = reportSuccess()
+				_, err = controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				modified = get.Session("test", "test-session")
+				Expect(modified.Status.Conditions).To(ContainElement(WithTransform(func(c *v1alpha1.Condition) string { return *c.Type }, Equal(session.DriftedReason))))
+			})
+			It("should record drifted condition for each of the restored resources", func() {
+				locator.Action = foundTestLocatorTarget("first", "second")
+				mutator.Action = reportDrift()
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				drifted := []string{}
+				for _, condition := range get.Session("test", "test-session").Status.Conditions {
+					if *condition.Type == session.DriftedReason {
+						drifted = append(drifted, condition.Target.Name)
+					}
+				}
+				Expect(drifted).To(ConsistOf("first", "second"))
+			})
+			It("should drop drifted condition once it expires", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = reportDrift()
+
+				res, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+				// drifted long ago, matching since
+				modified := get.Session("test", "test-session")
+				for _, condition := range modified.Status.Conditions {
+					if *condition.Type == session.DriftedReason {
+						expired := metav1.NewTime(time.Now().Add(-time.Hour))
+						condition.LastTransitionTime = &expired
+					}
+				}
+				Expect(c.Status().Update(context.Background(), &modified)).To(Succeed())
+
+				mutator.Action = reportSuccess()
+				res, err = controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeZero())
+
+				modified = get.Session("test", "test-session")
+				Expect(modified.Status.Conditions).ToNot(ContainElement(WithTransform(func(c *v1alpha1.Condition) string { return *c.Type }, Equal(session.DriftedReason))))
+			})
+			It("should update the status when session was changed during reconcile", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+					changed := get.Session("test", "test-session")
+					changed.Labels = map[string]string{"changed": "true"}
+					Expect(c.Update(ctx, &changed)).To(Succeed())
+					reportSuccess()(ctx, ref, store, report)
+				}
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				modified := get.Session("test", "test-session")
+				Expect(modified.Labels).To(HaveKeyWithValue("changed", "true"))
+				Expect(modified.Status.Conditions).To(HaveLen(1))
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateSuccess))
+			})
+			It("should keep processing the session while clone is rolling out", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+					for _, l := range store() {
+						report(model.ModificatorStatus{LocatorStatus: l, Success: true, Prop: map[string]string{model.PropPending: "true"}})
+					}
+				}
+
+				res, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+				modified := get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateProcessing))
+				Expect(*modified.Status.Conditions[0].Reason).To(Equal(session.PendingReason))
+				Expect(modified.Status.Readiness.Components.Pending).To(ConsistOf("X/test"))
+
+				// reconciled again once rolled out
+				mutator.Action = reportSuccess()
+				res, err = controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeZero())
+
+				modified = get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateSuccess))
+				Expect(modified.Status.Readiness.Components.Ready).To(ConsistOf("X/test"))
+			})
+			It("should report session metrics", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = reportSuccess()
+				readySessions := metricValue("session_ready_duration_seconds", nil)
+				Expect(c.Create(context.Background(), &v1alpha1.Session{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "metrics-session",
+						Namespace:         "metrics",
+						CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+					},
+					Spec: v1alpha1.SessionSpec{
+						Refs: []v1alpha1.Ref{{Name: "details", Strategy: "metrics-strategy"}},
+					},
+				})).To(Succeed())
+				metricsReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: "metrics-session", Namespace: "metrics"}}
+
+				_, err := controller.Reconcile(context.Background(), metricsReq)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(metricValue("session_active", map[string]string{"namespace": "metrics"})).To(Equal(1.0))
+				Expect(metricValue("session_refs", map[string]string{"strategy": "metrics-strategy"})).To(Equal(1.0))
+				Expect(metricValue("session_ready_duration_seconds", nil)).To(Equal(readySessions + 1))
+
+				// reconciled again once ready
+				_, err = controller.Reconcile(context.Background(), metricsReq)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(metricValue("session_ready_duration_seconds", nil)).To(Equal(readySessions + 1))
+
+				// session is gone
+				Expect(c.Delete(context.Background(), &v1alpha1.Session{ObjectMeta: metav1.ObjectMeta{Name: "metrics-session", Namespace: "metrics"}})).To(Succeed())
+				_, err = controller.Reconcile(context.Background(), metricsReq)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(metricValue("session_active", map[string]string{"namespace": "metrics"})).To(BeZero())
+			})
 			It("should update status with the corresponding route", func() {
 				res, err := controller.Reconcile(context.Background(), req)
 				Expect(err).ToNot(HaveOccurred())
@@ -128,6 +381,142 @@ var _ = Describe("Basic session manipulation", func() {
 				Expect(modified.Status.Route.Name).To(Equal(session.DefaultRouteHeaderName))
 			})
 		})
+		Context("transactional session", func() {
+			BeforeEach(func() {
+				objects = []runtime.Object{
+					&v1alpha1.Session{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-session",
+							Namespace: "test",
+						},
+						Spec: v1alpha1.SessionSpec{
+							Refs:          []v1alpha1.Ref{{Name: "details"}},
+							Transactional: true,
+						},
+					},
+				}
+			})
+
+			locateUndoable := func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+				action := model.ActionCreate
+				if ref.Remove {
+					action = model.Flip(action)
+				}
+				report(model.LocatorStatus{Resource: model.Resource{Kind: "X", Name: "test"}, Action: action})
+
+				return nil
+			}
+			failWhenApplied := func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+				if ref.Remove {
+					writeTarget()(ctx, ref, store, report)
+
+					return
+				}
+				writeTarget()(ctx, ref, store, func(model.ModificatorStatus) {})
+				reportFailure()(ctx, ref, store, report)
+			}
+
+			It("should rollback ref when modification fails", func() {
+				locator.Action = locateUndoable
+				mutator.Action = failWhenApplied
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mutator.Refs).To(HaveLen(2))
+				Expect(mutator.Refs[1].Remove).To(BeTrue())
+
+				modified := get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateFailed))
+				Expect(modified.Status.Conditions).To(ContainElements(
+					WithTransform(func(c *v1alpha1.Condition) string { return *c.Message }, HaveSuffix("failed")),
+					WithTransform(func(c *v1alpha1.Condition) string { return *c.Type + "=" + *c.Status }, Equal(session.RolledBackReason+"=true")),
+				))
+				Expect(modified.Status.Readiness.Components.Ready).To(BeEmpty())
+				Expect(recordedEvents()).To(ContainElement("Normal Deleted X /test deleted for details"))
+			})
+
+			It("should not apply rolled back ref again until it changes", func() {
+				locator.Action = locateUndoable
+				mutator.Action = failWhenApplied
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mutator.Refs).To(HaveLen(2))
+
+				_, err = controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mutator.Refs).To(HaveLen(2))
+				modified := get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateFailed))
+				Expect(modified.Status.Conditions).To(ContainElements(
+					WithTransform(func(c *v1alpha1.Condition) string { return *c.Message }, HaveSuffix("failed")),
+					WithTransform(func(c *v1alpha1.Condition) string { return *c.Type + "=" + *c.Status }, Equal(session.RolledBackReason+"=true")),
+				))
+
+				// ref changed
+				modified.Spec.Refs[0].Args = map[string]string{"image": "fixed"}
+				Expect(c.Update(context.Background(), &modified)).To(Succeed())
+				mutator.Action = reportSuccess()
+
+				_, err = controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mutator.Refs).To(HaveLen(3))
+				modified = get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateSuccess))
+				Expect(modified.Status.Conditions).ToNot(ContainElement(
+					WithTransform(func(c *v1alpha1.Condition) string { return *c.Type }, Equal(session.RolledBackReason)),
+				))
+			})
+
+			It("should not rollback ref when modifications succeed", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = reportSuccess()
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mutator.Refs).To(HaveLen(1))
+				modified := get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateSuccess))
+			})
+		})
+		Context("session with multiple refs", func() {
+			BeforeEach(func() {
+				objects = []runtime.Object{
+					&v1alpha1.Session{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-session",
+							Namespace: "test",
+						},
+						Spec: v1alpha1.SessionSpec{
+							Refs: []v1alpha1.Ref{{Name: "reviews"}, {Name: "details"}, {Name: "ratings"}, {Name: "productpage"}},
+						},
+					},
+				}
+			})
+
+			It("should list the refs and hosts in the order of the refs", func() {
+				locator.Action = foundTestLocator
+				mutator.Action = func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+					gateway := model.LocatorStatus{Resource: model.Resource{Kind: istio.GatewayKind, Name: "gateway"}, Action: model.ActionModify}
+					report(model.ModificatorStatus{LocatorStatus: gateway, Success: true, Prop: map[string]string{"hosts": ref.KindName.Name + ".example.com"}})
+				}
+
+				for i := 0; i < 3; i++ {
+					_, err := controller.Reconcile(context.Background(), req)
+					Expect(err).ToNot(HaveOccurred())
+
+					modified := get.Session("test", "test-session")
+					Expect(modified.Status.RefNames).To(Equal([]string{"reviews", "details", "ratings", "productpage"}))
+					Expect(modified.Status.Hosts).To(Equal([]string{
+						"reviews.example.com", "details.example.com", "ratings.example.com", "productpage.example.com",
+					}))
+				}
+			})
+		})
 	})
 	Context("session modification", func() {
 		Context("new reference", func() {
@@ -166,6 +555,37 @@ var _ = Describe("Basic session manipulation", func() {
 				Expect(mutator.WasCalled).To(BeTrue())
 				Expect(mutator.Refs[0].Remove).To(BeFalse())
 			})
+			It("should sync refs concurrently", func() {
+				controller.(*session.ReconcileSession).WithMaxConcurrentRefs(2)
+				locator.Action = foundTestLocator
+				started := make(chan struct{}, 2)
+				mutator.Action = func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+					started <- struct{}{}
+					deadline := time.After(5 * time.Second)
+					for len(started) < 2 { // both refs have to be in progress at the same time
+						select {
+						case <-deadline:
+							reportFailure()(ctx, ref, store, report)
+
+							return
+						case <-time.After(10 * time.Millisecond):
+						}
+					}
+					reportSuccess()(ctx, ref, store, report)
+				}
+
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				modified := get.Session("test", "test-session")
+				Expect(*modified.Status.State).To(Equal(v1alpha1.StateSuccess))
+				Expect(modified.Status.Conditions).To(HaveLen(2))
+				Expect(modified.Status.Conditions).To(ConsistOf(
+					WithTransform(func(c *v1alpha1.Condition) string { return c.Source.Ref }, Equal("details")),
+					WithTransform(func(c *v1alpha1.Condition) string { return c.Source.Ref }, Equal("details2")),
+				))
+			})
+
 			It("should update existing status when new mutation occurs", func() {
 				locator.Action = foundTestLocatorTarget("details2")
 				mutator.Action = reportSuccess()
@@ -288,10 +708,97 @@ var _ = Describe("Basic session manipulation", func() {
 				_, err = get.SessionWithError("test", "test-session")
 				Expect(err).To(HaveOccurred())
 			})
+
+			It("should record finalizer removal", func() {
+				_, err := controller.Reconcile(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(recordedEvents()).To(ContainElement(ContainSubstring(session.FinalizerRemovedEventReason)))
+			})
 		})
 	})
 })
 
+var _ = Describe("Session status update", func() {
+
+	It("should write status to the latest version of the session when the cached one is stale", func() {
+		// given
+		schema, _ := v1alpha1.SchemeBuilder.Build()
+		c := fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(&v1alpha1.Session{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-session", Namespace: "test", Finalizers: []string{session.Finalizer}},
+			Spec:       v1alpha1.SessionSpec{Refs: []v1alpha1.Ref{{Name: "details"}}},
+		}).Build()
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-session", Namespace: "test"}}
+
+		stale := &v1alpha1.Session{}
+		Expect(c.Get(context.Background(), req.NamespacedName, stale)).To(Succeed())
+		latest := stale.DeepCopy()
+		latest.Labels = map[string]string{"changed": "true"}
+		Expect(c.Update(context.Background(), latest)).To(Succeed())
+
+		controller := session.NewStandaloneReconciler(&staleSessionClient{Client: c, stale: stale}, session.Manipulators{}).WithAPIReader(c)
+
+		// when
+		_, err := controller.Reconcile(context.Background(), req)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		updated := testclient.New(c).Session("test", "test-session")
+		Expect(updated.Labels).To(HaveKeyWithValue("changed", "true"))
+		Expect(updated.Status.State).ToNot(BeNil())
+	})
+})
+
+var _ = Describe("Session validation", func() {
+
+	noResources := func(kinds ...string) []model.LocatorStatus {
+		return nil
+	}
+
+	It("should warn about propagation of routes other than header", func() {
+		ctx := model.SessionContext{Route: model.Route{Type: model.RouteTypeBaggage, Name: "workspace", Value: "feature-x", Propagate: true}}
+
+		typeName, err := session.RoutePropagation(ctx, noResources)
+
+		Expect(typeName).To(Equal("RoutePropagation"))
+		Expect(err).To(MatchError(ContainSubstring(istio.ErrPropagationNotSupported.Error())))
+	})
+
+	It("should accept propagation of header route", func() {
+		ctx := model.SessionContext{Route: model.Route{Type: model.RouteTypeHeader, Name: "x-workspace-route", Value: "feature-x", Propagate: true}}
+
+		_, err := session.RoutePropagation(ctx, noResources)
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+// metricValue returns the value of the gauge, or the number of observations of the histogram, with the given labels.
+func metricValue(name string, labels map[string]string) float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if value, found := labels[label.GetName()]; found && value != label.GetValue() {
+					continue metrics
+				}
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+
+			return metric.GetGauge().GetValue()
+		}
+	}
+
+	return 0
+}
+
 // notFound Action for Locator tracker.
 func notFoundTestLocator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
 	return nil
@@ -322,10 +829,13 @@ func noOpModifier(ctx model.SessionContext, ref model.Ref, store model.LocatorSt
 type trackedLocator struct {
 	WasCalled bool
 	Action    model.Locator
+	lock      sync.Mutex
 }
 
 func (t *trackedLocator) Do(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+	t.lock.Lock()
 	t.WasCalled = true
+	t.lock.Unlock()
 
 	return t.Action(ctx, ref, store, report)
 }
@@ -339,15 +849,84 @@ func reportSuccess() func(ctx model.SessionContext, ref model.Ref, store model.L
 	}
 }
 
+func reportFailure() func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	return func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+		for _, l := range store() {
+			report(model.ModificatorStatus{LocatorStatus: l, Success: false, Error: errors.New("failed")})
+		}
+	}
+}
+
+func reportDrift() func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	return func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+		for _, l := range store() {
+			report(model.ModificatorStatus{LocatorStatus: l, Success: true, Prop: map[string]string{istio.PropDrifted: "true"}})
+		}
+	}
+}
+
+// writeTarget Action for mutator tracker, creating the target of the located resource or deleting it when the ref is removed.
+func writeTarget() func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	return func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+		for _, l := range store() {
+			target := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Namespace, Name: l.Name, UID: "test-uid"}}
+			var err error
+			if ref.Remove {
+				err = ctx.Client.Delete(ctx, &target)
+			} else {
+				err = ctx.Client.Create(ctx, &target)
+			}
+			report(model.ModificatorStatus{
+				LocatorStatus: l,
+				Success:       err == nil || k8sErrors.IsAlreadyExists(err) || k8sErrors.IsNotFound(err),
+				Error:         err,
+				Target:        &model.Resource{Kind: "ConfigMap", Namespace: target.Namespace, Name: target.Name}})
+		}
+	}
+}
+
+// involvedObjectsRecorder keeps the objects the events are recorded for.
+type involvedObjectsRecorder struct {
+	*k8sRecord.FakeRecorder
+	Objects []runtime.Object
+	lock    sync.Mutex
+}
+
+func (r *involvedObjectsRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.lock.Lock()
+	r.Objects = append(r.Objects, object)
+	r.lock.Unlock()
+	r.FakeRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
 type trackedMutator struct {
 	WasCalled bool
 	Action    model.Modificator
 	Refs      []model.Ref
+	lock      sync.Mutex
 }
 
 func (t *trackedMutator) Do(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	t.lock.Lock()
 	t.WasCalled = true
 	t.Refs = append(t.Refs, ref)
+	t.lock.Unlock()
 
 	t.Action(ctx, ref, store, report)
 }
+
+// staleSessionClient keeps returning the Session as it was before the change, the way the informer cache lagging behind does.
+type staleSessionClient struct {
+	client.Client
+	stale *v1alpha1.Session
+}
+
+func (s *staleSessionClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if sess, ok := obj.(*v1alpha1.Session); ok && key == client.ObjectKeyFromObject(s.stale) {
+		s.stale.DeepCopyInto(sess)
+
+		return nil
+	}
+
+	return s.Client.Get(ctx, key, obj, opts...) //nolint:wrapcheck //reason test client passing through
+}
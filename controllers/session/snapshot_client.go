@@ -0,0 +1,121 @@
+package session
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// NewSnapshotClient returns client serving the listings made during a single reconcile from a shared snapshot. Locators of
+// every ref list the same resources in the namespace, so each of them is read from the API server only once.
+func NewSnapshotClient(c client.Client) *SnapshotClient {
+	return &SnapshotClient{Client: c, lists: map[string]client.ObjectList{}}
+}
+
+// SnapshotClient keeps the listings until the resources of the same kind are changed through it, so the changes made by
+// the modificators are seen by the locators running afterwards.
+type SnapshotClient struct {
+	client.Client
+	lock  sync.Mutex
+	lists map[string]client.ObjectList
+}
+
+func (s *SnapshotClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	kind := s.getKind(list)
+	if kind == "" || listOpts.Limit > 0 || listOpts.Continue != "" { // paged listings are not part of the snapshot
+		return s.Client.List(ctx, list, opts...)
+	}
+	key := kind + "/" + listOpts.Namespace + "?" + selectorsOf(listOpts)
+
+	s.lock.Lock()
+	cached, found := s.lists[key]
+	s.lock.Unlock()
+	if found {
+		reflect.ValueOf(list).Elem().Set(reflect.ValueOf(cached.DeepCopyObject()).Elem())
+
+		return nil
+	}
+
+	if err := s.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	if snapshot, ok := list.DeepCopyObject().(client.ObjectList); ok {
+		s.lock.Lock()
+		s.lists[key] = snapshot
+		s.lock.Unlock()
+	}
+
+	return nil
+}
+
+func (s *SnapshotClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	defer s.invalidate(obj)
+
+	return s.Client.Create(ctx, obj, opts...)
+}
+
+func (s *SnapshotClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	defer s.invalidate(obj)
+
+	return s.Client.Delete(ctx, obj, opts...)
+}
+
+func (s *SnapshotClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer s.invalidate(obj)
+
+	return s.Client.Update(ctx, obj, opts...)
+}
+
+func (s *SnapshotClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer s.invalidate(obj)
+
+	return s.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (s *SnapshotClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	defer s.invalidate(obj)
+
+	return s.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// invalidate drops all the listings of the kind of the changed object.
+func (s *SnapshotClient) invalidate(obj client.Object) {
+	kind := s.getKind(obj)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key := range s.lists {
+		if kind == "" || strings.HasPrefix(key, kind+"/") {
+			delete(s.lists, key)
+		}
+	}
+}
+
+// getKind returns the kind of the object, or of the items in case of list.
+func (s *SnapshotClient) getKind(obj runtime.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, s.Scheme())
+	if err != nil {
+		return ""
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	return gvk.GroupKind().String()
+}
+
+func selectorsOf(listOpts client.ListOptions) string {
+	selectors := []string{}
+	if listOpts.LabelSelector != nil {
+		selectors = append(selectors, "labels="+listOpts.LabelSelector.String())
+	}
+	if listOpts.FieldSelector != nil {
+		selectors = append(selectors, "fields="+listOpts.FieldSelector.String())
+	}
+
+	return strings.Join(selectors, "&")
+}
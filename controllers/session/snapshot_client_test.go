@@ -0,0 +1,93 @@
+package session_test
+
+import (
+	"context"
+
+	"github.com/maistra/istio-workspace/controllers/session"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Snapshot client", func() {
+
+	var (
+		counting *listCountingClient
+		c        *session.SnapshotClient
+	)
+
+	service := func(name string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	}
+
+	BeforeEach(func() {
+		schema := runtime.NewScheme()
+		Expect(corev1.AddToScheme(schema)).To(Succeed())
+		counting = &listCountingClient{Client: fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(service("details")).Build()}
+		c = session.NewSnapshotClient(counting)
+	})
+
+	It("should list the same resources only once", func() {
+		// when
+		first, second := corev1.ServiceList{}, corev1.ServiceList{}
+		Expect(c.List(context.Background(), &first, client.InNamespace("test"))).To(Succeed())
+		Expect(c.List(context.Background(), &second, client.InNamespace("test"))).To(Succeed())
+
+		// then
+		Expect(counting.lists).To(Equal(1))
+		Expect(second.Items).To(HaveLen(1))
+		Expect(second.Items[0].Name).To(Equal("details"))
+	})
+
+	It("should not share listings with different options", func() {
+		// when
+		Expect(c.List(context.Background(), &corev1.ServiceList{}, client.InNamespace("test"))).To(Succeed())
+		Expect(c.List(context.Background(), &corev1.ServiceList{}, client.InNamespace("test"), client.MatchingLabels{"app": "details"})).To(Succeed())
+
+		// then
+		Expect(counting.lists).To(Equal(2))
+	})
+
+	It("should list again once the resources of the same kind change", func() {
+		// given
+		Expect(c.List(context.Background(), &corev1.ServiceList{}, client.InNamespace("test"))).To(Succeed())
+
+		// when
+		Expect(c.Create(context.Background(), service("ratings"))).To(Succeed())
+		services := corev1.ServiceList{}
+		Expect(c.List(context.Background(), &services, client.InNamespace("test"))).To(Succeed())
+
+		// then
+		Expect(counting.lists).To(Equal(2))
+		Expect(services.Items).To(HaveLen(2))
+	})
+
+	It("should not share modifications of the listed items", func() {
+		// given
+		listed := corev1.ServiceList{}
+		Expect(c.List(context.Background(), &listed, client.InNamespace("test"))).To(Succeed())
+
+		// when
+		listed.Items[0].Name = "changed"
+		services := corev1.ServiceList{}
+		Expect(c.List(context.Background(), &services, client.InNamespace("test"))).To(Succeed())
+
+		// then
+		Expect(services.Items[0].Name).To(Equal("details"))
+	})
+})
+
+type listCountingClient struct {
+	client.Client
+	lists int
+}
+
+func (l *listCountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	l.lists++
+
+	return l.Client.List(ctx, list, opts...)
+}
@@ -2,10 +2,13 @@ package session
 
 import (
 	"strconv"
+	"strings"
 
 	"emperror.dev/errors"
 	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/istio"
 	"github.com/maistra/istio-workspace/pkg/model"
+	k8sRecord "k8s.io/client-go/tools/record"
 )
 
 const (
@@ -13,20 +16,36 @@ const (
 )
 
 // Validator returns a string of Type and a possible error.
-type Validator func(store model.LocatorStatusStore) (string, error)
+type Validator func(ctx model.SessionContext, store model.LocatorStatusStore) (string, error)
 
-func chainValidator(ctx model.SessionContext, ref model.Ref, session *istiov1alpha1.Session, validators ...Validator) model.ModificatorController {
+// warning is a validation error which is reported to the user, but does not stop the session from being applied.
+type warning struct {
+	error
+}
+
+func isWarning(err error) bool {
+	var w warning
+
+	return errors.As(err, &w)
+}
+
+func chainValidator(ctx model.SessionContext, ref model.Ref, session *istiov1alpha1.Session, recorder k8sRecord.EventRecorder, validators ...Validator) model.ModificatorController {
 	return func(store model.LocatorStatusStore) bool {
 		succeeded := true
 		for _, validator := range validators {
 			var message string
-			typeName, err := validator(store)
+			typeName, err := validator(ctx, store)
+			reason := ValidationReason
 			if err != nil {
-				succeeded = false
 				message = err.Error()
+				recordValidation(recorder, session, ref, typeName, err)
+				if isWarning(err) {
+					reason = WarningReason
+				} else {
+					succeeded = false
+				}
 			}
 
-			reason := ValidationReason
 			status := strconv.FormatBool(err == nil)
 			session.AddCondition(istiov1alpha1.Condition{
 				Source: istiov1alpha1.Source{
@@ -42,19 +61,12 @@ func chainValidator(ctx model.SessionContext, ref model.Ref, session *istiov1alp
 			})
 		}
 
-		if len(validators) > 0 {
-			err := ctx.Client.Status().Update(ctx, session)
-			if err != nil {
-				ctx.Log.Error(err, "could not update session", "name", session.Name, "namespace", session.Namespace)
-			}
-		}
-
 		return succeeded
 	}
 }
 
 func ResourceFound(kind string) Validator {
-	return func(store model.LocatorStatusStore) (string, error) {
+	return func(ctx model.SessionContext, store model.LocatorStatusStore) (string, error) {
 		targetType := "Find" + kind
 		if len(store(kind)) == 0 {
 			return targetType, errors.New("no " + kind + " found")
@@ -64,7 +76,7 @@ func ResourceFound(kind string) Validator {
 	}
 }
 
-func TargetFound(store model.LocatorStatusStore) (string, error) {
+func TargetFound(ctx model.SessionContext, store model.LocatorStatusStore) (string, error) {
 	typeName := "FindTarget"
 	if len(store("DeploymentConfig")) == 0 && len(store("Deployment")) == 0 {
 		return typeName, errors.New("no target Deployment or DeploymentConfig found")
@@ -72,3 +84,27 @@ func TargetFound(store model.LocatorStatusStore) (string, error) {
 
 	return typeName, nil
 }
+
+// RouteVisibility warns when Sidecar or exportTo configuration keeps part of the mesh from seeing the session route.
+func RouteVisibility(ctx model.SessionContext, store model.LocatorStatusStore) (string, error) {
+	typeName := "RouteVisibility"
+	issues, err := istio.FindRouteVisibilityIssues(ctx, store)
+	if err != nil {
+		return typeName, warning{errors.WrapIf(err, "failed checking route visibility")}
+	}
+	if len(issues) > 0 {
+		return typeName, warning{errors.New(strings.Join(issues, "; "))}
+	}
+
+	return typeName, nil
+}
+
+// RoutePropagation warns when the session asks for propagation of the route which the operator cannot propagate.
+func RoutePropagation(ctx model.SessionContext, store model.LocatorStatusStore) (string, error) {
+	typeName := "RoutePropagation"
+	if ctx.Route.Propagate && ctx.Route.Type != model.RouteTypeHeader {
+		return typeName, warning{errors.WithDetails(istio.ErrPropagationNotSupported, "type", ctx.Route.Type)}
+	}
+
+	return typeName, nil
+}
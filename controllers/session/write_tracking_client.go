@@ -0,0 +1,96 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/maistra/istio-workspace/pkg/model"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// NewWriteTrackingClient returns client remembering the objects changed through it. Modificators report success also for
+// the resources found already in the desired state, so this is how the changes actually made by them are told apart.
+func NewWriteTrackingClient(c client.Client) *WriteTrackingClient {
+	return &WriteTrackingClient{Client: c, written: map[string]client.Object{}}
+}
+
+// WriteTrackingClient is used by the modificators of a single ref, which run one after another, so it is not synchronized.
+type WriteTrackingClient struct {
+	client.Client
+	written map[string]client.Object
+}
+
+// Written returns the last state of the resource changed through the client, nil when it has not been changed.
+func (w *WriteTrackingClient) Written(resource model.Resource) client.Object {
+	return w.written[resource.Kind+"/"+types.NamespacedName{Namespace: resource.Namespace, Name: resource.Name}.String()]
+}
+
+func (w *WriteTrackingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	err := w.Client.Create(ctx, obj, opts...)
+	w.track(obj, err)
+
+	return err
+}
+
+func (w *WriteTrackingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := w.Client.Delete(ctx, obj, opts...)
+	w.track(obj, err)
+
+	return err
+}
+
+func (w *WriteTrackingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	err := w.Client.Update(ctx, obj, opts...)
+	w.track(obj, err)
+
+	return err
+}
+
+func (w *WriteTrackingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	changes := changesObject(obj, patch)
+	err := w.Client.Patch(ctx, obj, patch, opts...)
+	if changes {
+		w.track(obj, err)
+	}
+
+	return err
+}
+
+func (w *WriteTrackingClient) track(obj client.Object, err error) {
+	if err != nil {
+		return
+	}
+	gvk, err := apiutil.GVKForObject(obj, w.Scheme())
+	if err != nil {
+		return
+	}
+	if written, ok := obj.DeepCopyObject().(client.Object); ok {
+		w.written[gvk.Kind+"/"+client.ObjectKeyFromObject(obj).String()] = written
+	}
+}
+
+// changesObject tells if the merge patch changes anything but the resource version it is locked on. Other kinds of patches
+// are considered changing the object.
+func changesObject(obj client.Object, patch client.Patch) bool {
+	if patch.Type() != types.MergePatchType {
+		return true
+	}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return true
+	}
+	changes := map[string]interface{}{}
+	if err = json.Unmarshal(data, &changes); err != nil {
+		return true
+	}
+	if metadata, ok := changes["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "resourceVersion")
+		if len(metadata) == 0 {
+			delete(changes, "metadata")
+		}
+	}
+
+	return len(changes) > 0
+}
@@ -0,0 +1,77 @@
+package session_test
+
+import (
+	"context"
+
+	"github.com/maistra/istio-workspace/controllers/session"
+	"github.com/maistra/istio-workspace/pkg/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Write tracking client", func() {
+
+	var c *session.WriteTrackingClient
+
+	service := func(name string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	}
+	resource := func(name string) model.Resource {
+		return model.Resource{Kind: "Service", Namespace: "test", Name: name}
+	}
+
+	BeforeEach(func() {
+		schema := runtime.NewScheme()
+		Expect(corev1.AddToScheme(schema)).To(Succeed())
+		c = session.NewWriteTrackingClient(fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(service("details")).Build())
+	})
+
+	It("should track created resources", func() {
+		// when
+		Expect(c.Create(context.Background(), service("ratings"))).To(Succeed())
+
+		// then
+		Expect(c.Written(resource("ratings"))).ToNot(BeNil())
+		Expect(c.Written(resource("details"))).To(BeNil())
+	})
+
+	It("should not track resources failed to be written", func() {
+		// when
+		Expect(c.Create(context.Background(), service("details"))).ToNot(Succeed())
+
+		// then
+		Expect(c.Written(resource("details"))).To(BeNil())
+	})
+
+	It("should track patches changing the resource", func() {
+		// given
+		details := service("details")
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(details), details)).To(Succeed())
+
+		// when
+		patch := client.MergeFromWithOptions(details.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		details.Labels = map[string]string{"app": "details"}
+		Expect(c.Patch(context.Background(), details, patch)).To(Succeed())
+
+		// then
+		Expect(c.Written(resource("details"))).ToNot(BeNil())
+	})
+
+	It("should not track patches leaving the resource as it is", func() {
+		// given
+		details := service("details")
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(details), details)).To(Succeed())
+
+		// when
+		patch := client.MergeFromWithOptions(details.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		Expect(c.Patch(context.Background(), details, patch)).To(Succeed())
+
+		// then
+		Expect(c.Written(resource("details"))).To(BeNil())
+	})
+})
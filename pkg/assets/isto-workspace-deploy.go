@@ -103,7 +103,7 @@ func templateStrategies_basicRemoveTpl() (*asset, error) {
 	return a, nil
 }
 
-var _templateStrategies_basicVersionTpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x94\x4d\x6f\xf2\x30\x10\x84\xef\xfc\x8a\xd5\x9e\xde\x57\x02\x7c\xe7\x5a\x2a\xf5\x50\xf5\xc8\x7d\xeb\x2c\x25\xaa\xbf\x1a\x1b\x2a\x64\xf9\xbf\x57\x09\x1f\x05\x0a\xc5\x31\xf4\x98\xc4\x3b\xf3\x64\x76\xe4\x18\xa1\x9e\x83\xb1\x01\xfe\x8d\xa7\x14\x68\xfc\x44\x1e\x50\x78\xc7\x52\x04\xd6\x4e\x51\x60\xa1\x39\x50\x45\x81\xf0\x3f\xa4\x34\x88\x68\x1d\x4e\x00\xa9\xaa\x70\x08\xe8\x28\x2c\xda\xc7\x4b\x33\x43\xc0\x15\xa9\x25\xe3\x04\x62\x4a\xc3\x41\x8c\xc0\xa6\xea\x84\xfa\x78\x0b\x45\xaf\xac\x7c\x09\xc2\x6e\xf4\x1a\x49\x36\x84\x58\x71\xe3\x6b\x6b\xf0\x90\x45\x5a\xb7\x6e\x2d\xe6\x8d\xd5\xd7\x61\xf6\x12\xd9\xf8\xbb\x89\x91\xb7\xcb\x46\x32\xb6\x3f\xb0\xb5\x6e\xd8\x29\x92\xdc\x5f\xeb\x30\x12\x8c\x71\xfc\xc2\x9f\xb3\xcd\x97\x94\xf0\xe6\x5d\xed\x5d\xca\x77\x76\x5f\x50\xcf\x8a\x65\xb0\x4d\x06\xd0\xfe\x68\x5e\x69\x1e\x3f\x96\xa4\x00\xc5\x7b\x6d\x2a\x04\x9c\xb2\x53\x76\xad\xd9\x84\xae\x22\x00\x39\x54\x42\x53\x90\x8b\xe7\x83\x9a\x03\x64\x31\x1e\x0d\x9e\xf2\x76\xde\x5b\xe2\x1d\x47\x16\xc2\x51\xc9\xbf\x49\x2e\x76\xed\x57\x8d\x2b\xeb\x3b\x07\x99\x1f\xd6\x71\xcf\x0a\x42\x2b\xc1\xec\xd9\x83\x07\x6b\xe6\xf5\x1b\xe6\x6d\xa1\x34\xf9\xbf\x4b\xbb\x28\xe1\xbb\xa5\xba\x21\xca\xb9\x84\xcf\x65\x74\xfb\x95\x92\x23\x6e\x48\xf3\xa9\x64\x07\x3f\x6b\x5f\xfc\x38\x99\xd2\xe8\x8c\xe7\x57\x00\x00\x00\xff\xff\x82\xd4\x0f\xab\x8e\x07\x00\x00")
+var _templateStrategies_basicVersionTpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x94\x4f\x6f\xe2\x30\x10\xc5\xef\x7c\x8a\xd1\x48\x2b\xb1\x52\x88\xef\x5c\x97\x95\xf6\x80\x56\x3d\x71\x9f\x26\x93\x12\xd5\xb1\xdd\xd8\xb4\x42\x96\xbf\x7b\x15\xc4\x7f\x02\x71\x02\xf4\x1a\xcd\xbc\xf9\xcd\xbc\x17\x7b\x0f\x65\x01\x4a\x3b\x18\xa7\x33\x72\x94\xfe\x23\x0b\x28\xac\xe1\x4c\x38\xae\x8c\x24\xc7\xa2\x62\x47\x39\x39\xc2\xdf\x10\xc2\xc8\xa3\x36\x38\x05\xa4\x3c\xc7\x04\xd0\x90\x5b\xe2\xf4\x7a\x4f\x02\xf8\x49\x72\xc5\x38\x05\x1f\x42\x32\xf2\x1e\x58\xe5\x1b\xa1\x3e\xb3\x85\xa4\x57\x96\x76\x08\xc2\xae\xb5\x8b\xe4\x00\x31\x36\x75\xa9\x5c\xd1\xa5\x28\x7e\x59\x84\x74\xc1\xb5\x2d\xb5\x9a\x37\x43\x5e\xc8\x2d\x4f\x08\x33\x6d\xd6\xcd\x95\x8a\x5a\x57\xdd\x88\xc2\xfb\x0b\xb9\x10\xe2\x57\x6c\xef\x9f\x58\xbd\xaa\x33\xc6\x90\xec\xb1\x6a\x36\x92\x32\xbe\x57\xf9\xf8\xa4\xe8\x7d\xfa\x9f\xbf\xb6\x45\x21\x60\xb7\xd7\xf7\x9d\x79\x78\x12\x9e\xb2\xcc\x76\xa6\x65\xc9\x99\xd3\x75\x44\x50\xf7\xa5\x71\xc1\xfc\xfb\xb1\x22\x09\x28\xde\x4b\x95\x23\xe0\x8c\x8d\xd4\xeb\x8a\x95\xc3\xa6\x12\x20\x86\x4a\x54\xe4\xb2\xe5\xfc\xe8\x57\x02\x88\x62\x3c\x69\x3c\xe7\x05\x38\x10\xef\x38\xae\xba\xdc\xa6\x78\xe3\x47\x02\xe8\xcc\x6c\xab\xe2\x20\x8b\xdb\x16\xb9\x9d\xd9\x1e\xdb\x0c\x3d\xf7\xfd\xab\xf4\xcc\xd3\x1f\xad\x8a\xf2\x0d\xfb\xb9\xf9\x18\x07\x7f\xda\xb5\x07\x38\xf5\x24\x77\x4e\xa9\xe3\x5e\xc4\xe3\x17\xa7\xed\xd6\x8f\x7e\x06\x63\x46\x29\xaa\xf8\x5c\x72\xb3\xe0\xa2\xf9\x70\x51\x19\xc2\xa4\x65\xe6\xf7\x00\x7c\x75\xb3\x4f\xa6\x08\x00\x00")
 
 func templateStrategies_basicVersionTplBytes() ([]byte, error) {
 	return bindataRead(
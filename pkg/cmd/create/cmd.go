@@ -46,7 +46,7 @@ func NewCmd() *cobra.Command {
 	createCmd.Flags().StringP("deployment", "d", "", "name of the deployment or deployment config")
 	createCmd.Flags().StringP("session", "s", "", "create or join an existing session")
 	createCmd.Flags().StringP("image", "i", "", "create a prepared session with the given image")
-	createCmd.Flags().StringP("route", "", "", "specifies traffic route options in the format of type:name=value. "+
+	createCmd.Flags().StringP("route", "", "", "specifies traffic route options in the format of type:name=value, where type is header or baggage. "+
 		"Defaults to X-Workspace-Route header with current session name value")
 	createCmd.Flags().StringP("namespace", "n", "", "target namespace to develop against "+
 		"(defaults to default for the current context)")
@@ -128,7 +128,7 @@ func NewCmd() *cobra.Command {
 	_ = developCmd.RegisterFlagCompletionFunc("method", flag.CompletionFor(tpMethods))
 
 	developCmd.Flags().StringP("session", "s", "", "create or join an existing session")
-	developCmd.Flags().StringP("route", "", "", "specifies traffic route options in the format of type:name=value. "+
+	developCmd.Flags().StringP("route", "", "", "specifies traffic route options in the format of type:name=value, where type is header or baggage. "+
 		"Defaults to X-Workspace-Route header with current session name value")
 	developCmd.Flags().StringP("namespace", "n", "", "target namespace to develop against "+
 		"(defaults to default for the current context)")
@@ -7,6 +7,7 @@ import (
 	"emperror.dev/errors"
 	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
 	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/maistra/istio-workspace/pkg/model"
 )
 
 const urlHint = `Knowing your application url you can now access your new version by using
@@ -16,15 +17,21 @@ the following hosts
 $ curl {{ . }}
 {{- end }}
 {{ end -}}
-{{- if .Route }}{{ if eq .Route.Type "header" }}
+{{- if .Route }}{{ if eq .Route.Type .HeaderType }}
 the following header
 $ curl -H"{{.Route.Name}}:{{.Route.Value}}" YOUR_APP_URL.
+{{ end }}{{ if eq .Route.Type .BaggageType }}
+the following baggage entry
+$ curl -H"baggage:{{.Route.Name}}={{.Route.Value}}" YOUR_APP_URL.
 {{ end }}{{ end }}
-If you can't see any changes make sure that this header is respected by your app and propagated down the call chain.`
+If you can't see any changes make sure that this header is respected by your app and propagated down the call chain.
+Use 'ike propagation' to find the service which does not propagate it.`
 
 type data struct {
-	Hosts []string
-	Route *istiov1alpha1.Route
+	Hosts       []string
+	Route       *istiov1alpha1.Route
+	HeaderType  string
+	BaggageType string
 }
 
 // Hint returns a string containing the help for how to reach your new route.
@@ -35,7 +42,7 @@ func Hint(state *session.State) (string, error) {
 	}
 
 	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, data{Hosts: state.Hosts, Route: &state.Route})
+	err = tmpl.Execute(&buf, data{Hosts: state.Hosts, Route: &state.Route, HeaderType: model.RouteTypeHeader, BaggageType: model.RouteTypeBaggage})
 
 	return buf.String(), err
 }
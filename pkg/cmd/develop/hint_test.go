@@ -48,6 +48,15 @@ var _ = Describe("Hint", func() {
 		Expect(text).To(ContainSubstring("curl -H\"x:y\" YOUR_APP_URL."))
 	})
 
+	It("should print baggage entry if baggage route provided", func() {
+		text, err := develop.Hint(&session.State{
+			Route: istiov1alpha1.Route{Type: "baggage", Name: "workspace", Value: "y"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(text).To(ContainSubstring("curl -H\"baggage:workspace=y\" YOUR_APP_URL."))
+	})
+
 	It("should print multiple hosts if hosts provided", func() {
 		text, err := develop.Hint(&validState)
 		Expect(err).ToNot(HaveOccurred())
@@ -0,0 +1,43 @@
+package kube
+
+import (
+	"emperror.dev/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RestConfig resolves the configuration of the current kube context.
+// While resolving configuration we look for .kube/config file unless KUBECONFIG env variable is set.
+func RestConfig() (*rest.Config, error) {
+	kubeCfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	)
+	restCfg, err := kubeCfg.ClientConfig()
+
+	return restCfg, errors.Wrap(err, "failed to get kube config")
+}
+
+// Clientset creates k8s clientset for the current kube context.
+func Clientset() (kubernetes.Interface, error) {
+	restCfg, err := RestConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+
+	return clientset, errors.Wrap(err, "failed creating k8s clientset")
+}
+
+// DynamicClient creates k8s dynamic client for the current kube context.
+func DynamicClient() (dynamic.Interface, error) {
+	restCfg, err := RestConfig()
+	if err != nil {
+		return nil, err
+	}
+	dynClient, err := dynamic.NewForConfig(restCfg)
+
+	return dynClient, errors.Wrap(err, "failed creating k8s dynamic client")
+}
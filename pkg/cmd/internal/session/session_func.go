@@ -34,6 +34,36 @@ func Sessions(cmd *cobra.Command) (session.State, session.Options, func(), error
 	return state, options, f, err
 }
 
+// JoinSession adds the ref defined by the deployment and image flags to the existing session of the given name.
+// It's expected that cmd has namespace, deployment, image and revert flags defined.
+func JoinSession(cmd *cobra.Command, sessionName string) (session.State, error) {
+	options, err := ToJoinOptions(sessionName, cmd.Flags())
+	if err != nil {
+		return session.State{}, errors.WrapIf(err, "failed to create options")
+	}
+	client, err := session.DefaultClient(options.NamespaceName)
+	if err != nil {
+		return session.State{}, errors.WrapIf(err, "failed to get default client")
+	}
+
+	return session.JoinHandler(options, client)
+}
+
+// LeaveSession removes the ref defined by the deployment flag from the existing session of the given name.
+// It's expected that cmd has namespace, deployment, keep and revert flags defined.
+func LeaveSession(cmd *cobra.Command, sessionName string) (session.State, error) {
+	options, err := ToLeaveOptions(sessionName, cmd.Flags())
+	if err != nil {
+		return session.State{}, errors.WrapIf(err, "failed to create options")
+	}
+	client, err := session.DefaultClient(options.NamespaceName)
+	if err != nil {
+		return session.State{}, errors.WrapIf(err, "failed to get default client")
+	}
+
+	return session.LeaveHandler(options, client)
+}
+
 // RemoveSessions creates a Handler for the given session operation for removing a
 // session expects that cmd has offline and session flags defined. Otherwise, it fails.
 func RemoveSessions(cmd *cobra.Command) (session.State, func(), error) {
@@ -58,9 +88,6 @@ const (
 
 // ToOptions converts between FlagSet to a Handler Options.
 func ToOptions(annotations map[string]string, flags *pflag.FlagSet) (session.Options, error) {
-	strategy := telepresenceStrategy
-	strategyArgs := map[string]string{}
-
 	n, err := flags.GetString("namespace")
 	if err != nil {
 		return session.Options{}, errors.Wrap(err, "failed obtaining namespace flag")
@@ -81,17 +108,11 @@ func ToOptions(annotations map[string]string, flags *pflag.FlagSet) (session.Opt
 		return session.Options{}, errors.Wrap(err, "failed obtaining route flag")
 	}
 
-	i, _ := flags.GetString("image") // ignore error, not a required argument
-	if i != "" {
-		strategy = "prepared-image"
-		strategyArgs["image"] = i
+	strategy, strategyArgs, err := toStrategy(flags)
+	if err != nil {
+		return session.Options{}, err
 	}
 
-	if strategy == telepresenceStrategy {
-		if strategyArgs["version"], err = telepresence.GetVersion(); err != nil {
-			return session.Options{}, errors.Wrap(err, "failed obtaining telepresence version")
-		}
-	}
 	revert := false
 	if val, found := annotations[AnnotationRevert]; found && val == "true" {
 		revert = true
@@ -108,6 +129,88 @@ func ToOptions(annotations map[string]string, flags *pflag.FlagSet) (session.Opt
 	}, nil
 }
 
+// ToJoinOptions converts between FlagSet to a Handler Options for joining the given session.
+func ToJoinOptions(sessionName string, flags *pflag.FlagSet) (session.Options, error) {
+	n, err := flags.GetString("namespace")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining namespace flag")
+	}
+
+	d, err := flags.GetString("deployment")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining deployment flag")
+	}
+
+	revert, err := flags.GetBool("revert")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining revert flag")
+	}
+
+	strategy, strategyArgs, err := toStrategy(flags)
+	if err != nil {
+		return session.Options{}, err
+	}
+
+	return session.Options{
+		Revert:         revert,
+		NamespaceName:  n,
+		DeploymentName: d,
+		SessionName:    sessionName,
+		Strategy:       strategy,
+		StrategyArgs:   strategyArgs,
+	}, nil
+}
+
+// ToLeaveOptions converts between FlagSet to a Handler Options for leaving the given session.
+func ToLeaveOptions(sessionName string, flags *pflag.FlagSet) (session.Options, error) {
+	n, err := flags.GetString("namespace")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining namespace flag")
+	}
+
+	d, err := flags.GetString("deployment")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining deployment flag")
+	}
+
+	k, err := flags.GetBool("keep")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining keep flag")
+	}
+
+	revert, err := flags.GetBool("revert")
+	if err != nil {
+		return session.Options{}, errors.Wrap(err, "failed obtaining revert flag")
+	}
+
+	return session.Options{
+		Revert:         revert,
+		NamespaceName:  n,
+		DeploymentName: d,
+		SessionName:    sessionName,
+		Keep:           k,
+	}, nil
+}
+
+func toStrategy(flags *pflag.FlagSet) (strategy string, strategyArgs map[string]string, err error) {
+	strategy = telepresenceStrategy
+	strategyArgs = map[string]string{}
+
+	i, _ := flags.GetString("image") // ignore error, not a required argument
+	if i != "" {
+		strategy = "prepared-image"
+		strategyArgs["image"] = i
+	}
+
+	if strategy == telepresenceStrategy {
+		if strategyArgs["version"], err = telepresence.GetVersion(); err != nil {
+			return "", nil, errors.Wrap(err, "failed obtaining telepresence version")
+		}
+	}
+
+	return strategy, strategyArgs, nil
+}
+
 // ToRemoveOptions converts between FlagSet to a Handler Options.
 func ToRemoveOptions(flags *pflag.FlagSet) (session.Options, error) {
 	n, err := flags.GetString("namespace")
@@ -0,0 +1,72 @@
+package join
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/cmd/config"
+	internal "github.com/maistra/istio-workspace/pkg/cmd/internal/session"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// NewCmd creates instance of "join" Cobra Command with flags and execution logic defined.
+func NewCmd() *cobra.Command {
+	joinCmd := &cobra.Command{
+		Use:          "join SESSION",
+		Short:        "Joins an existing Session",
+		Long:         "Adds the given deployment to an existing Session, or updates it if the deployment already participates in it",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Wrap(config.SyncFullyQualifiedFlags(cmd), "failed syncing flags")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := internal.JoinSession(cmd, args[0])
+			if err != nil {
+				return errors.WrapIf(err, "failed to join session")
+			}
+
+			return PrintJoined(cmd.OutOrStdout(), args[0], cmd.Flag("deployment").Value.String(), state)
+		},
+	}
+
+	joinCmd.Flags().StringP("deployment", "d", "", "name of the deployment or deployment config")
+	joinCmd.Flags().StringP("image", "i", "", "join with a prepared image instead of telepresence")
+	joinCmd.Flags().Bool("revert", false, "restore the strategy the deployment had in the session before joining when it leaves, "+
+		"instead of removing it (--revert=false forgets the strategy kept by the previous join)")
+	joinCmd.Flags().StringP("namespace", "n", "", "target namespace to develop against "+
+		"(defaults to default for the current context)")
+
+	joinCmd.Flags().VisitAll(config.BindFullyQualifiedFlag(joinCmd))
+
+	_ = joinCmd.MarkFlagRequired("deployment")
+
+	return joinCmd
+}
+
+// PrintJoined writes confirmation of joining the session listing other participating refs.
+func PrintJoined(out io.Writer, sessionName, ref string, state session.State) error {
+	var others []string
+	for _, r := range state.Refs {
+		if r != ref {
+			others = append(others, r)
+		}
+	}
+
+	msg := fmt.Sprintf("Joined session '%s' with '%s'.\n", sessionName, ref)
+	if len(others) == 0 {
+		msg += "No other refs participate in this session.\n"
+	} else {
+		msg += fmt.Sprintf("Other participating refs: %s\n", strings.Join(others, ", "))
+	}
+	for _, host := range state.Hosts {
+		msg += fmt.Sprintf("Exposed host: %s\n", host)
+	}
+
+	_, err := io.WriteString(out, msg)
+
+	return errors.Wrap(err, "failed writing to out stream")
+}
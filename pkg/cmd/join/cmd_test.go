@@ -0,0 +1,97 @@
+package join_test
+
+import (
+	"bytes"
+
+	. "github.com/maistra/istio-workspace/pkg/cmd"
+	internal "github.com/maistra/istio-workspace/pkg/cmd/internal/session"
+	"github.com/maistra/istio-workspace/pkg/cmd/join"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	. "github.com/maistra/istio-workspace/test"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Usage of ike join command", func() {
+
+	var joinCmd *cobra.Command
+
+	BeforeEach(func() {
+		joinCmd = join.NewCmd()
+		joinCmd.SilenceUsage = true
+		joinCmd.SilenceErrors = true
+		NewCmd(&k8s.AssumeOperatorInstalled{}).AddCommand(joinCmd)
+	})
+
+	Describe("input validation", func() {
+
+		It("should fail when session is not specified", func() {
+			_, err := ValidateArgumentsOf(joinCmd).Passing("--deployment", "ratings-v1")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("accepts 1 arg(s)"))
+		})
+
+		It("should fail when deployment is not specified", func() {
+			_, err := ValidateArgumentsOf(joinCmd).Passing("feature-x")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(And(ContainSubstring("required flag(s)"), ContainSubstring("deployment")))
+		})
+
+		It("should accept image", func() {
+			_, err := ValidateArgumentsOf(joinCmd).Passing("feature-x", "-d", "ratings-v1", "--image", "quay.io/ratings:dev")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(joinCmd.Flag("image").Value.String()).To(Equal("quay.io/ratings:dev"))
+		})
+
+		It("should not revert by default", func() {
+			_, err := ValidateArgumentsOf(joinCmd).Passing("feature-x", "-d", "ratings-v1", "--image", "quay.io/ratings:dev")
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := internal.ToJoinOptions("feature-x", joinCmd.Flags())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Revert).To(BeFalse())
+		})
+
+		It("should set Revert when asked to", func() {
+			_, err := ValidateArgumentsOf(joinCmd).Passing("feature-x", "-d", "ratings-v1", "--image", "quay.io/ratings:dev", "--revert")
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := internal.ToJoinOptions("feature-x", joinCmd.Flags())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Revert).To(BeTrue())
+		})
+	})
+
+	Describe("confirmation", func() {
+
+		It("should list other participating refs", func() {
+			out := new(bytes.Buffer)
+
+			Expect(join.PrintJoined(out, "feature-x", "ratings-v1", session.State{
+				Refs:  []string{"reviews-v1", "ratings-v1", "details-v1"},
+				Hosts: []string{"feature-x.bookinfo.example.com"},
+			})).To(Succeed())
+
+			Expect(out.String()).To(And(
+				ContainSubstring("Joined session 'feature-x' with 'ratings-v1'"),
+				ContainSubstring("Other participating refs: reviews-v1, details-v1"),
+				ContainSubstring("feature-x.bookinfo.example.com"),
+			))
+		})
+
+		It("should inform when no other refs participate", func() {
+			out := new(bytes.Buffer)
+
+			Expect(join.PrintJoined(out, "feature-x", "ratings-v1", session.State{Refs: []string{"ratings-v1"}})).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring("No other refs participate in this session"))
+		})
+	})
+})
@@ -0,0 +1,66 @@
+package leave
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/cmd/config"
+	internal "github.com/maistra/istio-workspace/pkg/cmd/internal/session"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// NewCmd creates instance of "leave" Cobra Command with flags and execution logic defined.
+func NewCmd() *cobra.Command {
+	leaveCmd := &cobra.Command{
+		Use:   "leave SESSION",
+		Short: "Leaves an existing Session",
+		Long: "Removes the given deployment from an existing Session. " +
+			"The Session is deleted when no other deployments participate in it, unless --keep is used",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Wrap(config.SyncFullyQualifiedFlags(cmd), "failed syncing flags")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := internal.LeaveSession(cmd, args[0])
+			if err != nil {
+				return errors.WrapIf(err, "failed to leave session")
+			}
+
+			return PrintLeft(cmd.OutOrStdout(), args[0], cmd.Flag("deployment").Value.String(), state)
+		},
+	}
+
+	leaveCmd.Flags().StringP("deployment", "d", "", "name of the deployment or deployment config")
+	leaveCmd.Flags().Bool("keep", false, "keep the session even if no other deployments participate in it")
+	leaveCmd.Flags().Bool("revert", true, "restore the strategy the deployment had before 'ike join --revert' "+
+		"instead of removing it from the session (--revert=false removes it regardless)")
+	leaveCmd.Flags().StringP("namespace", "n", "", "target namespace to develop against "+
+		"(defaults to default for the current context)")
+
+	leaveCmd.Flags().VisitAll(config.BindFullyQualifiedFlag(leaveCmd))
+
+	_ = leaveCmd.MarkFlagRequired("deployment")
+
+	return leaveCmd
+}
+
+// PrintLeft writes confirmation of leaving the session listing remaining refs.
+func PrintLeft(out io.Writer, sessionName, ref string, state session.State) error {
+	msg := fmt.Sprintf("'%s' left session '%s'.\n", ref, sessionName)
+	switch {
+	case state.Removed:
+		msg += fmt.Sprintf("Session '%s' has been removed as no other refs participate in it.\n", sessionName)
+	case len(state.Refs) == 0:
+		msg += fmt.Sprintf("Session '%s' is kept without participating refs.\n", sessionName)
+	default:
+		msg += fmt.Sprintf("Remaining participating refs: %s\n", strings.Join(state.Refs, ", "))
+	}
+
+	_, err := io.WriteString(out, msg)
+
+	return errors.Wrap(err, "failed writing to out stream")
+}
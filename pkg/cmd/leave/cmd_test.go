@@ -0,0 +1,101 @@
+package leave_test
+
+import (
+	"bytes"
+
+	. "github.com/maistra/istio-workspace/pkg/cmd"
+	internal "github.com/maistra/istio-workspace/pkg/cmd/internal/session"
+	"github.com/maistra/istio-workspace/pkg/cmd/leave"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	. "github.com/maistra/istio-workspace/test"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Usage of ike leave command", func() {
+
+	var leaveCmd *cobra.Command
+
+	BeforeEach(func() {
+		leaveCmd = leave.NewCmd()
+		leaveCmd.SilenceUsage = true
+		leaveCmd.SilenceErrors = true
+		NewCmd(&k8s.AssumeOperatorInstalled{}).AddCommand(leaveCmd)
+	})
+
+	Describe("input validation", func() {
+
+		It("should fail when session is not specified", func() {
+			_, err := ValidateArgumentsOf(leaveCmd).Passing("--deployment", "ratings-v1")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("accepts 1 arg(s)"))
+		})
+
+		It("should fail when deployment is not specified", func() {
+			_, err := ValidateArgumentsOf(leaveCmd).Passing("feature-x")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(And(ContainSubstring("required flag(s)"), ContainSubstring("deployment")))
+		})
+
+		It("should accept keep flag", func() {
+			_, err := ValidateArgumentsOf(leaveCmd).Passing("feature-x", "-d", "ratings-v1", "--keep")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(leaveCmd.Flag("keep").Value.String()).To(Equal("true"))
+		})
+
+		It("should revert by default", func() {
+			_, err := ValidateArgumentsOf(leaveCmd).Passing("feature-x", "-d", "ratings-v1")
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := internal.ToLeaveOptions("feature-x", leaveCmd.Flags())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Revert).To(BeTrue())
+		})
+
+		It("should clear Revert when asked to", func() {
+			_, err := ValidateArgumentsOf(leaveCmd).Passing("feature-x", "-d", "ratings-v1", "--revert=false")
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := internal.ToLeaveOptions("feature-x", leaveCmd.Flags())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Revert).To(BeFalse())
+		})
+	})
+
+	Describe("confirmation", func() {
+
+		It("should list remaining refs", func() {
+			out := new(bytes.Buffer)
+
+			Expect(leave.PrintLeft(out, "feature-x", "ratings-v1", session.State{Refs: []string{"reviews-v1"}})).To(Succeed())
+
+			Expect(out.String()).To(And(
+				ContainSubstring("'ratings-v1' left session 'feature-x'"),
+				ContainSubstring("Remaining participating refs: reviews-v1"),
+			))
+		})
+
+		It("should inform when session was removed", func() {
+			out := new(bytes.Buffer)
+
+			Expect(leave.PrintLeft(out, "feature-x", "ratings-v1", session.State{Removed: true})).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring("Session 'feature-x' has been removed"))
+		})
+
+		It("should inform when session was kept", func() {
+			out := new(bytes.Buffer)
+
+			Expect(leave.PrintLeft(out, "feature-x", "ratings-v1", session.State{})).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring("Session 'feature-x' is kept"))
+		})
+	})
+})
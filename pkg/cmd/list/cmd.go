@@ -0,0 +1,62 @@
+package list
+
+import (
+	"emperror.dev/errors"
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/cmd/config"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NewCmd creates instance of "list" Cobra Command with flags and execution logic defined.
+func NewCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "Lists existing Sessions",
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Wrap(config.SyncFullyQualifiedFlags(cmd), "failed syncing flags")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, _ := cmd.Flags().GetString("namespace")
+			allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+			mine, _ := cmd.Flags().GetBool("mine")
+
+			selector := ""
+			if mine {
+				owner, err := session.CurrentUser()
+				if err != nil {
+					return errors.WrapIf(err, "failed determining current user")
+				}
+				selector = labels.SelectorFromSet(labels.Set{session.LabelOwner: owner}).String()
+			}
+
+			client, err := session.DefaultClient(namespace)
+			if err != nil {
+				return errors.WrapIf(err, "failed to get default client")
+			}
+
+			var sessions *istiov1alpha1.SessionList
+			if allNamespaces {
+				sessions, err = client.ListAllNamespaces(selector)
+			} else {
+				sessions, err = client.List(selector)
+			}
+			if err != nil {
+				return errors.WrapIf(err, "failed listing sessions")
+			}
+
+			return errors.WrapIf(PrintSessions(cmd.OutOrStdout(), sessions.Items, allNamespaces), "failed printing sessions")
+		},
+	}
+
+	listCmd.Flags().StringP("namespace", "n", "", "target namespace to list sessions from "+
+		"(defaults to default for the current context)")
+	listCmd.Flags().BoolP("all-namespaces", "A", false, "list sessions across all namespaces")
+	listCmd.Flags().Bool("mine", false, "list only sessions created by the current user")
+
+	listCmd.Flags().VisitAll(config.BindFullyQualifiedFlag(listCmd))
+
+	return listCmd
+}
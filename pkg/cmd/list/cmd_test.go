@@ -0,0 +1,120 @@
+package list_test
+
+import (
+	"bytes"
+	"time"
+
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	. "github.com/maistra/istio-workspace/pkg/cmd"
+	"github.com/maistra/istio-workspace/pkg/cmd/list"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	. "github.com/maistra/istio-workspace/test"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Usage of ike list command", func() {
+
+	var listCmd *cobra.Command
+
+	BeforeEach(func() {
+		listCmd = list.NewCmd()
+		listCmd.SilenceUsage = true
+		listCmd.SilenceErrors = true
+		NewCmd(&k8s.AssumeOperatorInstalled{}).AddCommand(listCmd)
+	})
+
+	Describe("input validation", func() {
+
+		It("should accept all namespaces and mine flags", func() {
+			_, err := ValidateArgumentsOf(listCmd).Passing("--all-namespaces", "--mine")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listCmd.Flag("all-namespaces").Value.String()).To(Equal("true"))
+			Expect(listCmd.Flag("mine").Value.String()).To(Equal("true"))
+		})
+
+		It("should accept short all namespaces flag", func() {
+			_, err := ValidateArgumentsOf(listCmd).Passing("-A")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listCmd.Flag("all-namespaces").Value.String()).To(Equal("true"))
+		})
+	})
+
+	Describe("printing sessions", func() {
+
+		success := istiov1alpha1.StateSuccess
+		sessions := []istiov1alpha1.Session{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "feature-x",
+					Namespace:         "bookinfo",
+					Labels:            map[string]string{session.LabelOwner: "alice"},
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Hour)),
+				},
+				Status: istiov1alpha1.SessionStatus{
+					State:           &success,
+					RouteExpression: "header:x-workspace-route=feature-x",
+					RefNames:        []string{"ratings-v1", "reviews-v1"},
+					Strategies:      []string{"prepared-image"},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "legacy",
+					Namespace: "other",
+				},
+			},
+		}
+
+		It("should print a table with session details", func() {
+			out := new(bytes.Buffer)
+
+			Expect(list.PrintSessions(out, sessions, false)).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring("NAME"))
+			Expect(out.String()).ToNot(ContainSubstring("NAMESPACE"))
+			Expect(out.String()).To(And(
+				ContainSubstring("feature-x"),
+				ContainSubstring("alice"),
+				ContainSubstring("5h"),
+				ContainSubstring("ratings-v1,reviews-v1"),
+				ContainSubstring("prepared-image"),
+				ContainSubstring("header:x-workspace-route=feature-x"),
+				ContainSubstring("Success"),
+			))
+		})
+
+		It("should print none for missing details", func() {
+			out := new(bytes.Buffer)
+
+			Expect(list.PrintSessions(out, sessions[1:], false)).To(Succeed())
+
+			Expect(out.String()).To(ContainSubstring("legacy   <none>"))
+		})
+
+		It("should include namespace column when listing across namespaces", func() {
+			out := new(bytes.Buffer)
+
+			Expect(list.PrintSessions(out, sessions, true)).To(Succeed())
+
+			Expect(out.String()).To(And(
+				ContainSubstring("NAMESPACE"),
+				ContainSubstring("bookinfo"),
+				ContainSubstring("other"),
+			))
+		})
+
+		It("should inform when no sessions found", func() {
+			out := new(bytes.Buffer)
+
+			Expect(list.PrintSessions(out, nil, false)).To(Succeed())
+
+			Expect(out.String()).To(Equal("No sessions found.\n"))
+		})
+	})
+})
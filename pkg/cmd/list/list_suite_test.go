@@ -0,0 +1,26 @@
+package list_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/goleak"
+)
+
+func TestListCmd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "List Command Suite")
+}
+
+var current goleak.Option
+
+var _ = SynchronizedBeforeSuite(func() []byte {
+	current = goleak.IgnoreCurrent()
+
+	return []byte{}
+}, func([]byte) {})
+
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	goleak.VerifyNone(GinkgoT(), current)
+})
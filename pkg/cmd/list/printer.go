@@ -0,0 +1,77 @@
+package list
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"emperror.dev/errors"
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const none = "<none>"
+
+// PrintSessions writes given sessions as a table to the out stream. Namespace column is included only when
+// sessions are listed across all namespaces.
+func PrintSessions(out io.Writer, sessions []istiov1alpha1.Session, withNamespace bool) error {
+	if len(sessions) == 0 {
+		_, err := fmt.Fprintln(out, "No sessions found.")
+
+		return errors.Wrap(err, "failed writing to out stream")
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	header := []string{"NAME", "OWNER", "AGE", "REFS", "STRATEGIES", "ROUTE", "STATE"}
+	if withNamespace {
+		header = append([]string{"NAMESPACE"}, header...)
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+		return errors.Wrap(err, "failed writing to out stream")
+	}
+
+	for i := range sessions {
+		row := sessionRow(&sessions[i])
+		if withNamespace {
+			row = append([]string{sessions[i].Namespace}, row...)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return errors.Wrap(err, "failed writing to out stream")
+		}
+	}
+
+	return errors.Wrap(w.Flush(), "failed writing to out stream")
+}
+
+func sessionRow(s *istiov1alpha1.Session) []string {
+	age := none
+	if !s.CreationTimestamp.IsZero() {
+		age = duration.HumanDuration(time.Since(s.CreationTimestamp.Time))
+	}
+
+	state := none
+	if s.Status.State != nil {
+		state = string(*s.Status.State)
+	}
+
+	return []string{
+		s.Name,
+		orNone(s.Labels[session.LabelOwner]),
+		age,
+		orNone(strings.Join(s.Status.RefNames, ",")),
+		orNone(strings.Join(s.Status.Strategies, ",")),
+		orNone(s.Status.RouteExpression),
+		state,
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return none
+	}
+
+	return value
+}
@@ -0,0 +1,249 @@
+package propagation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/cmd/config"
+	"github.com/maistra/istio-workspace/pkg/cmd/internal/kube"
+	"github.com/maistra/istio-workspace/pkg/cmd/route"
+	"github.com/maistra/istio-workspace/pkg/hook"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const sidecarContainer = "istio-proxy"
+
+var errBrokenPropagation = errors.Sentinel("route header is not propagated by all services")
+
+// NewCmd creates instance of "propagation" Cobra Command with flags and execution logic defined.
+func NewCmd() *cobra.Command {
+	propagationCmd := &cobra.Command{
+		Use:   "propagation SESSION",
+		Short: "Checks if services propagate the Session route header down the call chain",
+		Long: "Installs a temporary EnvoyFilter in the session namespace which logs, for every sidecar, whether requests carrying the route header " +
+			"leave the service with the header preserved. Then sends requests to the Session (or waits for your own traffic) " +
+			"and reports for each service if it propagates the header, so you can find the one breaking the call chain.",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Wrap(config.SyncFullyQualifiedFlags(cmd), "failed syncing flags")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, _ := cmd.Flags().GetString("namespace")
+			rawURL, _ := cmd.Flags().GetString("url")
+			gateway, _ := cmd.Flags().GetString("gateway")
+			path, _ := cmd.Flags().GetString("path")
+			noProbe, _ := cmd.Flags().GetBool("no-probe")
+			settle, _ := cmd.Flags().GetDuration("settle")
+			duration, _ := cmd.Flags().GetDuration("duration")
+
+			client, err := session.DefaultClient(namespace)
+			if err != nil {
+				return errors.WrapIf(err, "failed to get default client")
+			}
+			sess, err := client.Get(args[0])
+			if err != nil {
+				return errors.WrapIf(err, "failed to get session")
+			}
+
+			filter, err := CreateEnvoyFilter(sess)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := kube.Clientset()
+			if err != nil {
+				return err
+			}
+			dynClient, err := kube.DynamicClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			versionLabel, _ := cmd.Flags().GetString("version-label")
+			if versionLabel == "" {
+				versionLabel = sess.Spec.VersionLabel
+			}
+			versions, err := route.ReadSessionVersions(ctx, clientset, sess, versionLabel)
+			if err != nil {
+				return err
+			}
+
+			filters := dynClient.Resource(EnvoyFilterResource).Namespace(sess.Namespace)
+			// the filter left behind by the check which has not been able to clean up, e.g. when killed
+			if err = filters.Delete(ctx, filter.GetName(), metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+				return errors.WrapWithDetails(err, "failed removing stale propagation check filter", "name", filter.GetName())
+			}
+			if _, err = filters.Create(ctx, filter, metav1.CreateOptions{}); err != nil {
+				return errors.WrapWithDetails(err, "failed creating propagation check filter", "name", filter.GetName())
+			}
+			removeFilter := func() error {
+				// not bound to the command context, so the filter is removed also when the command is cancelled
+				err := filters.Delete(context.Background(), filter.GetName(), metav1.DeleteOptions{})
+
+				return errors.WrapWithDetails(err, "failed removing propagation check filter", "name", filter.GetName())
+			}
+			hook.Register(removeFilter)
+			defer func() {
+				_ = removeFilter()
+			}()
+
+			if err = printf(cmd.OutOrStdout(), "Waiting %s for the check to be applied to sidecars.\n", settle); err != nil {
+				return err
+			}
+			if err = wait(ctx, settle); err != nil {
+				return err
+			}
+			since := metav1.NewTime(time.Now())
+
+			if !noProbe {
+				requests, err := route.CreateRequests(sess, versions, rawURL, gateway, path)
+				if err != nil {
+					return err
+				}
+				for _, request := range requests {
+					result := route.Probe(ctx, http.DefaultClient, request)
+					if err := route.PrintResult(cmd.OutOrStdout(), &result); err != nil {
+						return err
+					}
+				}
+			}
+			if err = printf(cmd.OutOrStdout(), "Collecting sidecar logs for %s.\n", duration); err != nil {
+				return err
+			}
+			if err = wait(ctx, duration); err != nil {
+				return err
+			}
+
+			reports, err := collectReports(ctx, clientset, sess.Namespace, versionLabel, since)
+			if err != nil {
+				return err
+			}
+			if err := PrintReports(cmd.OutOrStdout(), reports); err != nil {
+				return err
+			}
+			for _, report := range reports {
+				if report.Broken() {
+					return errBrokenPropagation
+				}
+			}
+
+			return nil
+		},
+	}
+
+	propagationCmd.Flags().StringP("namespace", "n", "", "namespace of the session "+
+		"(defaults to default for the current context)")
+	propagationCmd.Flags().StringP("url", "u", "", "in-mesh url to call instead of the hosts exposed by the session")
+	propagationCmd.Flags().StringP("gateway", "g", "", "address of the ingress gateway to call with session hosts used as Host header")
+	propagationCmd.Flags().StringP("path", "p", "/", "path to call on the hosts exposed by the session")
+	propagationCmd.Flags().String("version-label", "", "label key holding the version of the services "+
+		"(defaults to the one defined by the session or '"+model.DefaultVersionLabel+"')")
+	propagationCmd.Flags().Bool("no-probe", false, "do not send any requests, only observe the traffic you generate during --duration")
+	propagationCmd.Flags().Duration("settle", 5*time.Second, "time to wait for the check to be applied to all sidecars")
+	propagationCmd.Flags().Duration("duration", 5*time.Second, "time to observe the traffic before collecting sidecar logs")
+
+	propagationCmd.Flags().VisitAll(config.BindFullyQualifiedFlag(propagationCmd))
+
+	return propagationCmd
+}
+
+// wait blocks for the given duration, unless the context is done first.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "propagation check interrupted")
+	}
+}
+
+func printf(out io.Writer, format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(out, format, a...)
+
+	return errors.Wrap(err, "failed writing to out stream")
+}
+
+func collectReports(ctx context.Context, clientset kubernetes.Interface, namespace, versionLabel string, since metav1.Time) ([]*ServiceReport, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, "failed listing pods", "namespace", namespace)
+	}
+
+	reports := map[string]*ServiceReport{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !hasSidecar(pod) {
+			continue
+		}
+		logs, err := clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+			Container: sidecarContainer,
+			SinceTime: &since,
+		}).DoRaw(ctx)
+		service := ServiceName(pod, versionLabel)
+		if _, found := reports[service]; !found {
+			reports[service] = &ServiceReport{Service: service}
+		}
+		if err != nil {
+			// the other pods can still tell which services break the propagation
+			reports[service].Unreadable = append(reports[service].Unreadable, pod.Name)
+
+			continue
+		}
+		if err := reports[service].Collect(bytes.NewReader(logs)); err != nil {
+			reports[service].Unreadable = append(reports[service].Unreadable, pod.Name)
+		}
+	}
+
+	result := make([]*ServiceReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, report)
+	}
+
+	return result, nil
+}
+
+func hasSidecar(pod *v1.Pod) bool {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == sidecarContainer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServiceName determines the name of the service the pod belongs to based on well known labels, falling back to the pod name.
+// The version held by the given label key, or the default one if empty, is appended to tell the versions apart.
+func ServiceName(pod *v1.Pod, versionLabel string) string {
+	if versionLabel == "" {
+		versionLabel = model.DefaultVersionLabel
+	}
+	name := pod.Labels["app"]
+	if name == "" {
+		name = pod.Labels["service.istio.io/canonical-name"]
+	}
+	if name == "" {
+		return pod.Name
+	}
+	if version := pod.Labels[versionLabel]; version != "" {
+		name += "-" + version
+	}
+
+	return name
+}
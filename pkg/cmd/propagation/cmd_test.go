@@ -0,0 +1,162 @@
+package propagation_test
+
+import (
+	"bytes"
+	"strings"
+
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	. "github.com/maistra/istio-workspace/pkg/cmd"
+	"github.com/maistra/istio-workspace/pkg/cmd/propagation"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	. "github.com/maistra/istio-workspace/test"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Usage of ike propagation command", func() {
+
+	var propagationCmd *cobra.Command
+
+	BeforeEach(func() {
+		propagationCmd = propagation.NewCmd()
+		propagationCmd.SilenceUsage = true
+		propagationCmd.SilenceErrors = true
+		NewCmd(&k8s.AssumeOperatorInstalled{}).AddCommand(propagationCmd)
+	})
+
+	Describe("input validation", func() {
+
+		It("should fail when session is not specified", func() {
+			_, err := ValidateArgumentsOf(propagationCmd).Passing("--no-probe")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("accepts 1 arg(s)"))
+		})
+	})
+
+	Describe("envoy filter", func() {
+
+		It("should log route header in both directions", func() {
+			filter, err := propagation.CreateEnvoyFilter(&istiov1alpha1.Session{
+				ObjectMeta: metav1.ObjectMeta{Name: "feature-x", Namespace: "bookinfo"},
+				Status: istiov1alpha1.SessionStatus{
+					Route: &istiov1alpha1.Route{Type: "header", Name: "x-workspace-route", Value: "feature-x"},
+				},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filter.GetName()).To(Equal("feature-x-propagation-check"))
+			Expect(filter.GetNamespace()).To(Equal("bookinfo"))
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			Expect(patches).To(HaveLen(2))
+			for _, patch := range patches {
+				code, _, _ := unstructured.NestedString(patch.(map[string]interface{}), "patch", "value", "typed_config", "inlineCode")
+				Expect(code).To(ContainSubstring(`get("x-workspace-route") == "feature-x"`))
+			}
+		})
+
+		It("should look for the entry in baggage for baggage routes", func() {
+			filter, err := propagation.CreateEnvoyFilter(&istiov1alpha1.Session{
+				ObjectMeta: metav1.ObjectMeta{Name: "feature-x", Namespace: "bookinfo"},
+				Status: istiov1alpha1.SessionStatus{
+					Route: &istiov1alpha1.Route{Type: "baggage", Name: "workspace", Value: "feature-x"},
+				},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			code, _, _ := unstructured.NestedString(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "inlineCode")
+			Expect(code).To(ContainSubstring(`string.match(entry, "^%s*([^=%s]+)%s*=%s*([^;%s]+)")`))
+			Expect(code).To(ContainSubstring(`if k == "workspace" and v == "feature-x" then`))
+		})
+
+		It("should fail for routes other than header", func() {
+			_, err := propagation.CreateEnvoyFilter(&istiov1alpha1.Session{
+				Status: istiov1alpha1.SessionStatus{
+					Route: &istiov1alpha1.Route{Type: "query", Name: "route", Value: "feature-x"},
+				},
+			})
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("report", func() {
+
+		const logs = `2022-01-01T10:00:00.000Z	info	envoy config	all clusters initialized
+2022-01-01T10:00:01.000Z	warning	envoy lua	script log: ike-propagation direction=inbound header=present
+2022-01-01T10:00:01.100Z	warning	envoy lua	script log: ike-propagation direction=outbound header=present authority=reviews:9080
+2022-01-01T10:00:01.200Z	warning	envoy lua	script log: ike-propagation direction=outbound header=missing authority=details:9080
+`
+
+		It("should not consider services dropping the header for some calls as broken", func() {
+			report := &propagation.ServiceReport{Service: "productpage-v1"}
+
+			Expect(report.Collect(strings.NewReader(logs))).To(Succeed())
+
+			Expect(report.Received).To(Equal(1))
+			Expect(report.Forwarded).To(Equal(1))
+			Expect(report.Missing).To(ConsistOf("details:9080"))
+			Expect(report.Status()).To(Equal(propagation.StatusPartial))
+			Expect(report.Broken()).To(BeFalse())
+		})
+
+		It("should not consider services without header as broken", func() {
+			report := &propagation.ServiceReport{Service: "ratings-v1"}
+
+			Expect(report.Collect(strings.NewReader(
+				"script log: ike-propagation direction=outbound header=missing authority=mongodb:27017"))).To(Succeed())
+
+			Expect(report.Status()).To(Equal(propagation.StatusNotReached))
+			Expect(report.Broken()).To(BeFalse())
+		})
+
+		It("should print status per service", func() {
+			out := new(bytes.Buffer)
+
+			Expect(propagation.PrintReports(out, []*propagation.ServiceReport{
+				{Service: "reviews-v1", Received: 2, Missing: []string{"ratings:9080"}},
+				{Service: "details-v1", Received: 1},
+			})).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[1]).To(And(HavePrefix("details-v1"), ContainSubstring("no outbound calls"), HaveSuffix("<none>")))
+			Expect(lines[2]).To(And(HavePrefix("reviews-v1"), ContainSubstring("broken"), HaveSuffix("ratings:9080")))
+		})
+
+		It("should report pods which sidecar logs could not be read", func() {
+			out := new(bytes.Buffer)
+
+			Expect(propagation.PrintReports(out, []*propagation.ServiceReport{
+				{Service: "reviews-v1", Unreadable: []string{"reviews-v1-6b7f6c9d5-x2k4p"}},
+				{Service: "details-v1", Received: 1},
+			})).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[2]).To(And(HavePrefix("reviews-v1"), ContainSubstring("unknown")))
+			Expect(lines[3]).To(Equal("Failed reading sidecar logs of pods: reviews-v1-6b7f6c9d5-x2k4p"))
+		})
+
+		It("should name service by app and version labels", func() {
+			Expect(propagation.ServiceName(&v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "reviews-v1-6b7f6c9d5-x2k4p",
+				Labels: map[string]string{"app": "reviews", "version": "v1"},
+			}}, "")).To(Equal("reviews-v1"))
+			Expect(propagation.ServiceName(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone"}}, "")).To(Equal("standalone"))
+		})
+
+		It("should name service by configured version label", func() {
+			Expect(propagation.ServiceName(&v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "reviews-v1-6b7f6c9d5-x2k4p",
+				Labels: map[string]string{"app": "reviews", "version": "v1", "app.kubernetes.io/version": "1.2"},
+			}}, "app.kubernetes.io/version")).To(Equal("reviews-1.2"))
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package propagation
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LogMarker prefixes every entry logged by the propagation check filter.
+const LogMarker = "ike-propagation"
+
+// EnvoyFilterResource is the resource of Istio EnvoyFilter used to install the temporary check.
+var EnvoyFilterResource = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"}
+
+// Direction of the request as seen by the sidecar.
+type Direction string
+
+const (
+	Inbound  Direction = "inbound"
+	Outbound Direction = "outbound"
+)
+
+// FilterName returns the name of the temporary EnvoyFilter created for the given session.
+func FilterName(sessionName string) string {
+	return sessionName + "-propagation-check"
+}
+
+// CreateEnvoyFilter creates temporary EnvoyFilter which logs in every sidecar of the namespace
+// whether inbound requests carry the session route and whether outbound requests still have it.
+func CreateEnvoyFilter(session *istiov1alpha1.Session) (*unstructured.Unstructured, error) {
+	route := session.Status.Route
+	if route == nil {
+		return nil, errors.NewWithDetails("session has no route configured yet", "session", session.Name)
+	}
+	if route.Type != model.RouteTypeHeader && route.Type != model.RouteTypeBaggage {
+		return nil, errors.NewWithDetails("propagation check is only supported for header and baggage based routes", "session", session.Name, "type", route.Type)
+	}
+
+	filter := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"configPatches": []interface{}{
+				istio.LuaFilterPatch("SIDECAR_INBOUND", inboundScript(route)),
+				istio.LuaFilterPatch("SIDECAR_OUTBOUND", outboundScript(route)),
+			},
+		},
+	}}
+	filter.SetAPIVersion(EnvoyFilterResource.GroupVersion().String())
+	filter.SetKind("EnvoyFilter")
+	filter.SetName(FilterName(session.Name))
+	filter.SetNamespace(session.Namespace)
+	filter.SetLabels(map[string]string{"ike.session": session.Name})
+
+	return filter, nil
+}
+
+func inboundScript(route *istiov1alpha1.Route) string {
+	return routePresentFunc(route) + fmt.Sprintf(`
+function envoy_on_request(request_handle)
+  if route_present(request_handle:headers()) then
+    request_handle:logWarn(%[1]q)
+  end
+end
+`, LogMarker+" direction="+string(Inbound)+" header=present")
+}
+
+func outboundScript(route *istiov1alpha1.Route) string {
+	return routePresentFunc(route) + fmt.Sprintf(`
+function envoy_on_request(request_handle)
+  local authority = request_handle:headers():get(":authority") or ""
+  if route_present(request_handle:headers()) then
+    request_handle:logWarn(%[1]q .. authority)
+  else
+    request_handle:logWarn(%[2]q .. authority)
+  end
+end
+`,
+		LogMarker+" direction="+string(Outbound)+" header=present authority=",
+		LogMarker+" direction="+string(Outbound)+" header=missing authority=")
+}
+
+// routePresentFunc defines Lua function checking if the request carries the session route.
+func routePresentFunc(route *istiov1alpha1.Route) string {
+	if route.Type == model.RouteTypeBaggage {
+		return fmt.Sprintf(`local function route_present(headers)
+  local baggage = headers:get("baggage")
+  if baggage == nil then
+    return false
+  end
+  for entry in string.gmatch(baggage, "[^,]+") do
+    local k, v = string.match(entry, "^%%s*([^=%%s]+)%%s*=%%s*([^;%%s]+)")
+    if k == %[1]q and v == %[2]q then
+      return true
+    end
+  end
+  return false
+end
+`, route.Name, route.Value)
+	}
+
+	return fmt.Sprintf(`local function route_present(headers)
+  return headers:get(%[1]q) == %[2]q
+end
+`, strings.ToLower(route.Name), route.Value)
+}
@@ -0,0 +1,141 @@
+package propagation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"emperror.dev/errors"
+)
+
+// Status describes how the given service treats the route header.
+type Status string
+
+const (
+	// StatusNotReached means no request carrying the route header arrived to the service.
+	StatusNotReached Status = "not reached"
+	// StatusPropagated means all outgoing requests kept the route header.
+	StatusPropagated Status = "propagated"
+	// StatusPartial means only some of the outgoing requests kept the route header.
+	StatusPartial Status = "partial"
+	// StatusBroken means the service received the route header but none of its outgoing requests carried it.
+	StatusBroken Status = "broken"
+	// StatusNoOutbound means the service received the route header but did not call any other service.
+	StatusNoOutbound Status = "no outbound calls"
+	// StatusUnknown means the sidecar logs of the service could not be read.
+	StatusUnknown Status = "unknown"
+)
+
+// ServiceReport aggregates propagation entries logged by the sidecar of a single service.
+type ServiceReport struct {
+	Service    string
+	Received   int
+	Forwarded  int
+	Missing    []string // authorities called without the route header
+	Unreadable []string // pods which sidecar logs could not be read
+}
+
+// Status determines whether the service preserves the route header.
+func (r *ServiceReport) Status() Status {
+	switch {
+	case r.Received == 0 && len(r.Unreadable) > 0:
+		return StatusUnknown
+	case r.Received == 0:
+		return StatusNotReached
+	case r.Forwarded == 0 && len(r.Missing) == 0:
+		return StatusNoOutbound
+	case r.Forwarded == 0:
+		return StatusBroken
+	case len(r.Missing) > 0:
+		return StatusPartial
+	default:
+		return StatusPropagated
+	}
+}
+
+// Broken returns true if the service is not propagating the route header to any of the called services.
+// Services calling some of their dependencies without the header are not considered broken, as those calls are often
+// made to parts which are not in the mesh, like databases.
+func (r *ServiceReport) Broken() bool {
+	return r.Status() == StatusBroken
+}
+
+// Collect parses sidecar logs of the service and accumulates entries written by the propagation check.
+func (r *ServiceReport) Collect(logs io.Reader) error {
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, LogMarker+" ")
+		if idx < 0 {
+			continue
+		}
+		entry := parseEntry(line[idx+len(LogMarker)+1:])
+		switch {
+		case entry["direction"] == string(Inbound):
+			r.Received++
+		case entry["direction"] == string(Outbound) && entry["header"] == "present":
+			r.Forwarded++
+		case entry["direction"] == string(Outbound):
+			r.Missing = appendUnique(r.Missing, entry["authority"])
+		}
+	}
+
+	return errors.Wrap(scanner.Err(), "failed reading sidecar logs")
+}
+
+func parseEntry(entry string) map[string]string {
+	values := map[string]string{}
+	for _, field := range strings.Fields(entry) {
+		if kv := strings.SplitN(field, "=", 2); len(kv) == 2 {
+			values[kv[0]] = kv[1]
+		}
+	}
+
+	return values
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
+
+// PrintReports writes per service summary of the route header propagation.
+func PrintReports(out io.Writer, reports []*ServiceReport) error {
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Service < reports[j].Service
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(w, "SERVICE\tRECEIVED\tFORWARDED\tSTATUS\tCALLED WITHOUT HEADER"); err != nil {
+		return errors.Wrap(err, "failed writing to out stream")
+	}
+	unreadable := []string{}
+	for _, report := range reports {
+		missing := "<none>"
+		if len(report.Missing) > 0 {
+			missing = strings.Join(report.Missing, ",")
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", report.Service, report.Received, report.Forwarded, report.Status(), missing); err != nil {
+			return errors.Wrap(err, "failed writing to out stream")
+		}
+		unreadable = append(unreadable, report.Unreadable...)
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "failed writing to out stream")
+	}
+	if len(unreadable) > 0 {
+		_, err := fmt.Fprintf(out, "Failed reading sidecar logs of pods: %s\n", strings.Join(unreadable, ", "))
+
+		return errors.Wrap(err, "failed writing to out stream")
+	}
+
+	return nil
+}
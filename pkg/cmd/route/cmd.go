@@ -0,0 +1,152 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"emperror.dev/errors"
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/cmd/config"
+	"github.com/maistra/istio-workspace/pkg/cmd/internal/kube"
+	"github.com/maistra/istio-workspace/pkg/internal/session"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var errNotRoutedToSession = errors.Sentinel("none of the requests was answered by the session version")
+
+// NewCmd creates instance of "route" Cobra Command with flags and execution logic defined.
+func NewCmd() *cobra.Command {
+	routeCmd := &cobra.Command{
+		Use:   "route SESSION",
+		Short: "Verifies that traffic is routed to the Session",
+		Long: "Sends a request with the session route applied, either through the hosts exposed by the Session or to the given in-mesh url, " +
+			"and reports which version answered it together with the call chain if the service reports one. " +
+			"When the session version is not reported by the exposed hosts, e.g. as the ref is further down the call chain, " +
+			"the services of the session refs are called directly",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Wrap(config.SyncFullyQualifiedFlags(cmd), "failed syncing flags")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, _ := cmd.Flags().GetString("namespace")
+			rawURL, _ := cmd.Flags().GetString("url")
+			gateway, _ := cmd.Flags().GetString("gateway")
+			path, _ := cmd.Flags().GetString("path")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			client, err := session.DefaultClient(namespace)
+			if err != nil {
+				return errors.WrapIf(err, "failed to get default client")
+			}
+			sess, err := client.Get(args[0])
+			if err != nil {
+				return errors.WrapIf(err, "failed to get session")
+			}
+
+			versionLabel, _ := cmd.Flags().GetString("version-label")
+			if versionLabel == "" {
+				versionLabel = sess.Spec.VersionLabel
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			clientset, err := kube.Clientset()
+			if err != nil {
+				return err
+			}
+			versions, err := ReadSessionVersions(ctx, clientset, sess, versionLabel)
+			if err != nil {
+				return err
+			}
+
+			requests, err := CreateRequests(sess, versions, rawURL, gateway, path)
+			if err != nil && !errors.Is(err, ErrNoHosts) {
+				return err
+			}
+
+			routed, err := probeAll(ctx, cmd.OutOrStdout(), requests)
+			if err != nil {
+				return err
+			}
+			if !routed && rawURL == "" {
+				// the version is only reported by the hop routed to the session, which might be further down the call chain
+				hops, err := createHopRequests(ctx, clientset, sess, versions, path)
+				if err != nil {
+					return err
+				}
+				if _, err = fmt.Fprintln(cmd.OutOrStdout(), "Calling the services of the session refs directly."); err != nil {
+					return errors.Wrap(err, "failed writing to out stream")
+				}
+				if routed, err = probeAll(ctx, cmd.OutOrStdout(), hops); err != nil {
+					return err
+				}
+			}
+			if !routed {
+				return errNotRoutedToSession
+			}
+
+			return nil
+		},
+	}
+
+	routeCmd.Flags().StringP("namespace", "n", "", "namespace of the session "+
+		"(defaults to default for the current context)")
+	routeCmd.Flags().StringP("url", "u", "", "in-mesh url to call instead of the hosts exposed by the session")
+	routeCmd.Flags().StringP("gateway", "g", "", "address of the ingress gateway to call with session hosts used as Host header")
+	routeCmd.Flags().StringP("path", "p", "/", "path to call on the hosts exposed by the session")
+	routeCmd.Flags().String("version-label", "", "label key holding the version of the services "+
+		"(defaults to the one defined by the session or '"+model.DefaultVersionLabel+"')")
+	routeCmd.Flags().Duration("timeout", 10*time.Second, "maximum time to wait for the responses")
+
+	routeCmd.Flags().VisitAll(config.BindFullyQualifiedFlag(routeCmd))
+
+	return routeCmd
+}
+
+func probeAll(ctx context.Context, out io.Writer, requests []Request) (bool, error) {
+	routed := false
+	for _, request := range requests {
+		result := Probe(ctx, http.DefaultClient, request)
+		if err := PrintResult(out, &result); err != nil {
+			return false, err
+		}
+		routed = routed || result.RoutedToSession()
+	}
+
+	return routed, nil
+}
+
+// ReadSessionVersions reads the version label of the pods cloned for the session, so the probes can tell if they were answered
+// by the session.
+func ReadSessionVersions(ctx context.Context, clientset kubernetes.Interface, sess *istiov1alpha1.Session, versionLabel string) ([]string, error) {
+	pods, err := clientset.CoreV1().Pods(sess.Namespace).List(ctx, metav1.ListOptions{LabelSelector: k8s.ClonePodsSelector().String()})
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, "failed listing pods cloned for the session", "namespace", sess.Namespace)
+	}
+
+	return SessionVersions(sess, pods.Items, versionLabel), nil
+}
+
+func createHopRequests(ctx context.Context, clientset kubernetes.Interface, sess *istiov1alpha1.Session, versions []string,
+	path string) ([]Request, error) {
+	services := []corev1.Service{}
+	for _, name := range TargetServices(sess) {
+		service, err := clientset.CoreV1().Services(name.Namespace).Get(ctx, name.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.WrapWithDetails(err, "failed getting service of the session ref", "name", name.Name)
+		}
+		services = append(services, *service)
+	}
+
+	return CreateHopRequests(sess, versions, services, path), nil
+}
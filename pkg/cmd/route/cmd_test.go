@@ -0,0 +1,209 @@
+package route_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	. "github.com/maistra/istio-workspace/pkg/cmd"
+	"github.com/maistra/istio-workspace/pkg/cmd/route"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	. "github.com/maistra/istio-workspace/test"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Usage of ike route command", func() {
+
+	var routeCmd *cobra.Command
+
+	BeforeEach(func() {
+		routeCmd = route.NewCmd()
+		routeCmd.SilenceUsage = true
+		routeCmd.SilenceErrors = true
+		NewCmd(&k8s.AssumeOperatorInstalled{}).AddCommand(routeCmd)
+	})
+
+	Describe("input validation", func() {
+
+		It("should fail when session is not specified", func() {
+			_, err := ValidateArgumentsOf(routeCmd).Passing("--path", "/productpage")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("accepts 1 arg(s)"))
+		})
+	})
+
+	Describe("preparing requests", func() {
+
+		session := &istiov1alpha1.Session{
+			ObjectMeta: metav1.ObjectMeta{Name: "feature-x"},
+			Status: istiov1alpha1.SessionStatus{
+				Route: &istiov1alpha1.Route{Type: "header", Name: "x-workspace-route", Value: "feature-x"},
+				Hosts: []string{"feature-x.bookinfo.example.com"},
+			},
+		}
+
+		It("should call session hosts with route header", func() {
+			requests, err := route.CreateRequests(session, nil, "", "", "productpage")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL).To(Equal("http://feature-x.bookinfo.example.com/productpage"))
+			Expect(requests[0].Headers).To(HaveKeyWithValue("x-workspace-route", "feature-x"))
+		})
+
+		It("should call session hosts with baggage for baggage route", func() {
+			requests, err := route.CreateRequests(&istiov1alpha1.Session{
+				Status: istiov1alpha1.SessionStatus{
+					Route: &istiov1alpha1.Route{Type: "baggage", Name: "workspace", Value: "feature-x"},
+					Hosts: []string{"feature-x.bookinfo.example.com"},
+				},
+			}, nil, "", "", "/")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests[0].Headers).To(HaveKeyWithValue("baggage", "workspace=feature-x"))
+		})
+
+		It("should call gateway address with session host as Host header", func() {
+			requests, err := route.CreateRequests(session, nil, "", "192.168.39.2:80", "/")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests[0].URL).To(Equal("http://192.168.39.2:80/"))
+			Expect(requests[0].Host).To(Equal("feature-x.bookinfo.example.com"))
+		})
+
+		It("should call in-mesh url instead of session hosts", func() {
+			requests, err := route.CreateRequests(session, nil, "http://reviews:9080/reviews/1", "", "/")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL).To(Equal("http://reviews:9080/reviews/1"))
+		})
+
+		It("should call services of the session refs directly", func() {
+			locatedService, createdAlias := "LocatedService", "CreateService"
+			sess := &istiov1alpha1.Session{
+				ObjectMeta: metav1.ObjectMeta{Name: "feature-x", Namespace: "bookinfo"},
+				Status: istiov1alpha1.SessionStatus{
+					Route: &istiov1alpha1.Route{Type: "header", Name: "x-workspace-route", Value: "feature-x"},
+					Conditions: []*istiov1alpha1.Condition{
+						{Source: istiov1alpha1.Source{Kind: "Service", Name: "ratings", Ref: "ratings-v1"}, Type: &locatedService},
+						{Source: istiov1alpha1.Source{Kind: "Service", Name: "ratings-feature-x", Ref: "ratings-v1"}, Type: &createdAlias},
+						{Source: istiov1alpha1.Source{Kind: "Service", Name: "ratings", Ref: "ratings-v1"}, Type: &locatedService},
+					},
+				},
+			}
+
+			services := route.TargetServices(sess)
+			Expect(services).To(ConsistOf(types.NamespacedName{Namespace: "bookinfo", Name: "ratings"}))
+
+			requests := route.CreateHopRequests(sess, []string{"v1-feature-x"}, []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "ratings", Namespace: "bookinfo"},
+					Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 9080}}},
+				},
+			}, "ratings/0")
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL).To(Equal("http://ratings.bookinfo:9080/ratings/0"))
+			Expect(requests[0].Headers).To(HaveKeyWithValue("x-workspace-route", "feature-x"))
+			Expect(requests[0].Versions).To(ConsistOf("v1-feature-x"))
+		})
+
+		It("should expect versions of the pods cloned for the session", func() {
+			pod := func(name, version, session string) corev1.Pod {
+				pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app.version": version}}}
+				Expect(reference.Add(types.NamespacedName{Namespace: "bookinfo", Name: session}, &pod)).To(Succeed())
+
+				return pod
+			}
+
+			sess := &istiov1alpha1.Session{ObjectMeta: metav1.ObjectMeta{Name: "feature-x", Namespace: "bookinfo"}}
+
+			versions := route.SessionVersions(sess, []corev1.Pod{
+				pod("ratings-v1-feature-x-1", "v1-feature-x", "feature-x"),
+				pod("ratings-v1-feature-x-2", "v1-feature-x", "feature-x"),
+				pod("ratings-v1-feature-y-1", "v1-feature-y", "feature-y"),
+			}, "app.version")
+
+			Expect(versions).To(ConsistOf("v1-feature-x"))
+		})
+
+		It("should fail when session exposes no hosts and no url is given", func() {
+			_, err := route.CreateRequests(&istiov1alpha1.Session{}, nil, "", "", "/")
+
+			Expect(err).To(MatchError(route.ErrNoHosts))
+		})
+	})
+
+	Describe("probing", func() {
+
+		var server *httptest.Server
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should report session version and call chain", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("x-workspace-route") == "feature-x" {
+					w.Header().Set(istio.ResponseVersionHeader, "v1-feature-x")
+				}
+				fmt.Fprint(w, `{"caller":"productpage","protocol":"http","path":"/","called":[{"caller":"reviews","protocol":"http","path":"/reviews"}]}`)
+			}))
+
+			result := route.Probe(context.Background(), server.Client(), route.Request{
+				URL:      server.URL,
+				Headers:  map[string]string{"x-workspace-route": "feature-x"},
+				Versions: []string{"v1-feature-x"},
+			})
+
+			Expect(result.RoutedToSession()).To(BeTrue())
+			Expect(result.Version).To(Equal("v1-feature-x"))
+
+			out := new(bytes.Buffer)
+			Expect(route.PrintResult(out, &result)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("answered by session version: v1-feature-x"))
+			Expect(out.String()).To(ContainSubstring("    productpage (http /)\n      reviews (http /reviews)"))
+		})
+
+		It("should report when answered outside of the session", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "plain response")
+			}))
+
+			result := route.Probe(context.Background(), server.Client(), route.Request{URL: server.URL})
+
+			Expect(result.RoutedToSession()).To(BeFalse())
+			Expect(result.CallStack).To(BeNil())
+
+			out := new(bytes.Buffer)
+			Expect(route.PrintResult(out, &result)).To(Succeed())
+			Expect(strings.TrimSpace(out.String())).To(HaveSuffix("answered by a version outside of the session"))
+		})
+
+		It("should report version not created for the session as outside of the session", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(istio.ResponseVersionHeader, "v1-feature-y")
+			}))
+
+			result := route.Probe(context.Background(), server.Client(), route.Request{URL: server.URL, Versions: []string{"v1-feature-x"}})
+
+			Expect(result.RoutedToSession()).To(BeFalse())
+
+			out := new(bytes.Buffer)
+			Expect(route.PrintResult(out, &result)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("answered by version outside of the session: v1-feature-y"))
+		})
+	})
+})
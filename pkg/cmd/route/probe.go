@@ -0,0 +1,267 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"emperror.dev/errors"
+	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CallStack is the call chain reported by the called service. It follows the response format of the test-service.
+type CallStack struct {
+	Caller   string       `json:"caller,omitempty"`
+	Protocol string       `json:"protocol,omitempty"`
+	Path     string       `json:"path,omitempty"`
+	Called   []*CallStack `json:"called,omitempty"`
+}
+
+// ErrNoHosts is returned when the session does not expose any hosts to call.
+var ErrNoHosts = errors.Sentinel("session does not expose any hosts, provide in-mesh url instead")
+
+// Request describes a single verification call for the session route.
+type Request struct {
+	URL      string
+	Host     string // overrides Host header, e.g. when calling the gateway address directly
+	Headers  map[string]string
+	Versions []string // values of the version label of the workloads cloned by the session
+}
+
+// Result holds the outcome of a verification call.
+type Result struct {
+	Request    Request
+	StatusCode int
+	Version    string     // version reported by the route which answered the call, empty if none did
+	CallStack  *CallStack // call chain if the response body follows CallStack format
+	Error      error
+}
+
+// RoutedToSession returns true if the request was answered by one of the versions created for the session.
+func (r *Result) RoutedToSession() bool {
+	if r.Error != nil || r.Version == "" {
+		return false
+	}
+	for _, version := range r.Request.Versions {
+		if version == r.Version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SessionVersions returns the values of the version label of the pods cloned for the session, as these are the versions
+// the session route is expected to be answered by.
+func SessionVersions(session *istiov1alpha1.Session, pods []corev1.Pod, versionLabel string) []string {
+	if versionLabel == "" {
+		versionLabel = model.DefaultVersionLabel
+	}
+	owner := types.NamespacedName{Namespace: session.Namespace, Name: session.Name}
+	versions := []string{}
+	found := map[string]bool{}
+	for i := range pods {
+		pod := &pods[i]
+		version := pod.Labels[versionLabel]
+		if version == "" || found[version] || !referencedBy(pod, owner) {
+			continue
+		}
+		found[version] = true
+		versions = append(versions, version)
+	}
+
+	return versions
+}
+
+func referencedBy(pod *corev1.Pod, owner types.NamespacedName) bool {
+	for _, ref := range reference.Get(pod) {
+		if ref == owner {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateRequests prepares verification requests for either the given URL or all hosts exposed by the session.
+// If gateway address is provided, requests for session hosts are sent to it with Host header set accordingly.
+// The versions are the ones expected to answer the requests, see SessionVersions.
+func CreateRequests(session *istiov1alpha1.Session, versions []string, rawURL, gateway, path string) ([]Request, error) {
+	headers := routeHeaders(session.Status.Route)
+	if rawURL != "" {
+		if _, err := url.ParseRequestURI(rawURL); err != nil {
+			return nil, errors.WrapWithDetails(err, "failed parsing url", "url", rawURL)
+		}
+
+		return []Request{{URL: rawURL, Headers: headers, Versions: versions}}, nil
+	}
+
+	path = toPath(path)
+	requests := make([]Request, 0, len(session.Status.Hosts))
+	for _, host := range session.Status.Hosts {
+		if host == "" {
+			continue
+		}
+		request := Request{URL: "http://" + host + path, Headers: headers, Versions: versions}
+		if gateway != "" {
+			request.URL = "http://" + gateway + path
+			request.Host = host
+		}
+		requests = append(requests, request)
+	}
+	if len(requests) == 0 {
+		return nil, errors.WithDetails(ErrNoHosts, "session", session.Name)
+	}
+
+	return requests, nil
+}
+
+// TargetServices returns the services of the deployments participating in the session, as located by the operator.
+func TargetServices(session *istiov1alpha1.Session) []types.NamespacedName {
+	services := []types.NamespacedName{}
+	located := map[types.NamespacedName]bool{}
+	for _, condition := range session.Status.Conditions {
+		if condition.Source.Kind != k8s.ServiceKind || condition.Type == nil || *condition.Type != "LocatedService" {
+			continue
+		}
+		service := types.NamespacedName{Namespace: condition.Source.Namespace, Name: condition.Source.Name}
+		if service.Namespace == "" {
+			service.Namespace = session.Namespace
+		}
+		if !located[service] {
+			located[service] = true
+			services = append(services, service)
+		}
+	}
+
+	return services
+}
+
+// CreateHopRequests prepares verification requests calling the given services of the session refs directly, as the version
+// is only reported by the hop routed to the session. This way refs further down the call chain than the hosts exposed
+// by the session can be verified too, as long as the services are reachable, e.g. through telepresence.
+func CreateHopRequests(session *istiov1alpha1.Session, versions []string, services []corev1.Service, path string) []Request {
+	headers := routeHeaders(session.Status.Route)
+	path = toPath(path)
+	requests := make([]Request, 0, len(services))
+	for i := range services {
+		service := services[i]
+		if len(service.Spec.Ports) == 0 {
+			continue
+		}
+		url := fmt.Sprintf("http://%s.%s:%d%s", service.Name, service.Namespace, service.Spec.Ports[0].Port, path)
+		requests = append(requests, Request{URL: url, Headers: headers, Versions: versions})
+	}
+
+	return requests
+}
+
+func toPath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+
+	return path
+}
+
+func routeHeaders(route *istiov1alpha1.Route) map[string]string {
+	headers := map[string]string{}
+	if route == nil {
+		return headers
+	}
+	switch route.Type {
+	case model.RouteTypeHeader:
+		headers[route.Name] = route.Value
+	case model.RouteTypeBaggage:
+		headers[istio.BaggageHeader] = route.Name + "=" + route.Value
+	}
+
+	return headers
+}
+
+// Probe sends the request and inspects which version answered it.
+func Probe(ctx context.Context, client *http.Client, request Request) Result {
+	result := Result{Request: request}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, request.URL, http.NoBody)
+	if err != nil {
+		result.Error = errors.WrapWithDetails(err, "failed creating request", "url", request.URL)
+
+		return result
+	}
+	if request.Host != "" {
+		req.Host = request.Host
+	}
+	for k, v := range request.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = errors.WrapWithDetails(err, "failed executing HTTP call", "url", request.URL)
+
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	// the header is set by the route to the subset it sends the call to, named after the version label of its pods
+	result.Version = resp.Header.Get(istio.ResponseVersionHeader)
+
+	if body, err := io.ReadAll(resp.Body); err == nil {
+		callStack := CallStack{}
+		if json.Unmarshal(body, &callStack) == nil && callStack.Caller != "" {
+			result.CallStack = &callStack
+		}
+	}
+
+	return result
+}
+
+// PrintResult writes human readable summary of the verification call.
+func PrintResult(out io.Writer, result *Result) error {
+	var b strings.Builder
+	target := result.Request.URL
+	if result.Request.Host != "" {
+		target += " (Host: " + result.Request.Host + ")"
+	}
+
+	if result.Error != nil {
+		fmt.Fprintf(&b, "GET %s failed: %s\n", target, result.Error.Error())
+	} else {
+		fmt.Fprintf(&b, "GET %s [%d]\n", target, result.StatusCode)
+		switch {
+		case result.RoutedToSession():
+			fmt.Fprintf(&b, "  answered by session version: %s\n", result.Version)
+		case result.Version != "":
+			fmt.Fprintf(&b, "  answered by version outside of the session: %s\n", result.Version)
+		default:
+			b.WriteString("  answered by a version outside of the session\n")
+		}
+		if result.CallStack != nil {
+			b.WriteString("  call chain:\n")
+			writeCallStack(&b, result.CallStack, 2)
+		}
+	}
+
+	_, err := io.WriteString(out, b.String())
+
+	return errors.Wrap(err, "failed writing to out stream")
+}
+
+func writeCallStack(b *strings.Builder, callStack *CallStack, depth int) {
+	fmt.Fprintf(b, "%s%s (%s %s)\n", strings.Repeat("  ", depth), callStack.Caller, callStack.Protocol, callStack.Path)
+	for _, called := range callStack.Called {
+		writeCallStack(b, called, depth+1)
+	}
+}
@@ -10,9 +10,13 @@ import (
 	"github.com/maistra/istio-workspace/api"
 	"github.com/maistra/istio-workspace/controllers"
 	"github.com/maistra/istio-workspace/pkg/cmd/version"
+	"github.com/maistra/istio-workspace/pkg/k8s"
 	"github.com/maistra/istio-workspace/pkg/log"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	k8sConfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -66,11 +70,15 @@ func startOperator(cmd *cobra.Command, args []string) error {
 		LeaderElectionID:       "istio-workspace-lock",
 	}
 
+	newCache := cache.New
 	if len(namespaces) == 1 {
 		managerOptions.Namespace = namespaces[0]
 	} else {
-		managerOptions.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		newCache = cache.MultiNamespacedCacheBuilder(namespaces)
 	}
+	managerOptions.NewCache = selectingClonePods(newCache)
+	// pods are read from the API server, so the ones not cached, e.g. of the gateway workloads, are found too
+	managerOptions.ClientDisableCacheFor = []client.Object{&corev1.Pod{}}
 
 	// Create a new Cmd to provide shared dependencies and Start components
 	mgr, err := manager.New(cfg, managerOptions)
@@ -111,6 +119,16 @@ func startOperator(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// selectingClonePods limits the cached pods to the ones of the workloads cloned by the sessions, as these are the only pods
+// the operator watches.
+func selectingClonePods(newCache cache.NewCacheFunc) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		opts.SelectorsByObject = cache.SelectorsByObject{&corev1.Pod{}: {Label: k8s.ClonePodsSelector()}}
+
+		return newCache(config, opts)
+	}
+}
+
 // getWatchNamespace returns the namespace the operator should be watching for changes.
 func getWatchNamespace() (string, error) {
 	ns, found := os.LookupEnv(watchNamespaceEnvVar)
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"encoding/json"
 	"os/user"
 	"regexp"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	sessionController "github.com/maistra/istio-workspace/controllers/session"
 	"github.com/maistra/istio-workspace/pkg/k8s"
 	"github.com/maistra/istio-workspace/pkg/log"
 	"github.com/maistra/istio-workspace/pkg/naming"
@@ -25,6 +27,14 @@ var (
 	errorWrongRouteFormat = errors.Sentinel("route in wrong format. expected type:name=value")
 )
 
+const (
+	// LabelOwner is the label key holding the name of the user who created the Session.
+	LabelOwner = "ike.owner"
+	// AnnotationPreviousRefs is the annotation key holding the refs replaced by joining the Session with Revert option,
+	// so they can be restored when leaving it later on.
+	AnnotationPreviousRefs = "ike.previous-refs"
+)
+
 // Options holds the variables used by the Session Handler.
 type Options struct {
 	NamespaceName  string            // name of the namespace for target resource
@@ -34,6 +44,7 @@ type Options struct {
 	Strategy       string            // name of the strategy to use for the target resource
 	StrategyArgs   map[string]string // additional arguments for the strategy
 	Revert         bool              // Revert back to previous known value if join/leave a existing session with a known ref
+	Keep           bool              // Keep the session even if the last ref leaves it
 	Duration       *time.Duration    // Duration defines the interval used to check for changes to the session object
 }
 
@@ -42,6 +53,8 @@ type State struct {
 	DeploymentName string              // name of the resource to target within the cloned route.
 	Hosts          []string            // currently exposed hosts
 	Route          istiov1alpha1.Route // the current route configuration
+	Refs           []string            // names of all refs participating in the session
+	Removed        bool                // true if the session was removed as no refs were left
 }
 
 // Handler is a function to setup a server session before attempting to connect. Returns a 'cleanup' function.
@@ -94,16 +107,78 @@ func CreateOrJoinHandler(opts Options, client *Client) (State, func(), error) {
 	if err != nil {
 		return State{}, h.removeOrLeaveSession, err
 	}
+
+	return toState(session, serviceName), h.removeOrLeaveSession, nil
+}
+
+// JoinHandler adds the ref to an already existing session, or updates it if the ref already participates in it.
+// Fails if the session does not exist.
+func JoinHandler(opts Options, client *Client) (State, error) {
+	h := &handler{c: client, opts: opts}
+
+	session, err := h.c.Get(h.opts.SessionName)
+	if err != nil {
+		return State{}, errors.WrapIfWithDetails(err, "failed to find session to join", "session", h.opts.SessionName)
+	}
+
+	session, serviceName, err := h.joinSession(session)
+	if err != nil {
+		return State{}, err
+	}
+
+	return toState(session, serviceName), nil
+}
+
+// LeaveHandler removes the ref from an existing session. The session itself is deleted when no other refs participate in it,
+// unless Keep option is set.
+func LeaveHandler(opts Options, client *Client) (State, error) {
+	h := &handler{c: client, opts: opts}
+
+	existing, err := h.c.Get(h.opts.SessionName)
+	if err != nil {
+		return State{}, errors.WrapIfWithDetails(err, "failed to find session to leave", "session", h.opts.SessionName)
+	}
+	if !participates(existing, h.opts.DeploymentName) {
+		return toState(existing, ""), RefNotFoundError{name: h.opts.DeploymentName, session: h.opts.SessionName}
+	}
+
+	session, removed, err := h.leaveSession()
+	if err != nil {
+		return State{}, err
+	}
+
+	state := toState(session, "")
+	state.Removed = removed
+
+	return state, nil
+}
+
+func participates(session *istiov1alpha1.Session, refName string) bool {
+	for _, ref := range session.Spec.Refs {
+		if ref.Name == refName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toState(session *istiov1alpha1.Session, serviceName string) State {
 	route := session.Status.Route
 	if route == nil {
 		route = &istiov1alpha1.Route{}
 	}
+	refs := make([]string, 0, len(session.Spec.Refs))
+	for _, ref := range session.Spec.Refs {
+		refs = append(refs, ref.Name)
+	}
 
 	return State{
 		DeploymentName: serviceName,
 		Hosts:          session.Status.Hosts,
 		Route:          *route,
-	}, h.removeOrLeaveSession, nil
+		Refs:           refs,
+	}
 }
 
 func (h *handler) createSession() (*istiov1alpha1.Session, error) {
@@ -130,6 +205,10 @@ func (h *handler) createSession() (*istiov1alpha1.Session, error) {
 		session.Spec.Route = *r
 	}
 
+	if owner, err := CurrentUser(); err == nil {
+		session.Labels = map[string]string{LabelOwner: owner}
+	}
+
 	return &session, h.c.Create(&session)
 }
 
@@ -144,6 +223,12 @@ func (h *handler) createOrJoinSession() (*istiov1alpha1.Session, string, error)
 
 		return h.waitForRefToComplete()
 	}
+
+	return h.joinSession(session)
+}
+
+// joinSession adds the ref to the given session or replaces it if already participating, waiting for the 'success' status.
+func (h *handler) joinSession(session *istiov1alpha1.Session) (*istiov1alpha1.Session, string, error) {
 	ref := istiov1alpha1.Ref{Name: h.opts.DeploymentName, Strategy: h.opts.Strategy, Args: h.opts.StrategyArgs}
 	// update ref in session
 	for i, r := range session.Spec.Refs {
@@ -153,7 +238,10 @@ func (h *handler) createOrJoinSession() (*istiov1alpha1.Session, string, error)
 		prev := session.Spec.Refs[i]
 		h.previousState = &prev // point to a variable, not a array index
 		session.Spec.Refs[i] = ref
-		err = h.c.Update(session)
+		if err := h.rememberPreviousState(session); err != nil {
+			return session, "", err
+		}
+		err := h.c.Update(session)
 		if err != nil {
 			return session, "", err
 		}
@@ -162,7 +250,10 @@ func (h *handler) createOrJoinSession() (*istiov1alpha1.Session, string, error)
 	}
 	// join session
 	session.Spec.Refs = append(session.Spec.Refs, ref)
-	err = h.c.Update(session)
+	if err := h.rememberPreviousState(session); err != nil {
+		return session, "", err
+	}
+	err := h.c.Update(session)
 	if err != nil {
 		return session, "", err
 	}
@@ -186,9 +277,15 @@ func (h *handler) waitForRefToComplete() (*istiov1alpha1.Session, string, error)
 		if sessionStatus.Status.State != nil && *sessionStatus.Status.State == istiov1alpha1.StateSuccess {
 			return true, nil
 		}
+		if failure := refFailure(sessionStatus, h.opts.DeploymentName); failure != nil {
+			return false, failure
+		}
 
 		return false, nil
 	})
+	if errors.As(err, &RefFailedError{}) {
+		return sessionStatus, "", err
+	}
 	if err != nil {
 		return sessionStatus, "", errors.Wrap(err, "timed out waiting for success")
 	}
@@ -201,6 +298,22 @@ func (h *handler) waitForRefToComplete() (*istiov1alpha1.Session, string, error)
 	return sessionStatus, "", DeploymentNotFoundError{name: h.opts.DeploymentName}
 }
 
+// refFailure returns the error reported for the failed ref, so there is no need to wait for the session any longer.
+func refFailure(session *istiov1alpha1.Session, name string) error {
+	if session.Status.State == nil || *session.Status.State != istiov1alpha1.StateFailed {
+		return nil
+	}
+	for _, condition := range session.Status.Conditions {
+		failed := condition.Status != nil && *condition.Status == istiov1alpha1.StatusFailed
+		warning := condition.Reason != nil && *condition.Reason == sessionController.WarningReason // warnings do not fail the session
+		if failed && !warning && refMatchesDeploymentName(condition, name) && condition.Message != nil {
+			return RefFailedError{name: name, reason: *condition.Message}
+		}
+	}
+
+	return nil
+}
+
 func notDeleted(condition *istiov1alpha1.Condition) bool {
 	return condition.Type != nil && *condition.Type != "delete"
 }
@@ -214,11 +327,28 @@ func refMatchesDeploymentName(condition *istiov1alpha1.Condition, name string) b
 }
 
 func (h *handler) removeOrLeaveSession() {
-	session, err := h.c.Get(h.opts.SessionName)
-	if err != nil {
+	if _, _, err := h.leaveSession(); err != nil {
 		logger().Error(err, "failed removing or leaving session")
+	}
+}
 
-		return // assume missing, nothing to clean?
+// leaveSession removes the ref from the session (or reverts it to its previous state) and deletes the session
+// if no refs are left, unless asked to keep it.
+func (h *handler) leaveSession() (session *istiov1alpha1.Session, removed bool, err error) {
+	session, err = h.c.Get(h.opts.SessionName)
+	if err != nil {
+		return nil, false, err // assume missing, nothing to clean?
+	}
+	previous, err := previousRefs(session)
+	if err != nil {
+		return session, false, err
+	}
+	if prev, found := previous[h.opts.DeploymentName]; found && h.previousState == nil {
+		h.previousState = &prev
+	}
+	delete(previous, h.opts.DeploymentName)
+	if err = setPreviousRefs(session, previous); err != nil {
+		return session, false, err
 	}
 	// more than one participant, update session
 	for i, r := range session.Spec.Refs {
@@ -228,13 +358,63 @@ func (h *handler) removeOrLeaveSession() {
 			} else {
 				session.Spec.Refs = append(session.Spec.Refs[:i], session.Spec.Refs[i+1:]...)
 			}
+
+			break
 		}
 	}
-	if len(session.Spec.Refs) == 0 {
-		_ = h.c.Delete(session)
-	} else {
-		_ = h.c.Update(session)
+	if len(session.Spec.Refs) == 0 && !h.opts.Keep {
+		return session, true, h.c.Delete(session)
+	}
+
+	return session, false, h.c.Update(session)
+}
+
+// rememberPreviousState keeps the ref replaced by joining the session in its annotations when asked to revert it on leave,
+// so it can be restored by a different invocation. Joining without Revert option clears the ref kept before.
+func (h *handler) rememberPreviousState(session *istiov1alpha1.Session) error {
+	previous, err := previousRefs(session)
+	if err != nil {
+		return err
+	}
+	if _, found := previous[h.opts.DeploymentName]; !found && h.opts.Revert && h.previousState != nil {
+		previous[h.opts.DeploymentName] = *h.previousState
+	}
+	if !h.opts.Revert {
+		delete(previous, h.opts.DeploymentName)
 	}
+
+	return setPreviousRefs(session, previous)
+}
+
+func previousRefs(session *istiov1alpha1.Session) (map[string]istiov1alpha1.Ref, error) {
+	previous := map[string]istiov1alpha1.Ref{}
+	value, found := session.Annotations[AnnotationPreviousRefs]
+	if !found {
+		return previous, nil
+	}
+	if err := json.Unmarshal([]byte(value), &previous); err != nil {
+		return nil, errors.WrapWithDetails(err, "failed reading previous refs", "session", session.Name)
+	}
+
+	return previous, nil
+}
+
+func setPreviousRefs(session *istiov1alpha1.Session, previous map[string]istiov1alpha1.Ref) error {
+	if len(previous) == 0 {
+		delete(session.Annotations, AnnotationPreviousRefs)
+
+		return nil
+	}
+	value, err := json.Marshal(previous)
+	if err != nil {
+		return errors.WrapWithDetails(err, "failed storing previous refs", "session", session.Name)
+	}
+	if session.Annotations == nil {
+		session.Annotations = map[string]string{}
+	}
+	session.Annotations[AnnotationPreviousRefs] = string(value)
+
+	return nil
 }
 
 var nonAlphaNumeric = regexp.MustCompile("[^A-Za-z0-9]+")
@@ -265,6 +445,26 @@ func getOrCreateSessionName(sessionName string) (string, error) {
 	return nonAlphaNumeric.ReplaceAllString(sessionName, "-"), nil
 }
 
+// CurrentUser returns the name of the user running ike in a form which can be used as a label value.
+func CurrentUser() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", errors.Wrap(err, "failed obtaining current user")
+	}
+
+	return ToLabelValue(u.Username), nil
+}
+
+// ToLabelValue converts given name to a valid label value by replacing all non-alphanumeric characters.
+func ToLabelValue(name string) string {
+	value := nonAlphaNumeric.ReplaceAllString(name, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+
+	return strings.Trim(value, "-")
+}
+
 // ParseRoute maps string route representation into a Route struct by unwrapping its type, name and value.
 func ParseRoute(route string) (*istiov1alpha1.Route, error) {
 	if route == "" {
@@ -99,3 +99,19 @@ func (c *Client) Get(sessionName string) (*istiov1alpha1.Session, error) {
 
 	return session, errors.WrapWithDetails(err, "failed retrieving session", "kind", "session", "name", sessionName, "namespace", c.namespace)
 }
+
+// List retrieves all Sessions in the client namespace matching given label selector. Empty selector matches everything.
+func (c *Client) List(labelSelector string) (*istiov1alpha1.SessionList, error) {
+	return c.list(c.namespace, labelSelector)
+}
+
+// ListAllNamespaces retrieves Sessions across all namespaces matching given label selector. Empty selector matches everything.
+func (c *Client) ListAllNamespaces(labelSelector string) (*istiov1alpha1.SessionList, error) {
+	return c.list(metav1.NamespaceAll, labelSelector)
+}
+
+func (c *Client) list(namespace, labelSelector string) (*istiov1alpha1.SessionList, error) {
+	sessions, err := c.WorkspaceV1alpha1().Sessions(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: labelSelector})
+
+	return sessions, errors.WrapWithDetails(err, "failed listing sessions", "kind", "session", "namespace", namespace, "selector", labelSelector)
+}
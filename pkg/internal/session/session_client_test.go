@@ -69,4 +69,49 @@ var _ = Describe("Session Client operations", func() {
 		})
 
 	})
+
+	Context("Session listing", func() {
+
+		owned := func(name, namespace, owner string) *istiov1alpha1.Session {
+			return &istiov1alpha1.Session{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{session.LabelOwner: owner},
+				},
+			}
+		}
+
+		fakeClient := testclient.NewSimpleClientset(
+			owned("first", "test-namespace", "alice"),
+			owned("second", "test-namespace", "bob"),
+			owned("third", "other-namespace", "alice"),
+		)
+		client, _ := session.NewClient(fakeClient, "test-namespace")
+
+		It("should list sessions in client namespace", func() {
+			sessions, err := client.List("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sessions.Items).To(HaveLen(2))
+		})
+
+		It("should list sessions across all namespaces", func() {
+			sessions, err := client.ListAllNamespaces("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sessions.Items).To(HaveLen(3))
+		})
+
+		It("should list only sessions matching owner", func() {
+			sessions, err := client.ListAllNamespaces(session.LabelOwner + "=alice")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sessions.Items).To(HaveLen(2))
+			for _, s := range sessions.Items {
+				Expect(s.Labels).To(HaveKeyWithValue(session.LabelOwner, "alice"))
+			}
+		})
+
+	})
 })
@@ -1,6 +1,7 @@
 package session_test
 
 import (
+	"strings"
 	"time"
 
 	istiov1alpha1 "github.com/maistra/istio-workspace/api/maistra/v1alpha1"
@@ -59,6 +60,39 @@ var _ = Describe("Session operations", func() {
 
 				Expect(sess.Spec.Refs).To(HaveLen(1))
 			})
+
+			It("should record the user creating the session", func() {
+				// given - no exiting sessions
+				// when - adding a ref to a session
+				_, remove, err := session.CreateOrJoinHandler(opts, client)
+				defer remove()
+				Expect(err).ToNot(HaveOccurred())
+
+				// then - the session should be labeled with the current user
+				sess, err := client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+
+				owner, err := session.CurrentUser()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.Labels).To(HaveKeyWithValue(session.LabelOwner, owner))
+			})
+
+			It("should fail without waiting for the session when ref fails", func() {
+				// given - the session failing to apply the ref
+				updateRemover()
+				updateRemover = addFailedSessionRefStatus(client, opts.SessionName, "test-deployment-clone-abc/app CrashLoopBackOff")
+				duration := 30 * time.Second
+				opts.Duration = &duration
+
+				// when - adding a ref to a session
+				started := time.Now()
+				_, _, err := session.CreateOrJoinHandler(opts, client)
+
+				// then - the failure should be reported before the session times out
+				Expect(err).To(BeAssignableToTypeOf(session.RefFailedError{}))
+				Expect(err.Error()).To(ContainSubstring("CrashLoopBackOff"))
+				Expect(time.Since(started)).To(BeNumerically("<", duration))
+			})
 		})
 		Context("join", func() {
 			BeforeEach(func() {
@@ -123,6 +157,71 @@ var _ = Describe("Session operations", func() {
 				Expect(sess.Spec.Refs[0].Strategy).To(Equal(preparedImage))
 			})
 
+			It("should revert ref to previous state when leaving explicitly", func() {
+				// given - an existing ref of prepared-image updated with telepresence by explicit join
+				opts.Revert = true
+				opts.DeploymentName += "-1"
+				opts.Strategy = telepresence
+				_, err := session.JoinHandler(opts, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				sess, err := client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.Annotations).To(HaveKey(session.AnnotationPreviousRefs))
+
+				// when - the ref leaves the session in a separate invocation
+				_, err = session.LeaveHandler(session.Options{SessionName: opts.SessionName, DeploymentName: opts.DeploymentName, Revert: true}, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then - expect the ref to be back to prepared-image
+				sess, err = client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(sess.Spec.Refs).To(HaveLen(1))
+				Expect(sess.Spec.Refs[0].Strategy).To(Equal(preparedImage))
+				Expect(sess.Annotations).ToNot(HaveKey(session.AnnotationPreviousRefs))
+			})
+
+			It("should remove ref when leaving explicitly without revert", func() {
+				// given - an existing ref of prepared-image updated with telepresence by explicit join
+				opts.Revert = true
+				opts.DeploymentName += "-1"
+				opts.Strategy = telepresence
+				_, err := session.JoinHandler(opts, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// when - the ref leaves the session asking not to revert it
+				state, err := session.LeaveHandler(session.Options{SessionName: opts.SessionName, DeploymentName: opts.DeploymentName, Keep: true}, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then - expect the ref to be gone together with its previous state
+				Expect(state.Removed).To(BeFalse())
+				sess, err := client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(sess.Spec.Refs).To(BeEmpty())
+				Expect(sess.Annotations).ToNot(HaveKey(session.AnnotationPreviousRefs))
+			})
+
+			It("should forget previous state when joining again without revert", func() {
+				// given - an existing ref of prepared-image updated with telepresence by explicit join
+				opts.Revert = true
+				opts.DeploymentName += "-1"
+				opts.Strategy = telepresence
+				_, err := session.JoinHandler(opts, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// when - the ref joins again clearing revert
+				opts.Revert = false
+				_, err = session.JoinHandler(opts, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then - there is nothing to revert to
+				sess, err := client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.Annotations).ToNot(HaveKey(session.AnnotationPreviousRefs))
+			})
+
 			It("should not revert if ref was never updated", func() {
 				// given - an existing ref of prepared-image
 
@@ -150,6 +249,108 @@ var _ = Describe("Session operations", func() {
 				Expect(sess.Spec.Refs[0].Strategy).To(Equal(preparedImage))
 			})
 		})
+		Context("explicit join", func() {
+			BeforeEach(func() {
+				objects = []runtime.Object{}
+			})
+
+			It("should fail when session does not exist", func() {
+				// given - no existing sessions
+				// when - joining a session
+				_, err := session.JoinHandler(opts, client)
+
+				// then - it should fail and no session should be created
+				Expect(err).To(HaveOccurred())
+				_, err = client.Get(opts.SessionName)
+				Expect(err).To(HaveOccurred())
+			})
+
+			When("session exists", func() {
+				BeforeEach(func() {
+					objects = []runtime.Object{
+						&istiov1alpha1.Session{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      opts.SessionName,
+								Namespace: opts.NamespaceName,
+							},
+							Spec: istiov1alpha1.SessionSpec{
+								Refs: []istiov1alpha1.Ref{
+									{Name: "other-deployment", Strategy: opts.Strategy},
+								},
+							},
+						}}
+				})
+
+				It("should add ref and report all participating refs", func() {
+					// given - an existing session with another ref
+					// when - joining the session
+					state, err := session.JoinHandler(opts, client)
+					Expect(err).ToNot(HaveOccurred())
+
+					// then - both refs participate
+					Expect(state.Refs).To(ConsistOf("other-deployment", opts.DeploymentName))
+					sess, err := client.Get(opts.SessionName)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(sess.Spec.Refs).To(HaveLen(2))
+				})
+			})
+		})
+		Context("explicit leave", func() {
+			BeforeEach(func() {
+				objects = []runtime.Object{
+					&istiov1alpha1.Session{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      opts.SessionName,
+							Namespace: opts.NamespaceName,
+						},
+						Spec: istiov1alpha1.SessionSpec{
+							Refs: []istiov1alpha1.Ref{
+								{Name: opts.DeploymentName, Strategy: opts.Strategy},
+							},
+						},
+					}}
+			})
+
+			It("should remove session when last ref leaves", func() {
+				// given - a session with a single ref
+				// when - the ref leaves
+				state, err := session.LeaveHandler(opts, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then - the session is removed
+				Expect(state.Removed).To(BeTrue())
+				_, err = client.Get(opts.SessionName)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should keep session when last ref leaves if asked to", func() {
+				// given - a session with a single ref
+				// when - the ref leaves keeping the session
+				opts.Keep = true
+				state, err := session.LeaveHandler(opts, client)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then - the session is kept without refs
+				Expect(state.Removed).To(BeFalse())
+				sess, err := client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.Spec.Refs).To(BeEmpty())
+			})
+
+			It("should fail when ref does not participate", func() {
+				// given - a session with a single ref
+				// when - another ref tries to leave
+				opts.DeploymentName = "not-participating"
+				_, err := session.LeaveHandler(opts, client)
+
+				// then - it fails leaving the session untouched
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("is not participating in session"))
+				sess, err := client.Get(opts.SessionName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.Spec.Refs).To(HaveLen(1))
+			})
+		})
 		Context("remove", func() {
 			BeforeEach(func() {
 				objects = []runtime.Object{
@@ -185,6 +386,21 @@ var _ = Describe("Session operations", func() {
 		})
 	})
 
+	Context("label values", func() {
+
+		It("should replace non alphanumeric characters", func() {
+			Expect(session.ToLabelValue(`DOMAIN\john.doe`)).To(Equal("DOMAIN-john-doe"))
+		})
+
+		It("should not start or end with a separator", func() {
+			Expect(session.ToLabelValue("_john_")).To(Equal("john"))
+		})
+
+		It("should not exceed maximum label value length", func() {
+			Expect(session.ToLabelValue(strings.Repeat("a", 100))).To(HaveLen(63))
+		})
+	})
+
 	Context("route parsing", func() {
 
 		It("should return nil with no error on empty string", func() {
@@ -272,3 +488,31 @@ func addSessionRefStatus(c *session.Client, sessionName string) func() {
 		done = true
 	}
 }
+
+func addFailedSessionRefStatus(c *session.Client, sessionName, message string) func() {
+	done := false
+	go func() {
+		for !done {
+			time.Sleep(5 * time.Millisecond)
+			sess, err := c.Get(sessionName)
+			if err != nil || sess.Status.State != nil {
+				continue
+			}
+			failed := istiov1alpha1.StatusFailed
+			for _, ref := range sess.Spec.Refs {
+				sess.Status.Conditions = append(sess.Status.Conditions, &istiov1alpha1.Condition{
+					Source:  istiov1alpha1.Source{Ref: ref.Name, Kind: "Deployment", Name: ref.Name},
+					Status:  &failed,
+					Message: &message,
+				})
+			}
+			state := istiov1alpha1.StateFailed
+			sess.Status.State = &state
+			Expect(c.Update(sess)).To(Succeed())
+		}
+	}()
+
+	return func() {
+		done = true
+	}
+}
@@ -11,3 +11,25 @@ type DeploymentNotFoundError struct {
 func (dnfe DeploymentNotFoundError) Error() string {
 	return fmt.Sprintf("no Deployment or DeploymentConfig found for target '%s'", dnfe.name)
 }
+
+// RefNotFoundError denotes that given ref does not participate in the session.
+type RefNotFoundError struct {
+	name    string
+	session string
+}
+
+// Error returns the formatted ref error.
+func (rnfe RefNotFoundError) Error() string {
+	return fmt.Sprintf("'%s' is not participating in session '%s'", rnfe.name, rnfe.session)
+}
+
+// RefFailedError denotes that the session failed to apply the given ref, e.g. as the pods of its clone do not start.
+type RefFailedError struct {
+	name   string
+	reason string
+}
+
+// Error returns the formatted ref failure.
+func (rfe RefFailedError) Error() string {
+	return fmt.Sprintf("session failed for '%s': %s", rfe.name, rfe.reason)
+}
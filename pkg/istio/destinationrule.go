@@ -10,6 +10,7 @@ import (
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -22,6 +23,8 @@ var _ model.Modificator = DestinationRuleModificator
 var _ model.Locator = DestinationRuleLocator
 var _ model.ModificatorRegistrar = GatewayRegistrar
 
+var errSubsetNotFound = errors.Sentinel("failed finding subset with given host and version")
+
 func DestinationRuleRegistrar() (client.Object, model.Modificator) {
 	return &istionetwork.DestinationRule{}, DestinationRuleModificator
 }
@@ -52,7 +55,19 @@ func DestinationRuleLocator(ctx model.SessionContext, ref model.Ref, store model
 		}
 
 		for _, hostName := range model.GetTargetHostNames(store) {
-			dr, err := locateDestinationRuleWithSubset(ctx, ctx.Namespace, hostName, model.GetVersion(store))
+			dr, err := locateDestinationRuleWithSubset(ctx, ctx.Namespace, hostName, model.GetVersion(store, ctx.GetVersionLabel()))
+			if errors.Is(err, errSubsetNotFound) { // service does not follow version subset convention, subset is synthesized
+				report(model.LocatorStatus{
+					Resource: model.Resource{
+						Kind:      DestinationRuleKind,
+						Namespace: ctx.Namespace,
+						Name:      naming.ConcatToMax(63, "dr", ref.KindName.Name, hostName.Name, ctx.Name),
+					},
+					Action: model.ActionCreate,
+					Labels: map[string]string{"host": hostName.String(), LabelIkeSynthesized: LabelIkeSynthesizedValue}})
+
+				continue
+			}
 			if err != nil {
 				errs = errors.Append(errs, err)
 
@@ -104,17 +119,78 @@ func DestinationRuleModificator(ctx model.SessionContext, ref model.Ref, store m
 }
 
 func actionCreateDestinationRule(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
-	dr, err := getDestinationRule(ctx, resource.Namespace, resource.Name)
+	var destinationRule istionetwork.DestinationRule
+	if resource.Labels[LabelIkeSynthesized] == LabelIkeSynthesizedValue {
+		destinationRule = createSynthesizedDestinationRule(ctx, store, resource)
+	} else {
+		dr, err := getDestinationRule(ctx, resource.Namespace, resource.Name)
+		if err != nil {
+			report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+			return
+		}
+		destinationRule = createDestinationRule(ctx, ref, store, dr)
+	}
+
+	if err := reference.Add(ctx.ToNamespacedName(), &destinationRule); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", destinationRule.Kind, "name", destinationRule.Name, "host", destinationRule.Spec.Host)
+	}
+	reference.AddRefMarker(&destinationRule, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+	drifted := false
+	err := ctx.Client.Create(ctx, &destinationRule)
+	if k8sErrors.IsAlreadyExists(err) {
+		drifted, err = reapplyDestinationRule(ctx, &destinationRule)
+	}
 	if err != nil {
-		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+		report(model.ModificatorStatus{
+			LocatorStatus: resource,
+			Success:       false,
+			Error: errors.WrapWithDetails(
+				err, "failed to create DestinationRule", "kind", DestinationRuleKind, "name", destinationRule.Name, "host", destinationRule.Spec.Host)})
 
 		return
 	}
 
-	newVersion := model.GetCreatedVersion(store, ctx.Name)
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true,
+		Prop:          driftedProp(drifted),
+		Target: &model.Resource{
+			Namespace: destinationRule.Namespace,
+			Kind:      DestinationRuleKind,
+			Name:      destinationRule.Name}})
+}
+
+// reapplyDestinationRule restores the spec of the already existing DestinationRule created by the session when it has changed since.
+func reapplyDestinationRule(ctx model.SessionContext, desired *istionetwork.DestinationRule) (bool, error) {
+	drifted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		live, err := getDestinationRule(ctx, desired.Namespace, desired.Name)
+		if err != nil {
+			return err
+		}
+		if drifted = !specsEqual(&live.Spec, &desired.Spec); !drifted {
+			return nil
+		}
+
+		patch := MergeFrom(live.DeepCopy())
+		live.Spec = *desired.Spec.DeepCopy()
+
+		return errors.WrapIfWithDetails(ctx.Client.Patch(ctx, live, patch), "failed restoring destination rule", "kind", DestinationRuleKind, "name", live.Name)
+	})
+
+	return drifted, err
+}
 
-	subset := locateSubset(dr, model.GetVersion(store))
-	destinationRule := istionetwork.DestinationRule{
+// createDestinationRule creates DestinationRule with the subset of the cloned version, inheriting traffic policy of the subset
+// it is cloned from. Istio only takes subsets from additional rules of the same host into account, so the top-level policy
+// of the original rule is folded into the subset. The vendored istio API does not define workloadSelector, it is not carried over.
+func createDestinationRule(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, dr *istionetwork.DestinationRule) istionetwork.DestinationRule {
+	newVersion := model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name)
+	subset := locateSubset(dr, ctx.GetVersionLabel(), model.GetVersion(store, ctx.GetVersionLabel()))
+
+	return istionetwork.DestinationRule{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      naming.ConcatToMax(63, "dr", ref.KindName.Name, dr.Spec.Host, ctx.Name),
 			Namespace: ref.Namespace,
@@ -125,38 +201,41 @@ func actionCreateDestinationRule(ctx model.SessionContext, ref model.Ref, store
 				{
 					Name: newVersion,
 					Labels: map[string]string{
-						"version": newVersion,
+						ctx.GetVersionLabel(): newVersion,
 					},
-					TrafficPolicy: subset.TrafficPolicy,
+					TrafficPolicy: mergeTrafficPolicy(dr.Spec.TrafficPolicy, subset.TrafficPolicy),
 				},
 			},
+			ExportTo: dr.Spec.ExportTo,
 		},
 	}
+}
 
-	if err := reference.Add(ctx.ToNamespacedName(), &destinationRule); err != nil {
-		ctx.Log.Error(err, "failed to add relation reference", "kind", destinationRule.Kind, "name", destinationRule.Name, "host", dr.Spec.Host)
-	}
-	reference.AddRefMarker(&destinationRule, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
-
-	if err := ctx.Client.Create(ctx, &destinationRule); err != nil {
-		if !k8sErrors.IsAlreadyExists(err) {
-			report(model.ModificatorStatus{
-				LocatorStatus: resource,
-				Success:       false,
-				Error: errors.WrapWithDetails(
-					err, "failed to create DestinationRule", "kind", DestinationRuleKind, "name", destinationRule.Name, "host", destinationRule.Spec.Host)})
+// createSynthesizedDestinationRule creates DestinationRule for services without version subsets. The subset selects
+// the cloned pods by the version label they are given when cloned.
+func createSynthesizedDestinationRule(ctx model.SessionContext, store model.LocatorStatusStore, resource model.LocatorStatus) istionetwork.DestinationRule {
+	newVersion := model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name)
 
-			return
-		}
+	return istionetwork.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+			Labels: map[string]string{
+				LabelIkeSynthesized: LabelIkeSynthesizedValue,
+			},
+		},
+		Spec: istionetworkv1alpha3.DestinationRule{
+			Host: resource.Labels["host"],
+			Subsets: []*istionetworkv1alpha3.Subset{
+				{
+					Name: newVersion,
+					Labels: map[string]string{
+						ctx.GetVersionLabel(): newVersion,
+					},
+				},
+			},
+		},
 	}
-
-	report(model.ModificatorStatus{
-		LocatorStatus: resource,
-		Success:       true,
-		Target: &model.Resource{
-			Namespace: destinationRule.Namespace,
-			Kind:      DestinationRuleKind,
-			Name:      destinationRule.Name}})
 }
 
 func actionDeleteDestinationRule(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
@@ -193,19 +272,19 @@ func locateDestinationRuleWithSubset(ctx model.SessionContext, namespace string,
 	for _, dr := range destinationRules.Items { //nolint:gocritic //reason for readability
 		dr := dr
 		if hostName.Match(dr.Spec.Host) {
-			subset := locateSubset(&dr, targetVersion)
+			subset := locateSubset(&dr, ctx.GetVersionLabel(), targetVersion)
 			if subset != nil {
 				return &dr, nil
 			}
 		}
 	}
 
-	return nil, errors.NewWithDetails("failed finding subset with given host and version", "host", hostName.String(), "version", targetVersion, "namespace", namespace)
+	return nil, errors.WithDetails(errSubsetNotFound, "host", hostName.String(), "version", targetVersion, "namespace", namespace)
 }
 
-func locateSubset(dr *istionetwork.DestinationRule, targetVersion string) *istionetworkv1alpha3.Subset {
+func locateSubset(dr *istionetwork.DestinationRule, versionLabel, targetVersion string) *istionetworkv1alpha3.Subset {
 	for _, subset := range dr.Spec.Subsets {
-		if subset.Labels["version"] == targetVersion {
+		if subset.Labels[versionLabel] == targetVersion {
 			return subset
 		}
 	}
@@ -61,7 +61,27 @@ var _ = Describe("Operations for istio DestinationRule kind", func() {
 								MaxRetries: 10,
 							},
 						},
+						Tls: &istionetworkv1alpha3.ClientTLSSettings{
+							Mode: istionetworkv1alpha3.ClientTLSSettings_ISTIO_MUTUAL,
+						},
+						OutlierDetection: &istionetworkv1alpha3.OutlierDetection{
+							MaxEjectionPercent: 50,
+						},
+						PortLevelSettings: []*istionetworkv1alpha3.TrafficPolicy_PortTrafficPolicy{
+							{
+								Port: &istionetworkv1alpha3.PortSelector{Number: 8080},
+								ConnectionPool: &istionetworkv1alpha3.ConnectionPoolSettings{
+									Http: &istionetworkv1alpha3.ConnectionPoolSettings_HTTPSettings{
+										MaxRetries: 1,
+									},
+								},
+								LoadBalancer: &istionetworkv1alpha3.LoadBalancerSettings{
+									LbPolicy: &istionetworkv1alpha3.LoadBalancerSettings_Simple{Simple: istionetworkv1alpha3.LoadBalancerSettings_ROUND_ROBIN},
+								},
+							},
+						},
 					},
+					ExportTo: []string{"."},
 				},
 			},
 			&istionetwork.DestinationRule{
@@ -217,7 +237,7 @@ var _ = Describe("Operations for istio DestinationRule kind", func() {
 				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
 
 				dr := get.DestinationRules(namespace, testclient.HasRefPredicate)
-				Expect(dr.Items[0].Spec.Subsets).To(ContainElement(WithTransform(GetName, Equal(model.GetCreatedVersion(locators.Store, ctx.Name)))))
+				Expect(dr.Items[0].Spec.Subsets).To(ContainElement(WithTransform(GetName, Equal(model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)))))
 			})
 
 			It("should not create new destination rules for subsequents mutations", func() {
@@ -233,7 +253,35 @@ var _ = Describe("Operations for istio DestinationRule kind", func() {
 				dr := get.DestinationRules(namespace, testclient.HasRefPredicate)
 				Expect(dr.Items).To(HaveLen(1))
 				Expect(dr.Items[0].Spec.Subsets).To(HaveLen(1))
-				Expect(dr.Items[0].Spec.Subsets).To(ContainElement(WithTransform(GetName, Equal(model.GetCreatedVersion(locators.Store, ctx.Name)))))
+				Expect(dr.Items[0].Spec.Subsets).To(ContainElement(WithTransform(GetName, Equal(model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)))))
+			})
+
+			It("should not report drift for unchanged rule", func() {
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+
+				Expect(modificators.Stored).To(HaveLen(2))
+				Expect(modificators.Stored[1].Error).ToNot(HaveOccurred())
+				Expect(modificators.Stored[1].Prop).ToNot(HaveKey(istio.PropDrifted))
+			})
+
+			It("should restore rule changed since it was created", func() {
+				// given
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+				created := get.DestinationRules(namespace, testclient.HasRefPredicate).Items[0]
+				created.Spec.Subsets[0].Labels = map[string]string{"version": "changed"}
+				Expect(c.Update(ctx, &created)).To(Succeed())
+
+				// when
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+
+				// then
+				Expect(modificators.Stored).To(HaveLen(2))
+				Expect(modificators.Stored[1].Error).ToNot(HaveOccurred())
+				Expect(modificators.Stored[1].Prop).To(HaveKeyWithValue(istio.PropDrifted, "true"))
+
+				restored := get.DestinationRules(namespace, testclient.HasRefPredicate).Items[0]
+				Expect(restored.Spec.Subsets[0].Labels).To(HaveKeyWithValue("version", model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)))
 			})
 
 			It("should keep traffic policy from target", func() {
@@ -245,25 +293,144 @@ var _ = Describe("Operations for istio DestinationRule kind", func() {
 				Expect(dr.Items[0].Spec.Subsets[0].TrafficPolicy).ToNot(BeNil())
 				Expect(dr.Items[0].Spec.Subsets[0].TrafficPolicy.ConnectionPool.Http.MaxRetries).To(Equal(int32(100)))
 			})
+
+			It("should merge top-level traffic policy of target rule", func() {
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				dr := get.DestinationRules(namespace, testclient.HasRefPredicate)
+				trafficPolicy := dr.Items[0].Spec.Subsets[0].TrafficPolicy
+				Expect(trafficPolicy.Tls.Mode).To(Equal(istionetworkv1alpha3.ClientTLSSettings_ISTIO_MUTUAL))
+				Expect(trafficPolicy.OutlierDetection.MaxEjectionPercent).To(Equal(int32(50)))
+			})
+
+			It("should let subset policy take precedence over port level settings of target rule", func() {
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				dr := get.DestinationRules(namespace, testclient.HasRefPredicate)
+				portLevelSettings := dr.Items[0].Spec.Subsets[0].TrafficPolicy.PortLevelSettings
+				Expect(portLevelSettings).To(HaveLen(1))
+				Expect(portLevelSettings[0].Port.Number).To(Equal(uint32(8080)))
+				Expect(portLevelSettings[0].ConnectionPool).To(BeNil())
+				Expect(portLevelSettings[0].LoadBalancer.GetSimple()).To(Equal(istionetworkv1alpha3.LoadBalancerSettings_ROUND_ROBIN))
+			})
+
+			It("should keep visibility of target rule", func() {
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				dr := get.DestinationRules(namespace, testclient.HasRefPredicate)
+				Expect(dr.Items[0].Spec.ExportTo).To(ConsistOf("."))
+			})
 		})
 
-		Context("missing rule", func() {
+		Context("custom version label", func() {
 
-			// https://github.com/maistra/istio-workspace/issues/856
-			XIt("should fail when no rules found", func() {
+			It("should locate subset and create clone subset using configured label", func() {
 				ref := model.Ref{
-					KindName:  model.ParseRefKindName("customer-v5"),
+					KindName:  model.ParseRefKindName("customer-rev"),
 					Namespace: namespace,
 				}
+				ctx.VersionLabel = "rev"
+				Expect(c.Create(ctx, &istionetwork.DestinationRule{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "customer-rev",
+						Namespace: namespace,
+					},
+					Spec: istionetworkv1alpha3.DestinationRule{
+						Host: "customer-rev",
+						Subsets: []*istionetworkv1alpha3.Subset{
+							{
+								Name:   "r1",
+								Labels: map[string]string{"rev": "r1"},
+							},
+						},
+					},
+				})).To(Succeed())
 				locators := model.LocatorStore{}
-				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: namespace, Name: "customer-v3"}, Labels: map[string]string{"version": "v5"}})
-				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: namespace, Name: "customer-missing"}})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: namespace, Name: "customer-rev"}, Labels: map[string]string{"rev": "r1"}})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: namespace, Name: "customer-rev"}})
 				modificators := model.ModificatorStore{}
 
+				Expect(istio.DestinationRuleLocator(ctx, ref, locators.Store, locators.Report)).To(Succeed())
+				Expect(locators.Store(istio.DestinationRuleKind)).To(HaveLen(1))
+				Expect(locators.Store(istio.DestinationRuleKind)[0].Name).To(Equal("customer-rev"))
+
 				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
 				Expect(modificators.Stored).To(HaveLen(1))
 				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
-				Expect(modificators.Stored[0].Error).To(ContainSubstring("failed finding subset with given host and version"))
+
+				newVersion := model.GetCreatedVersion(locators.Store, "rev", ctx.Name)
+				Expect(newVersion).To(HavePrefix(model.GetSha("r1")))
+				dr := get.DestinationRules(namespace, testclient.HasRefPredicate)
+				Expect(dr.Items).To(HaveLen(1))
+				Expect(dr.Items[0].Spec.Subsets[0].Labels).To(Equal(map[string]string{"rev": newVersion}))
+			})
+		})
+
+		Context("missing rule", func() {
+
+			var (
+				ref          model.Ref
+				locators     model.LocatorStore
+				modificators model.ModificatorStore
+			)
+
+			BeforeEach(func() {
+				ref = model.Ref{
+					KindName:  model.ParseRefKindName("customer-missing"),
+					Namespace: namespace,
+				}
+				locators = model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: namespace, Name: "customer-missing"}, Labels: map[string]string{"app": "customer"}})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: namespace, Name: "customer-missing"}})
+				modificators = model.ModificatorStore{}
+			})
+
+			It("should report synthesized rule when no rules found", func() {
+				err := istio.DestinationRuleLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				actions := locators.Store(istio.DestinationRuleKind)
+				Expect(actions).To(HaveLen(1))
+				Expect(actions[0].Action).To(Equal(model.ActionCreate))
+				Expect(actions[0].Name).To(Equal("dr-customer-missing-customer-missing-test"))
+				Expect(actions[0].Labels).To(HaveKeyWithValue(istio.LabelIkeSynthesized, istio.LabelIkeSynthesizedValue))
+			})
+
+			It("should create rule selecting cloned version", func() {
+				err := istio.DestinationRuleLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				newVersion := model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)
+				dr := get.DestinationRule(namespace, "dr-customer-missing-customer-missing-test")
+				Expect(dr.Spec.Host).To(Equal("customer-missing.test.svc.cluster.local"))
+				Expect(dr.Spec.Subsets).To(HaveLen(1))
+				Expect(dr.Spec.Subsets[0].Name).To(Equal(newVersion))
+				Expect(dr.Spec.Subsets[0].Labels).To(Equal(map[string]string{"version": newVersion}))
+				Expect(dr.Spec.Subsets[0].TrafficPolicy).To(BeNil())
+			})
+
+			It("should delete synthesized rule when reference is removed", func() {
+				err := istio.DestinationRuleLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				istio.DestinationRuleModificator(ctx, ref, locators.Store, modificators.Report)
+
+				ref.Remove = true
+				removeLocators := model.LocatorStore{}
+				err = istio.DestinationRuleLocator(ctx, ref, removeLocators.Store, removeLocators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				istio.DestinationRuleModificator(ctx, ref, removeLocators.Store, modificators.Report)
+
+				Expect(get.DestinationRules(namespace, testclient.HasRefPredicate).Items).To(BeEmpty())
 			})
 		})
 	})
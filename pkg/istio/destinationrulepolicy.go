@@ -0,0 +1,76 @@
+package istio
+
+import (
+	istionetworkv1alpha3 "istio.io/api/networking/v1alpha3"
+)
+
+// mergeTrafficPolicy combines the top-level policy of the original rule with the policy of its subset, following the order
+// istio applies them in: rule, rule port-level settings, subset and subset port-level settings. Settings defined by the subset
+// therefore take precedence over the port-level settings of the rule they are defined for.
+func mergeTrafficPolicy(parent, subset *istionetworkv1alpha3.TrafficPolicy) *istionetworkv1alpha3.TrafficPolicy {
+	if parent == nil {
+		return subset.DeepCopy()
+	}
+
+	merged := parent.DeepCopy()
+	if subset == nil {
+		return merged
+	}
+
+	if subset.LoadBalancer != nil {
+		merged.LoadBalancer = subset.LoadBalancer.DeepCopy()
+		for _, portPolicy := range merged.PortLevelSettings {
+			portPolicy.LoadBalancer = nil
+		}
+	}
+	if subset.ConnectionPool != nil {
+		merged.ConnectionPool = subset.ConnectionPool.DeepCopy()
+		for _, portPolicy := range merged.PortLevelSettings {
+			portPolicy.ConnectionPool = nil
+		}
+	}
+	if subset.OutlierDetection != nil {
+		merged.OutlierDetection = subset.OutlierDetection.DeepCopy()
+		for _, portPolicy := range merged.PortLevelSettings {
+			portPolicy.OutlierDetection = nil
+		}
+	}
+	if subset.Tls != nil {
+		merged.Tls = subset.Tls.DeepCopy()
+		for _, portPolicy := range merged.PortLevelSettings {
+			portPolicy.Tls = nil
+		}
+	}
+
+	for _, subsetPortPolicy := range subset.PortLevelSettings {
+		merged.PortLevelSettings = mergePortTrafficPolicy(merged.PortLevelSettings, subsetPortPolicy)
+	}
+
+	return merged
+}
+
+// mergePortTrafficPolicy overrides the settings defined for the same port, or adds the port policy if there are none.
+func mergePortTrafficPolicy(portPolicies []*istionetworkv1alpha3.TrafficPolicy_PortTrafficPolicy,
+	override *istionetworkv1alpha3.TrafficPolicy_PortTrafficPolicy) []*istionetworkv1alpha3.TrafficPolicy_PortTrafficPolicy {
+	for _, portPolicy := range portPolicies {
+		if portPolicy.Port.GetNumber() != override.Port.GetNumber() {
+			continue
+		}
+		if override.LoadBalancer != nil {
+			portPolicy.LoadBalancer = override.LoadBalancer.DeepCopy()
+		}
+		if override.ConnectionPool != nil {
+			portPolicy.ConnectionPool = override.ConnectionPool.DeepCopy()
+		}
+		if override.OutlierDetection != nil {
+			portPolicy.OutlierDetection = override.OutlierDetection.DeepCopy()
+		}
+		if override.Tls != nil {
+			portPolicy.Tls = override.Tls.DeepCopy()
+		}
+
+		return portPolicies
+	}
+
+	return append(portPolicies, override.DeepCopy())
+}
@@ -0,0 +1,35 @@
+package istio
+
+import (
+	"encoding/json"
+)
+
+// PropDrifted is the ModificatorStatus property set when the resource no longer carried the changes of the session
+// applied before and they had to be restored.
+const PropDrifted = "drifted"
+
+func driftedProp(drifted bool) map[string]string {
+	if !drifted {
+		return nil
+	}
+
+	return map[string]string{PropDrifted: "true"}
+}
+
+// specsEqual compares istio specs through their JSON representation, as the generated types carry internal fields
+// which are not relevant for the comparison.
+func specsEqual(spec, other json.Marshaler) bool {
+	marshaled := specJSON(spec)
+
+	return marshaled != "" && marshaled == specJSON(other)
+}
+
+// specJSON returns the JSON representation of the istio spec, or an empty string when it can not be marshaled.
+func specJSON(spec json.Marshaler) string {
+	marshaled, err := spec.MarshalJSON()
+	if err != nil {
+		return ""
+	}
+
+	return string(marshaled)
+}
@@ -0,0 +1,352 @@
+package istio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EnvoyFilterKind is the k8s Kind for a istio EnvoyFilter.
+	EnvoyFilterKind = "EnvoyFilter"
+
+	// TraceStateKey is the key of the tracestate entry carrying the session route value.
+	TraceStateKey = "ike"
+
+	luaFilterType = "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua"
+)
+
+var (
+	_ model.Locator              = EnvoyFilterLocator
+	_ model.ModificatorRegistrar = EnvoyFilterRegistrar
+
+	// ErrPropagationNotSupported is reported when the session asks for propagation of a route which is not header based.
+	ErrPropagationNotSupported = errors.Sentinel("route propagation is only supported for header based routes")
+)
+
+func EnvoyFilterRegistrar() (client.Object, model.Modificator) {
+	return &istionetwork.EnvoyFilter{}, EnvoyFilterModificator
+}
+
+// EnvoyFilterName returns the name of the EnvoyFilter propagating the route of the given session.
+func EnvoyFilterName(sessionName string) string {
+	return sessionName + "-route-propagation"
+}
+
+// EnvoyFilterLocator reports the EnvoyFilter propagating the session route when the Session opted-in for it.
+// The filter is shared by all refs of the session, each of them keeps its own ref marker on it.
+func EnvoyFilterLocator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+	labelKey := reference.CreateRefMarker(ctx.Name, ref.KindName.String())
+	filters, err := getEnvoyFilters(ctx, ctx.Namespace, reference.RefMarkerMatch(labelKey))
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to get all envoy filters", "ref", ref.KindName.String())
+	}
+
+	// unsupported route types are reported by the RoutePropagation validation
+	propagate := !ref.Remove && ctx.Route.Propagate && ctx.Route.Type == model.RouteTypeHeader
+	for i := range filters.Items {
+		filter := filters.Items[i]
+		action, hash := reference.GetRefMarker(&filter, labelKey)
+		if !propagate || ref.Hash() != hash {
+			report(model.LocatorStatus{
+				Resource: model.Resource{
+					Kind:      EnvoyFilterKind,
+					Namespace: filter.Namespace,
+					Name:      filter.Name,
+				},
+				Action: model.Flip(model.StatusAction(action))})
+		}
+		if propagate && ref.Hash() == hash {
+			// the route of the session might have changed since the filter has been created
+			upToDate, err := propagatesRoute(&filter, ctx.Route)
+			if err != nil {
+				return err
+			}
+			if upToDate {
+				return nil
+			}
+		}
+	}
+
+	if !propagate {
+		return nil
+	}
+
+	report(model.LocatorStatus{
+		Resource: model.Resource{
+			Kind:      EnvoyFilterKind,
+			Namespace: ctx.Namespace,
+			Name:      EnvoyFilterName(ctx.Name),
+		},
+		Action: model.ActionCreate})
+
+	return nil
+}
+
+// EnvoyFilterModificator installs EnvoyFilter which carries the route header across services not forwarding it.
+func EnvoyFilterModificator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	for _, resource := range store(EnvoyFilterKind) {
+		switch resource.Action {
+		case model.ActionCreate:
+			actionCreateEnvoyFilter(ctx, ref, report, resource)
+		case model.ActionDelete:
+			actionDeleteEnvoyFilter(ctx, ref, report, resource)
+		case model.ActionModify, model.ActionRevert, model.ActionLocated:
+			report(model.ModificatorStatus{
+				LocatorStatus: resource,
+				Success:       false,
+				Error:         errors.Errorf("Unknown action type for modificator: %v", resource.Action)})
+		}
+	}
+}
+
+func actionCreateEnvoyFilter(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	desired, err := createPropagationFilter(ctx.Route, resource.Namespace, resource.Name)
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	// the filter is shared by all refs of the session, which are synced concurrently
+	err = retry.OnError(retry.DefaultRetry, IsConflict, func() error {
+		return applyEnvoyFilter(ctx, ref, resource, desired.DeepCopy())
+	})
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true})
+}
+
+// applyEnvoyFilter creates the filter, or brings the existing one to the desired spec, marking it as relied on by the ref.
+func applyEnvoyFilter(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus, desired *istionetwork.EnvoyFilter) error {
+	filter, err := getEnvoyFilter(ctx, resource.Namespace, resource.Name)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	var patch client.Patch
+	if exists {
+		patch = MergeFrom(filter.DeepCopy())
+		filter.Spec = desired.Spec
+	} else {
+		filter = desired
+	}
+
+	if err = reference.Add(ctx.ToNamespacedName(), filter); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", EnvoyFilterKind, "name", filter.Name)
+	}
+	reference.AddRefMarker(filter, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+	if exists {
+		err = ctx.Client.Patch(ctx, filter, patch)
+	} else {
+		err = ctx.Client.Create(ctx, filter)
+	}
+
+	return errors.WrapIfWithDetails(err, "failed to create EnvoyFilter", "kind", EnvoyFilterKind, "name", filter.Name)
+}
+
+func actionDeleteEnvoyFilter(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return removeEnvoyFilter(ctx, ref, resource)
+	})
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	// ok, removed
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true})
+}
+
+// removeEnvoyFilter removes the ref marker from the filter, deleting it once no other ref of the session relies on it.
+func removeEnvoyFilter(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus) error {
+	filter, err := getEnvoyFilter(ctx, resource.Namespace, resource.Name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) { // Not found, nothing to clean
+			return nil
+		}
+
+		return err
+	}
+
+	patch := MergeFrom(filter.DeepCopy())
+	reference.RemoveRefMarker(filter, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
+
+	// other refs of the session still rely on the filter
+	if reference.HasRefMarkers(filter) {
+		err = ctx.Client.Patch(ctx, filter, patch)
+	} else {
+		err = ctx.Client.Delete(ctx, filter, client.Preconditions{ResourceVersion: &filter.ResourceVersion})
+	}
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WrapWithDetails(err, "failed to delete EnvoyFilter", "kind", EnvoyFilterKind, "name", filter.Name)
+	}
+
+	return nil
+}
+
+// createPropagationFilter creates EnvoyFilter applied to all sidecars in the namespace. Inbound requests carrying the route
+// header get the route recorded in W3C baggage and tracestate headers, which are forwarded by tracing aware services.
+// Outbound requests missing the route header get it restored from either of them.
+func createPropagationFilter(route model.Route, namespace, name string) (*istionetwork.EnvoyFilter, error) {
+	spec := map[string]interface{}{
+		"configPatches": []interface{}{
+			LuaFilterPatch("SIDECAR_INBOUND", inboundPropagationScript(route)),
+			LuaFilterPatch("SIDECAR_OUTBOUND", outboundPropagationScript(route)),
+		},
+	}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed marshaling envoy filter spec")
+	}
+
+	filter := &istionetwork.EnvoyFilter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if err = filter.Spec.UnmarshalJSON(raw); err != nil {
+		return nil, errors.Wrap(err, "failed creating envoy filter spec")
+	}
+
+	return filter, nil
+}
+
+// propagatesRoute tells if the filter propagates the given route, as it is not the case when the route of the session
+// has changed after the filter had been created.
+func propagatesRoute(filter *istionetwork.EnvoyFilter, route model.Route) (bool, error) {
+	desired, err := createPropagationFilter(route, filter.Namespace, filter.Name)
+	if err != nil {
+		return false, err
+	}
+	actualSpec, err := filter.Spec.MarshalJSON()
+	if err != nil {
+		return false, errors.Wrap(err, "failed marshaling envoy filter spec")
+	}
+	desiredSpec, err := desired.Spec.MarshalJSON()
+	if err != nil {
+		return false, errors.Wrap(err, "failed marshaling envoy filter spec")
+	}
+
+	return bytes.Equal(actualSpec, desiredSpec), nil
+}
+
+// LuaFilterPatch returns EnvoyFilter config patch inserting Lua filter running the given script in front of the router
+// of the sidecars, either for inbound or outbound requests depending on the context.
+func LuaFilterPatch(context, script string) map[string]interface{} {
+	return map[string]interface{}{
+		"applyTo": "HTTP_FILTER",
+		"match": map[string]interface{}{
+			"context": context,
+			"listener": map[string]interface{}{
+				"filterChain": map[string]interface{}{
+					"filter": map[string]interface{}{
+						"name": "envoy.filters.network.http_connection_manager",
+						"subFilter": map[string]interface{}{
+							"name": "envoy.filters.http.router",
+						},
+					},
+				},
+			},
+		},
+		"patch": map[string]interface{}{
+			"operation": "INSERT_BEFORE",
+			"value": map[string]interface{}{
+				"name": "envoy.lua",
+				"typed_config": map[string]interface{}{
+					"@type":      luaFilterType,
+					"inlineCode": script,
+				},
+			},
+		},
+	}
+}
+
+const luaHasEntry = `local function has_entry(header, key, value)
+  if header == nil then
+    return false
+  end
+  for entry in string.gmatch(header, "[^,]+") do
+    local k, v = string.match(entry, "^%s*([^=%s]+)%s*=%s*([^;%s]+)")
+    if k == key and v == value then
+      return true
+    end
+  end
+  return false
+end
+`
+
+func inboundPropagationScript(route model.Route) string {
+	return luaHasEntry + fmt.Sprintf(`
+local function prepend(header, entry)
+  if header == nil or header == "" then
+    return entry
+  end
+  return entry .. "," .. header
+end
+
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  if headers:get(%[1]q) ~= %[2]q then
+    return
+  end
+  if not has_entry(headers:get("baggage"), %[1]q, %[2]q) then
+    headers:replace("baggage", prepend(headers:get("baggage"), %[1]q .. "=" .. %[2]q))
+  end
+  if headers:get("traceparent") ~= nil and not has_entry(headers:get("tracestate"), %[3]q, %[2]q) then
+    headers:replace("tracestate", prepend(headers:get("tracestate"), %[3]q .. "=" .. %[2]q))
+  end
+end
+`, strings.ToLower(route.Name), route.Value, TraceStateKey)
+}
+
+func outboundPropagationScript(route model.Route) string {
+	return luaHasEntry + fmt.Sprintf(`
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  if headers:get(%[1]q) ~= nil then
+    return
+  end
+  if has_entry(headers:get("baggage"), %[1]q, %[2]q) or has_entry(headers:get("tracestate"), %[3]q, %[2]q) then
+    headers:add(%[1]q, %[2]q)
+  end
+end
+`, strings.ToLower(route.Name), route.Value, TraceStateKey)
+}
+
+func getEnvoyFilter(ctx model.SessionContext, namespace, name string) (*istionetwork.EnvoyFilter, error) {
+	filter := istionetwork.EnvoyFilter{}
+	err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &filter)
+
+	return &filter, errors.WrapWithDetails(err, "failed finding envoyfilter in namespace", "name", name, "namespace", namespace)
+}
+
+func getEnvoyFilters(ctx model.SessionContext, namespace string, opts ...client.ListOption) (*istionetwork.EnvoyFilterList, error) {
+	filters := istionetwork.EnvoyFilterList{}
+	err := ctx.Client.List(ctx, &filters, append(opts, client.InNamespace(namespace))...)
+
+	return &filters, errors.WrapWithDetails(err, "failed finding envoy filters in namespace", "namespace", namespace)
+}
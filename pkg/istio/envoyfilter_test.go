@@ -0,0 +1,212 @@
+package istio_test
+
+import (
+	"github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/log"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/test/testclient"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Operations for istio EnvoyFilter kind", func() {
+
+	const namespace = "test"
+
+	var (
+		c   client.Client
+		ctx model.SessionContext
+		get *testclient.Getters
+	)
+
+	locate := func(ref model.Ref) *model.LocatorStore {
+		locators := &model.LocatorStore{}
+		Expect(istio.EnvoyFilterLocator(ctx, ref, locators.Store, locators.Report)).To(Succeed())
+
+		return locators
+	}
+
+	modify := func(ref model.Ref, locators *model.LocatorStore) model.ModificatorStore {
+		modificators := model.ModificatorStore{}
+		istio.EnvoyFilterModificator(ctx, ref, locators.Store, modificators.Report)
+		for _, stored := range modificators.Stored {
+			Expect(stored.Error).ToNot(HaveOccurred())
+		}
+
+		return modificators
+	}
+
+	filterExists := func() bool {
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: istio.EnvoyFilterName(ctx.Name)}, &istionetwork.EnvoyFilter{})
+		if k8sErrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).ToNot(HaveOccurred())
+
+		return true
+	}
+
+	BeforeEach(func() {
+		schema, _ := v1alpha1.SchemeBuilder.Register(
+			&istionetwork.EnvoyFilter{},
+			&istionetwork.EnvoyFilterList{}).Build()
+
+		c = fake.NewClientBuilder().WithScheme(schema).Build()
+		get = testclient.New(c)
+		ctx = model.SessionContext{
+			Name:      "feature-x",
+			Namespace: namespace,
+			Route:     model.Route{Type: "header", Name: "X-Workspace-Route", Value: "feature-x", Propagate: true},
+			Client:    c,
+			Log:       log.CreateOperatorAwareLogger("envoyfilter"),
+		}
+	})
+
+	customer := model.Ref{KindName: model.ParseRefKindName("customer-v1"), Namespace: namespace}
+	details := model.Ref{KindName: model.ParseRefKindName("details-v1"), Namespace: namespace}
+
+	Context("locators", func() {
+
+		It("should not locate anything when session did not opt-in", func() {
+			ctx.Route.Propagate = false
+
+			Expect(locate(customer).Store(istio.EnvoyFilterKind)).To(BeEmpty())
+		})
+
+		It("should trigger create action when reference is created", func() {
+			actions := locate(customer).Store(istio.EnvoyFilterKind)
+
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Action).To(Equal(model.ActionCreate))
+			Expect(actions[0].Name).To(Equal("feature-x-route-propagation"))
+		})
+
+		It("should not trigger any action when filter is already in place", func() {
+			modify(customer, locate(customer))
+
+			Expect(locate(customer).Store(istio.EnvoyFilterKind)).To(BeEmpty())
+		})
+
+		It("should trigger delete action when session opted-out", func() {
+			modify(customer, locate(customer))
+
+			ctx.Route.Propagate = false
+			actions := locate(customer).Store(istio.EnvoyFilterKind)
+
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Action).To(Equal(model.ActionDelete))
+		})
+
+		It("should not propagate routes other than header", func() {
+			ctx.Route.Type = model.RouteTypeBaggage
+
+			Expect(locate(customer).Store(istio.EnvoyFilterKind)).To(BeEmpty())
+		})
+
+		It("should trigger create action when session route has changed", func() {
+			modify(customer, locate(customer))
+
+			ctx.Route.Value = "feature-y"
+			actions := locate(customer).Store(istio.EnvoyFilterKind)
+
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Action).To(Equal(model.ActionCreate))
+		})
+	})
+
+	Context("modificators", func() {
+
+		It("should create filter restoring route header from baggage and tracestate", func() {
+			modify(customer, locate(customer))
+
+			filter := get.EnvoyFilter(namespace, istio.EnvoyFilterName(ctx.Name))
+			Expect(filter.Spec.ConfigPatches).To(HaveLen(2))
+
+			inbound := filter.Spec.ConfigPatches[0].Patch.Value.Fields["typed_config"].GetStructValue().Fields["inlineCode"].GetStringValue()
+			Expect(inbound).To(ContainSubstring(`headers:replace("baggage", prepend(headers:get("baggage"), "x-workspace-route" .. "=" .. "feature-x"))`))
+			Expect(inbound).To(ContainSubstring(`headers:replace("tracestate", prepend(headers:get("tracestate"), "ike" .. "=" .. "feature-x"))`))
+
+			outbound := filter.Spec.ConfigPatches[1].Patch.Value.Fields["typed_config"].GetStructValue().Fields["inlineCode"].GetStringValue()
+			Expect(outbound).To(ContainSubstring(`headers:add("x-workspace-route", "feature-x")`))
+		})
+
+		It("should propagate changed session route", func() {
+			modify(customer, locate(customer))
+
+			ctx.Route.Value = "feature-y"
+			modify(customer, locate(customer))
+
+			filter := get.EnvoyFilter(namespace, istio.EnvoyFilterName(ctx.Name))
+			outbound := filter.Spec.ConfigPatches[1].Patch.Value.Fields["typed_config"].GetStructValue().Fields["inlineCode"].GetStringValue()
+			Expect(outbound).To(ContainSubstring(`headers:add("x-workspace-route", "feature-y")`))
+			Expect(outbound).ToNot(ContainSubstring(`"feature-x"`))
+		})
+
+		It("should share filter between refs of the session", func() {
+			modify(customer, locate(customer))
+			modify(details, locate(details))
+
+			ctx.Route.Propagate = false
+			modify(customer, locate(customer))
+			Expect(filterExists()).To(BeTrue())
+
+			modify(details, locate(details))
+			Expect(filterExists()).To(BeFalse())
+		})
+
+		It("should remove filter when last ref is removed", func() {
+			modify(customer, locate(customer))
+
+			removed := customer
+			removed.Remove = true
+			modify(removed, locate(removed))
+
+			Expect(filterExists()).To(BeFalse())
+		})
+
+		It("should mark filter created concurrently by other ref of the session", func() {
+			// given
+			other := ctx
+			sessionClient := &testclient.InterferingClient{Client: c, Interfere: func() {
+				istio.EnvoyFilterModificator(other, details, locate(details).Store, (&model.ModificatorStore{}).Report)
+			}}
+			ctx.Client = sessionClient
+
+			// when
+			modify(customer, locate(customer))
+
+			// then
+			Expect(sessionClient.Writes).To(Equal(2))
+
+			ctx.Client = c
+			ctx.Route.Propagate = false
+			modify(details, locate(details))
+			Expect(filterExists()).To(BeTrue())
+		})
+
+		It("should keep filter when other ref of the session is marked concurrently", func() {
+			// given
+			modify(customer, locate(customer))
+			removed := customer
+			removed.Remove = true
+			other := ctx
+			sessionClient := &testclient.InterferingClient{Client: c, Interfere: func() {
+				istio.EnvoyFilterModificator(other, details, locate(details).Store, (&model.ModificatorStore{}).Report)
+			}}
+			ctx.Client = sessionClient
+
+			// when
+			modify(removed, locate(removed))
+
+			// then
+			Expect(sessionClient.Writes).To(Equal(2))
+			Expect(filterExists()).To(BeTrue())
+		})
+	})
+})
@@ -1,14 +1,20 @@
 package istio
 
 import (
+	"strconv"
 	"strings"
 
 	"emperror.dev/errors"
 	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/naming"
 	"github.com/maistra/istio-workspace/pkg/reference"
+	"istio.io/api/networking/v1alpha3"
 	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -32,7 +38,11 @@ func GatewayModificator(ctx model.SessionContext, ref model.Ref, store model.Loc
 			actionModifyGateway(ctx, ref, report, resource)
 		case model.ActionRevert:
 			actionRevertGateway(ctx, ref, report, resource)
-		case model.ActionCreate, model.ActionDelete, model.ActionLocated:
+		case model.ActionCreate:
+			actionCreateGateway(ctx, ref, report, resource)
+		case model.ActionDelete:
+			actionDeleteGateway(ctx, report, resource)
+		case model.ActionLocated:
 			report(model.ModificatorStatus{
 				LocatorStatus: resource,
 				Success:       false,
@@ -42,6 +52,50 @@ func GatewayModificator(ctx model.SessionContext, ref model.Ref, store model.Loc
 }
 
 func actionModifyGateway(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	var mutatedGw istionetwork.Gateway
+	var addedHosts []string
+	drifted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		gw, err := getGateway(ctx, resource.Namespace, resource.Name)
+		if err != nil {
+			return err
+		}
+
+		ctx.Log.Info("Found Gateway", "name", resource.Name, "namespace", resource.Namespace)
+		patch := MergeFrom(gw.DeepCopy())
+		_, hash := reference.GetRefMarker(gw, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
+		mutatedGw, addedHosts = mutateGateway(ctx, *gw.DeepCopy())
+		drifted = hash == ref.Hash() && !specsEqual(&gw.Spec, &mutatedGw.Spec)
+
+		if err = reference.Add(ctx.ToNamespacedName(), &mutatedGw); err != nil {
+			ctx.Log.Error(err, "failed to add relation reference", "kind", mutatedGw.Kind, "name", mutatedGw.Name)
+		}
+		reference.AddRefMarker(&mutatedGw, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+		return errors.WrapIfWithDetails(ctx.Client.Patch(ctx, &mutatedGw, patch), "failed updating gateway", "kind", GatewayKind, "name", mutatedGw.Name)
+	})
+	if err != nil {
+		report(model.ModificatorStatus{
+			LocatorStatus: resource,
+			Success:       false,
+			Error:         err})
+
+		return
+	}
+
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true,
+		Prop: map[string]string{
+			"hosts":          strings.Join(addedHosts, ","),
+			"uncoveredHosts": strings.Join(findHostsNotCoveredByCertificate(ctx, &mutatedGw), ","),
+			PropDrifted:      strconv.FormatBool(drifted),
+		},
+	})
+}
+
+// actionCreateGateway exposes the session hosts through a Gateway of its own, leaving the located one untouched.
+func actionCreateGateway(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
 	gw, err := getGateway(ctx, resource.Namespace, resource.Name)
 	if err != nil {
 		report(model.ModificatorStatus{
@@ -52,21 +106,29 @@ func actionModifyGateway(ctx model.SessionContext, ref model.Ref, report model.M
 		return
 	}
 
-	ctx.Log.Info("Found Gateway", "name", resource.Name, "namespace", resource.Namespace)
-	patch := client.MergeFrom(gw.DeepCopy())
-	mutatedGw, addedHosts := mutateGateway(ctx, *gw)
+	sessionGw, addedHosts := createSessionGateway(ctx, *gw)
+	if len(sessionGw.Spec.Servers) == 0 { // catch-all hosts only, the session relies on the route match of the located Gateway
+		report(model.ModificatorStatus{
+			LocatorStatus: resource,
+			Success:       true})
 
-	if err = reference.Add(ctx.ToNamespacedName(), &mutatedGw); err != nil {
-		ctx.Log.Error(err, "failed to add relation reference", "kind", mutatedGw.Kind, "name", mutatedGw.Name)
+		return
 	}
-	reference.AddRefMarker(&mutatedGw, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+	if err = reference.Add(ctx.ToNamespacedName(), &sessionGw); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", sessionGw.Kind, "name", sessionGw.Name)
+	}
+	reference.AddRefMarker(&sessionGw, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
 
-	err = ctx.Client.Patch(ctx, &mutatedGw, patch)
+	drifted := false
+	err = ctx.Client.Create(ctx, &sessionGw)
+	if k8sErrors.IsAlreadyExists(err) {
+		drifted, err = reapplyGateway(ctx, &sessionGw)
+	}
 	if err != nil {
 		report(model.ModificatorStatus{
 			LocatorStatus: resource,
 			Success:       false,
-			Error:         errors.WrapIfWithDetails(err, "failed updating gateway", "kind", GatewayKind, "name", mutatedGw.Name)})
+			Error:         errors.WrapIfWithDetails(err, "failed creating gateway", "kind", GatewayKind, "name", sessionGw.Name)})
 
 		return
 	}
@@ -75,43 +137,125 @@ func actionModifyGateway(ctx model.SessionContext, ref model.Ref, report model.M
 		LocatorStatus: resource,
 		Success:       true,
 		Prop: map[string]string{
-			"hosts": strings.Join(addedHosts, ","),
+			"hosts":          strings.Join(addedHosts, ","),
+			"uncoveredHosts": strings.Join(findHostsNotCoveredByCertificate(ctx, &sessionGw), ","),
+			PropDrifted:      strconv.FormatBool(drifted),
 		},
-	})
+		Target: &model.Resource{
+			Namespace: sessionGw.Namespace,
+			Kind:      GatewayKind,
+			Name:      sessionGw.Name}})
 }
 
-func actionRevertGateway(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
-	gw, err := getGateway(ctx, resource.Namespace, resource.Name)
-	if err != nil {
-		if k8sErrors.IsNotFound(err) { // Not found, nothing to clean
-			report(model.ModificatorStatus{
-				LocatorStatus: resource,
-				Success:       true})
-
-			return
-		}
+func actionDeleteGateway(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	gw := istionetwork.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+		},
+	}
+	if err := ctx.Client.Delete(ctx, &gw); err != nil && !k8sErrors.IsNotFound(err) {
 		report(model.ModificatorStatus{
 			LocatorStatus: resource,
 			Success:       false,
-			Error:         err})
+			Error:         errors.WrapWithDetails(err, "failed deleting gateway", "kind", GatewayKind, "name", gw.Name)})
 
 		return
 	}
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true})
+}
+
+// reapplyGateway restores the spec of the already existing Gateway created by the session when it has changed since.
+func reapplyGateway(ctx model.SessionContext, desired *istionetwork.Gateway) (bool, error) {
+	drifted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		live, err := getGateway(ctx, desired.Namespace, desired.Name)
+		if err != nil {
+			return err
+		}
+		if drifted = !specsEqual(&live.Spec, &desired.Spec); !drifted {
+			return nil
+		}
 
-	ctx.Log.Info("Found Gateway", "name", resource.Name, "namespace", resource.Namespace)
-	patch := client.MergeFrom(gw.DeepCopy())
-	mutatedGw := revertGateway(ctx, *gw)
-	if err = reference.Remove(ctx.ToNamespacedName(), &mutatedGw); err != nil {
-		ctx.Log.Error(err, "failed to remove relation reference", "kind", mutatedGw.Kind, "name", mutatedGw.Name)
+		patch := MergeFrom(live.DeepCopy())
+		live.Spec = *desired.Spec.DeepCopy()
+
+		return errors.WrapIfWithDetails(ctx.Client.Patch(ctx, live, patch), "failed restoring gateway", "kind", GatewayKind, "name", live.Name)
+	})
+
+	return drifted, err
+}
+
+// SessionGatewayName returns the name of the Gateway exposing the session hosts of the given one.
+func SessionGatewayName(gatewayName, session string) string {
+	return naming.ConcatToMax(63, gatewayName, session)
+}
+
+// createSessionGateway creates Gateway in the session namespace serving the session hosts on the same workload and ports
+// as the source one. Servers without hosts the session can be exposed at are left out.
+func createSessionGateway(ctx model.SessionContext, source istionetwork.Gateway) (istionetwork.Gateway, []string) {
+	addedHosts := []string{}
+	servers := []*v1alpha3.Server{}
+	sessionHosts := extractExistingHosts(&source)
+	for _, sourceServer := range source.Spec.Servers {
+		server := sourceServer.DeepCopy()
+		server.Hosts = []string{}
+		for _, host := range sourceServer.Hosts {
+			if isInSlice(sessionHosts, host) {
+				continue
+			}
+			namespace, _ := splitNamespacedHost(host)
+			if _, exposedHost, exposed := sessionHost(ctx.Name, host); exposed && !isInSlice(server.Hosts, namespace+exposedHost) {
+				server.Hosts = append(server.Hosts, namespace+exposedHost)
+				if !isInSlice(addedHosts, exposedHost) {
+					addedHosts = append(addedHosts, exposedHost)
+				}
+			}
+		}
+		if len(server.Hosts) > 0 {
+			servers = append(servers, server)
+		}
 	}
-	reference.RemoveRefMarker(&mutatedGw, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
 
-	err = ctx.Client.Patch(ctx, &mutatedGw, patch)
-	if err != nil {
+	return istionetwork.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SessionGatewayName(source.Name, ctx.Name),
+			Namespace: ctx.Namespace,
+			Labels: map[string]string{
+				LabelIkeMutated: LabelIkeMutatedValue,
+			},
+		},
+		Spec: v1alpha3.Gateway{
+			Selector: source.Spec.Selector,
+			Servers:  servers,
+		},
+	}, addedHosts
+}
+
+func actionRevertGateway(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		gw, err := getGateway(ctx, resource.Namespace, resource.Name)
+		if err != nil {
+			return err
+		}
+
+		ctx.Log.Info("Found Gateway", "name", resource.Name, "namespace", resource.Namespace)
+		patch := MergeFrom(gw.DeepCopy())
+		mutatedGw := revertGateway(ctx, *gw)
+		if err = reference.Remove(ctx.ToNamespacedName(), &mutatedGw); err != nil {
+			ctx.Log.Error(err, "failed to remove relation reference", "kind", mutatedGw.Kind, "name", mutatedGw.Name)
+		}
+		reference.RemoveRefMarker(&mutatedGw, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
+
+		return errors.WrapIfWithDetails(ctx.Client.Patch(ctx, &mutatedGw, patch), "failed updating gateway", "kind", GatewayKind, "name", mutatedGw.Name)
+	})
+	if err != nil && !k8sErrors.IsNotFound(err) { // Not found, nothing to clean
 		report(model.ModificatorStatus{
 			LocatorStatus: resource,
 			Success:       false,
-			Error:         errors.WrapIfWithDetails(err, "failed updating gateway", "kind", GatewayKind, "name", mutatedGw.Name)})
+			Error:         err})
 
 		return
 	}
@@ -133,18 +277,23 @@ func mutateGateway(ctx model.SessionContext, source istionetwork.Gateway) (mutat
 	for _, server := range source.Spec.Servers {
 		hosts := server.Hosts
 		for _, host := range hosts {
-			newHost := ctx.Name + "." + host
-			if !isInSlice(existingHosts, host) && !isInSlice(existingHosts, newHost) {
-				existingHosts = append(existingHosts, newHost)
-				hosts = append(hosts, newHost)
+			if isInSlice(existingHosts, host) {
+				continue
 			}
-			if isInSlice(existingHosts, newHost) {
-				addedHosts = append(addedHosts, newHost)
+			gatewayHost, exposedHost, exposed := sessionHost(ctx.Name, host)
+			if !exposed {
+				continue
+			}
+			if gatewayHost != host && !isInSlice(existingHosts, gatewayHost) {
+				existingHosts = append(existingHosts, gatewayHost)
+				hosts = append(hosts, gatewayHost)
+			}
+			if !isInSlice(addedHosts, exposedHost) {
+				addedHosts = append(addedHosts, exposedHost)
 			}
 		}
 		for _, existing := range existingHosts {
-			baseHost := strings.Join(strings.Split(existing, ".")[1:], ".")
-			if !isInSlice(hosts, existing) && isInSlice(hosts, baseHost) {
+			if !isInSlice(hosts, existing) && isInSlice(hosts, baseHost(existing)) {
 				hosts = append(hosts, existing)
 			}
 		}
@@ -168,7 +317,8 @@ func revertGateway(ctx model.SessionContext, source istionetwork.Gateway) istion
 		hosts := server.Hosts
 		for i := 0; i < len(hosts); i++ {
 			host := hosts[i]
-			if isInSlice(existingHosts, host) && strings.HasPrefix(host, ctx.Name+".") {
+			_, dnsName := splitNamespacedHost(host)
+			if isInSlice(existingHosts, host) && strings.HasPrefix(dnsName, ctx.Name+".") {
 				toBeRemovedHosts = append(toBeRemovedHosts, host)
 				hosts = append(hosts[:i], hosts[i+1:]...)
 				i--
@@ -188,6 +338,114 @@ func revertGateway(ctx model.SessionContext, source istionetwork.Gateway) istion
 	return source
 }
 
+// sessionHost determines how the session is exposed through the given Gateway server host. The gatewayHost is the entry
+// which has to be present on the server, exposedHost is the plain DNS name the session is reachable at.
+// Wildcard hosts (e.g. *.example.com) already cover the session host, so they are reused as they are. A catch-all host (*)
+// leaves no room for a dedicated session host, in which case exposed is false.
+// Hosts can be scoped to namespace (e.g. bookinfo/example.com), the scope is kept for the gatewayHost.
+func sessionHost(session, host string) (gatewayHost, exposedHost string, exposed bool) {
+	namespace, dnsName := splitNamespacedHost(host)
+	switch {
+	case dnsName == "*":
+		return "", "", false
+	case strings.HasPrefix(dnsName, "*."):
+		return host, session + strings.TrimPrefix(dnsName, "*"), true
+	default:
+		exposedHost = session + "." + dnsName
+
+		return namespace + exposedHost, exposedHost, true
+	}
+}
+
+// splitNamespacedHost splits the host defined as <namespace>/<dnsName> keeping the trailing slash in the namespace part,
+// so it can be directly prepended again. Namespace is empty for hosts which are not scoped.
+func splitNamespacedHost(host string) (namespace, dnsName string) {
+	if i := strings.Index(host, "/"); i >= 0 {
+		return host[:i+1], host[i+1:]
+	}
+
+	return "", host
+}
+
+// baseHost returns the Gateway host the given session host has been derived from.
+func baseHost(host string) string {
+	namespace, dnsName := splitNamespacedHost(host)
+
+	return namespace + strings.Join(strings.Split(dnsName, ".")[1:], ".")
+}
+
+// ExposedHost is the host the session is reachable at through the Gateway, along with the ingress Service serving it.
+type ExposedHost struct {
+	Name             string
+	TLS              bool
+	ServiceName      string
+	ServiceNamespace string
+}
+
+// GetExposedHosts returns the hosts the session is exposed at through the Gateways located for the ref. The ingress Service
+// is the one labeled as the gateway workload, Gateways without such Service are skipped.
+func GetExposedHosts(ctx model.SessionContext, store model.LocatorStatusStore) ([]ExposedHost, error) {
+	exposedHosts := []ExposedHost{}
+	found := func(name string) bool {
+		for _, exposedHost := range exposedHosts {
+			if exposedHost.Name == name {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, located := range store(GatewayKind) {
+		if located.Action != model.ActionModify && located.Action != model.ActionCreate {
+			continue
+		}
+		gw, err := getGateway(ctx, located.Namespace, located.Name)
+		if err != nil {
+			return nil, err
+		}
+		services, err := getIngressServices(ctx, gw)
+		if err != nil {
+			return nil, err
+		}
+		if len(services) == 0 {
+			ctx.Log.Info("no ingress service found for gateway", "name", gw.Name, "namespace", gw.Namespace)
+
+			continue
+		}
+		service := services[0]
+
+		sessionHosts := extractExistingHosts(gw)
+		for _, server := range gw.Spec.Servers {
+			for _, host := range server.Hosts {
+				if isInSlice(sessionHosts, host) {
+					continue
+				}
+				if _, exposedHost, exposed := sessionHost(ctx.Name, host); exposed && !found(exposedHost) {
+					exposedHosts = append(exposedHosts, ExposedHost{
+						Name:             exposedHost,
+						TLS:              server.Tls != nil && !server.Tls.HttpsRedirect,
+						ServiceName:      service.Name,
+						ServiceNamespace: service.Namespace,
+					})
+				}
+			}
+		}
+	}
+
+	return exposedHosts, nil
+}
+
+func getIngressServices(ctx model.SessionContext, gw *istionetwork.Gateway) ([]corev1.Service, error) {
+	if len(gw.Spec.Selector) == 0 {
+		return []corev1.Service{}, nil
+	}
+	services := corev1.ServiceList{}
+	err := ctx.Client.List(ctx, &services, client.MatchingLabels(gw.Spec.Selector))
+
+	return services.Items, errors.WrapWithDetails(err, "failed finding ingress services", "gateway", gw.Name, "namespace", gw.Namespace)
+}
+
 func getGateway(ctx model.SessionContext, namespace, name string) (*istionetwork.Gateway, error) {
 	Gateway := istionetwork.Gateway{}
 	err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &Gateway)
@@ -1,6 +1,16 @@
 package istio_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"time"
+
 	"github.com/maistra/istio-workspace/api/maistra/v1alpha1"
 	"github.com/maistra/istio-workspace/pkg/istio"
 	"github.com/maistra/istio-workspace/pkg/log"
@@ -11,6 +21,7 @@ import (
 	. "github.com/onsi/gomega"
 	"istio.io/api/networking/v1alpha3"
 	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +44,7 @@ var _ = Describe("Operations for istio gateway kind", func() {
 		schema, _ := v1alpha1.SchemeBuilder.Register(
 			&istionetwork.Gateway{},
 			&istionetwork.GatewayList{}).Build()
+		Expect(corev1.AddToScheme(schema)).To(Succeed())
 
 		c = fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(objects...).Build()
 		get = testclient.New(c)
@@ -354,5 +366,294 @@ var _ = Describe("Operations for istio gateway kind", func() {
 				Expect(gw.Labels).ToNot(HaveKey(istio.LabelIkeHosts))
 			})
 		})
+
+		Context("host forms", func() {
+
+			BeforeEach(func() {
+				objects = []runtime.Object{
+					&istionetwork.Gateway{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "gateway",
+							Namespace: "test",
+						},
+						Spec: v1alpha3.Gateway{
+							Selector: map[string]string{
+								"istio": "ingressgateway",
+							},
+							Servers: []*v1alpha3.Server{
+								{
+									Port: &v1alpha3.Port{
+										Protocol: "HTTP",
+										Name:     "http",
+										Number:   80,
+									},
+									Hosts: []string{
+										"*.wildcard.com",
+										"bookinfo/domain.com",
+										"*",
+									},
+								},
+							},
+						},
+					},
+				}
+				ref = model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators = model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Gateway", Namespace: "test", Name: "gateway"}, Action: model.ActionModify})
+				modificators = model.ModificatorStore{}
+			})
+
+			It("should reuse wildcard and keep namespace of scoped hosts", func() {
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				gw := get.Gateway("test", "gateway")
+				Expect(gw.Spec.Servers[0].Hosts).To(ConsistOf("*.wildcard.com", "bookinfo/domain.com", "*", "bookinfo/test.domain.com"))
+			})
+
+			It("should report plain exposed hosts", func() {
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				Expect(strings.Split(modificators.Stored[0].Prop["hosts"], ",")).To(ConsistOf("test.wildcard.com", "test.domain.com"))
+			})
+
+			It("should remove namespace scoped host on revert", func() {
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+
+				locators.Clear()
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Gateway", Namespace: "test", Name: "gateway"}, Action: model.ActionRevert})
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(2))
+				Expect(modificators.Stored[1].Error).ToNot(HaveOccurred())
+
+				gw := get.Gateway("test", "gateway")
+				Expect(gw.Spec.Servers[0].Hosts).To(ConsistOf("*.wildcard.com", "bookinfo/domain.com", "*"))
+				Expect(gw.Annotations).ToNot(HaveKey(istio.LabelIkeHosts))
+			})
+		})
+
+		Context("gitops", func() {
+
+			BeforeEach(func() {
+				objects = []runtime.Object{
+					&istionetwork.Gateway{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "gateway",
+							Namespace: "istio-system",
+						},
+						Spec: v1alpha3.Gateway{
+							Selector: map[string]string{
+								"istio": "ingressgateway",
+							},
+							Servers: []*v1alpha3.Server{
+								{
+									Port: &v1alpha3.Port{
+										Protocol: "HTTP",
+										Name:     "http",
+										Number:   80,
+									},
+									Hosts: []string{
+										"*.wildcard.com",
+										"bookinfo/domain.com",
+										"*",
+									},
+								},
+								{
+									Port: &v1alpha3.Port{
+										Protocol: "HTTP",
+										Name:     "http-alt",
+										Number:   8080,
+									},
+									Hosts: []string{"*"},
+								},
+							},
+						},
+					},
+				}
+				ref = model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators = model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Gateway", Namespace: "istio-system", Name: "gateway"}, Action: model.ActionCreate})
+				modificators = model.ModificatorStore{}
+			})
+
+			JustBeforeEach(func() {
+				ctx.GitOps = true
+			})
+
+			It("should leave the located gateway untouched", func() {
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				gw := get.Gateway("istio-system", "gateway")
+				Expect(gw.Spec.Servers[0].Hosts).To(ConsistOf("*.wildcard.com", "bookinfo/domain.com", "*"))
+				Expect(gw.Annotations).ToNot(HaveKey(istio.LabelIkeHosts))
+			})
+
+			It("should create session gateway serving the session hosts in the session namespace", func() {
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+				Expect(strings.Split(modificators.Stored[0].Prop["hosts"], ",")).To(ConsistOf("test.wildcard.com", "test.domain.com"))
+				Expect(modificators.Stored[0].Target).To(Equal(&model.Resource{Kind: istio.GatewayKind, Namespace: "test", Name: "gateway-test"}))
+
+				gw := get.Gateway("test", "gateway-test")
+				Expect(gw.Spec.Selector).To(HaveKeyWithValue("istio", "ingressgateway"))
+				Expect(gw.Spec.Servers).To(HaveLen(1))
+				Expect(gw.Spec.Servers[0].Port.Number).To(BeEquivalentTo(80))
+				Expect(gw.Spec.Servers[0].Hosts).To(ConsistOf("test.wildcard.com", "bookinfo/test.domain.com"))
+				Expect(gw.Labels).To(HaveKeyWithValue(istio.LabelIkeMutated, istio.LabelIkeMutatedValue))
+				Expect(reference.Get(&gw)).To(HaveLen(1))
+			})
+
+			It("should delete session gateway", func() {
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+
+				locators.Clear()
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Gateway", Namespace: "test", Name: "gateway-test"}, Action: model.ActionDelete})
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(2))
+				Expect(modificators.Stored[1].Error).ToNot(HaveOccurred())
+
+				gws := istionetwork.GatewayList{}
+				Expect(c.List(ctx, &gws, client.InNamespace("test"))).To(Succeed())
+				Expect(gws.Items).To(BeEmpty())
+			})
+		})
+
+		Context("tls", func() {
+
+			tlsGateway := func(hosts ...string) *istionetwork.Gateway {
+				return &istionetwork.Gateway{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "gateway",
+						Namespace: "test",
+					},
+					Spec: v1alpha3.Gateway{
+						Selector: map[string]string{
+							"istio": "ingressgateway",
+						},
+						Servers: []*v1alpha3.Server{
+							{
+								Port: &v1alpha3.Port{
+									Protocol: "HTTPS",
+									Name:     "https",
+									Number:   443,
+								},
+								Hosts: hosts,
+								Tls: &v1alpha3.ServerTLSSettings{
+									Mode:           v1alpha3.ServerTLSSettings_SIMPLE,
+									CredentialName: "domain-cert",
+								},
+							},
+						},
+					},
+				}
+			}
+
+			certificateSecret := func(namespace string, dnsNames ...string) *corev1.Secret {
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "domain-cert",
+						Namespace: namespace,
+					},
+					Type: corev1.SecretTypeTLS,
+					Data: map[string][]byte{
+						corev1.TLSCertKey: selfSignedCertificate(dnsNames...),
+					},
+				}
+			}
+
+			ingressPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "istio-ingressgateway",
+					Namespace: "istio-system",
+					Labels:    map[string]string{"istio": "ingressgateway"},
+				},
+			}
+
+			modify := func() model.ModificatorStatus {
+				ref = model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators = model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Gateway", Namespace: "test", Name: "gateway"}, Action: model.ActionModify})
+				modificators = model.ModificatorStore{}
+
+				istio.GatewayModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				return modificators.Stored[0]
+			}
+
+			When("certificate does not cover session host", func() {
+
+				BeforeEach(func() {
+					objects = []runtime.Object{tlsGateway("domain.com"), ingressPod, certificateSecret("istio-system", "domain.com")}
+				})
+
+				It("should report uncovered host", func() {
+					Expect(modify().Prop["uncoveredHosts"]).To(Equal("test.domain.com"))
+				})
+			})
+
+			When("wildcard certificate covers session host", func() {
+
+				BeforeEach(func() {
+					objects = []runtime.Object{tlsGateway("domain.com"), ingressPod, certificateSecret("istio-system", "domain.com", "*.domain.com")}
+				})
+
+				It("should not report uncovered host", func() {
+					Expect(modify().Prop["uncoveredHosts"]).To(BeEmpty())
+				})
+			})
+
+			When("certificate is in the gateway namespace", func() {
+
+				BeforeEach(func() {
+					objects = []runtime.Object{tlsGateway("domain.com"), certificateSecret("test", "domain.com")}
+				})
+
+				It("should report uncovered host", func() {
+					Expect(modify().Prop["uncoveredHosts"]).To(Equal("test.domain.com"))
+				})
+			})
+
+			When("certificate can not be found", func() {
+
+				BeforeEach(func() {
+					objects = []runtime.Object{tlsGateway("domain.com")}
+				})
+
+				It("should not report uncovered host", func() {
+					Expect(modify().Prop["uncoveredHosts"]).To(BeEmpty())
+				})
+			})
+		})
 	})
 })
+
+func selfSignedCertificate(dnsNames ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,114 @@
+package istio
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"istio.io/api/networking/v1alpha3"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certificateKeys are the Secret keys Istio reads the server certificate from, for kubernetes.io/tls and generic Secrets respectively.
+var certificateKeys = []string{corev1.TLSCertKey, "cert"}
+
+// findHostsNotCoveredByCertificate returns the session hosts exposed through TLS servers of the Gateway
+// which are not covered by the certificate referred to by the server's credentialName.
+// Certificates which cannot be found or parsed are not verified.
+func findHostsNotCoveredByCertificate(ctx model.SessionContext, gw *istionetwork.Gateway) []string {
+	uncovered := []string{}
+	sessionHosts := extractExistingHosts(gw)
+	certificates := map[string]*x509.Certificate{}
+
+	for _, server := range gw.Spec.Servers {
+		if !terminatesTLS(server) {
+			continue
+		}
+
+		certificate, loaded := certificates[server.Tls.CredentialName]
+		if !loaded {
+			var err error
+			certificate, err = loadCertificate(ctx, gw, server.Tls.CredentialName)
+			if err != nil {
+				ctx.Log.Info("unable to verify session hosts against gateway certificate",
+					"gateway", gw.Name, "credentialName", server.Tls.CredentialName, "reason", err.Error())
+			}
+			certificates[server.Tls.CredentialName] = certificate
+		}
+		if certificate == nil {
+			continue
+		}
+
+		for _, host := range server.Hosts {
+			if isInSlice(sessionHosts, host) {
+				continue
+			}
+			_, exposedHost, exposed := sessionHost(ctx.Name, host)
+			if exposed && certificate.VerifyHostname(exposedHost) != nil && !isInSlice(uncovered, exposedHost) {
+				uncovered = append(uncovered, exposedHost)
+			}
+		}
+	}
+
+	return uncovered
+}
+
+func terminatesTLS(server *v1alpha3.Server) bool {
+	return server.Tls != nil &&
+		server.Tls.CredentialName != "" &&
+		server.Tls.Mode != v1alpha3.ServerTLSSettings_PASSTHROUGH &&
+		server.Tls.Mode != v1alpha3.ServerTLSSettings_AUTO_PASSTHROUGH
+}
+
+// loadCertificate reads the server certificate from the Secret named credentialName. Istio looks the Secret up in the
+// namespace of the gateway workload, so namespaces of Pods matching the Gateway selector are checked first, followed by
+// the namespace of the Gateway itself.
+func loadCertificate(ctx model.SessionContext, gw *istionetwork.Gateway, credentialName string) (*x509.Certificate, error) {
+	namespaces := []string{}
+	if len(gw.Spec.Selector) > 0 {
+		pods := corev1.PodList{}
+		if err := ctx.Client.List(ctx, &pods, client.MatchingLabels(gw.Spec.Selector)); err == nil {
+			for i := range pods.Items {
+				if !isInSlice(namespaces, pods.Items[i].Namespace) {
+					namespaces = append(namespaces, pods.Items[i].Namespace)
+				}
+			}
+		}
+	}
+	if !isInSlice(namespaces, gw.Namespace) {
+		namespaces = append(namespaces, gw.Namespace)
+	}
+
+	for _, namespace := range namespaces {
+		secret := corev1.Secret{}
+		if err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: credentialName}, &secret); err != nil {
+			continue
+		}
+
+		return parseCertificate(secret)
+	}
+
+	return nil, errors.NewWithDetails("certificate secret not found", "name", credentialName, "namespaces", namespaces)
+}
+
+func parseCertificate(secret corev1.Secret) (*x509.Certificate, error) {
+	for _, key := range certificateKeys {
+		data, found := secret.Data[key]
+		if !found {
+			continue
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.NewWithDetails("certificate is not PEM encoded", "name", secret.Name, "key", key)
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+
+		return certificate, errors.WrapIfWithDetails(err, "failed parsing certificate", "name", secret.Name, "key", key)
+	}
+
+	return nil, errors.NewWithDetails("secret does not contain certificate", "name", secret.Name)
+}
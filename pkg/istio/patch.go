@@ -0,0 +1,19 @@
+package istio
+
+import (
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MergeFrom creates merge patch which fails with conflict when the object has been changed since it was read. Resources
+// shared with other sessions and controllers are patched with it, so their concurrent changes are picked up when
+// the patch is retried instead of being overwritten.
+func MergeFrom(obj client.Object) client.Patch {
+	return client.MergeFromWithOptions(obj, client.MergeFromWithOptimisticLock{})
+}
+
+// IsConflict tells if the resource has been changed, or created, since it was read. Refs of the session are synced
+// concurrently, so the change is retried against the latest version of the resource they share.
+func IsConflict(err error) bool {
+	return k8sErrors.IsConflict(err) || k8sErrors.IsAlreadyExists(err)
+}
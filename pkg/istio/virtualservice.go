@@ -2,16 +2,20 @@ package istio
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"emperror.dev/errors"
 	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/naming"
 	"github.com/maistra/istio-workspace/pkg/reference"
 	"istio.io/api/networking/v1alpha3"
 	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -24,6 +28,18 @@ const (
 
 	// LabelIkeMutatedValue is the bool value of the LabelIkeMutated label.
 	LabelIkeMutatedValue = "true"
+
+	// LabelIkeSynthesized is a bool label to indicate the resource was created for a service not following version subset convention.
+	LabelIkeSynthesized = "ike.synthesized"
+
+	// LabelIkeSynthesizedValue is the bool value of the LabelIkeSynthesized label.
+	LabelIkeSynthesizedValue = "true"
+
+	// BaggageHeader is the name of W3C baggage header used by baggage based routes.
+	BaggageHeader = "baggage"
+
+	// ResponseVersionHeader is the name of the response header carrying the version which answered the session route.
+	ResponseVersionHeader = "x-workspace-version"
 )
 
 var (
@@ -38,7 +54,11 @@ func VirtualServiceRegistrar() (client.Object, model.Modificator) {
 
 func VirtualServiceLocator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
 	labelKey := reference.CreateRefMarker(ctx.Name, ref.KindName.String())
-	vss, err := getVirtualServices(ctx, ctx.Namespace, reference.RefMarkerMatch(labelKey))
+	virtualServices, err := getVirtualServicesInNamespaces(ctx, getVirtualServiceNamespaces(ctx))
+	if err != nil {
+		return err
+	}
+	vss, err := getMarkedVirtualServices(ctx, virtualServices, labelKey)
 	if err != nil {
 		return errors.WrapIfWithDetails(err, "failed to get all virtual services", "ref", ref.KindName.String())
 	}
@@ -48,7 +68,8 @@ func VirtualServiceLocator(ctx model.SessionContext, ref model.Ref, store model.
 			vs := vss.Items[i]
 			action, hash := reference.GetRefMarker(&vs, labelKey)
 			undo := model.Flip(model.StatusAction(action))
-			if ref.Hash() != hash {
+			aliasDisabled := !ctx.Route.Alias && vs.Labels[model.LabelIkeAlias] == "true"
+			if ref.Hash() != hash || aliasDisabled {
 				report(model.LocatorStatus{
 					Resource: model.Resource{
 						Kind:      VirtualServiceKind,
@@ -59,15 +80,20 @@ func VirtualServiceLocator(ctx model.SessionContext, ref model.Ref, store model.
 			}
 		}
 
-		virtualServices, err := getVirtualServices(ctx, ctx.Namespace)
+		delegated, err := getDelegatedVirtualServices(ctx, virtualServices)
 		if err != nil {
-			return err
+			return errors.WrapIfWithDetails(err, "failed to get delegated virtual services", "ref", ref.KindName.String())
 		}
-		targetVersion := model.GetVersion(store)
+		virtualServices.Items = append(virtualServices.Items, delegated...)
+		targetVersion := model.GetVersion(store, ctx.GetVersionLabel())
 
 		for _, hostName := range model.GetTargetHostNames(store) {
 			reportVsToBeCreated(virtualServices, hostName, report)
-			reportVsToBeModified(virtualServices, hostName, targetVersion, report)
+			reportBaseVsToBeCreated(ctx, virtualServices, hostName, report)
+			reportVsToBeModified(ctx, virtualServices, hostName, targetVersion, report)
+			if ctx.Route.Alias {
+				reportAliasVsToBeCreated(ctx, hostName, report)
+			}
 		}
 	} else {
 		for i := range vss.Items {
@@ -92,7 +118,8 @@ func reportVsToBeCreated(vss *istionetwork.VirtualServiceList, hostName model.Ho
 		vs := vss.Items[i]
 		_, connected := connectedToGateway(vs)
 
-		if !connected || vs.Labels[LabelIkeMutated] == LabelIkeMutatedValue {
+		// delegate roots are left alone, the session route is added to the delegated VirtualServices instead
+		if !connected || vs.Labels[LabelIkeMutated] == LabelIkeMutatedValue || len(getDelegates(vs)) > 0 || !refersToHost(vs, hostName) {
 			continue
 		}
 
@@ -107,10 +134,52 @@ func reportVsToBeCreated(vss *istionetwork.VirtualServiceList, hostName model.Ho
 	}
 }
 
-func reportVsToBeModified(vss *istionetwork.VirtualServiceList, hostName model.HostName, targetVersion string, report model.LocatorStatusReporter) {
+// reportBaseVsToBeCreated reports VirtualService to be synthesized for the host when there is none routing the mesh traffic to it,
+// so the session route has a place to live in.
+func reportBaseVsToBeCreated(ctx model.SessionContext, vss *istionetwork.VirtualServiceList, hostName model.HostName, report model.LocatorStatusReporter) {
+	for i := range vss.Items {
+		vs := vss.Items[i]
+		if vs.Labels[LabelIkeMutated] != LabelIkeMutatedValue && routesMeshTraffic(vs) && refersToHost(vs, hostName) && routesToHost(vs, hostName) {
+			return
+		}
+	}
+
+	report(model.LocatorStatus{
+		Resource: model.Resource{
+			Kind:      VirtualServiceKind,
+			Namespace: ctx.Namespace,
+			Name:      BaseVirtualServiceName(hostName),
+		},
+		Action: model.ActionCreate,
+		Labels: map[string]string{"host": hostName.String(), LabelIkeSynthesized: LabelIkeSynthesizedValue}})
+}
+
+// BaseVirtualServiceName returns the name of the VirtualService synthesized for the host without one.
+func BaseVirtualServiceName(hostName model.HostName) string {
+	return naming.ConcatToMax(63, hostName.Name, "ike-base")
+}
+
+func reportAliasVsToBeCreated(ctx model.SessionContext, hostName model.HostName, report model.LocatorStatusReporter) {
+	alias := hostName.Alias(ctx.Name)
+	report(model.LocatorStatus{
+		Resource: model.Resource{
+			Kind:      VirtualServiceKind,
+			Namespace: ctx.Namespace,
+			Name:      alias.Name,
+		},
+		Action: model.ActionCreate,
+		Labels: map[string]string{"host": hostName.String(), model.LabelIkeAlias: "true"}})
+}
+
+// reportVsToBeModified reports VirtualServices routing to the target to get the session route. In GitOps mode only
+// the VirtualServices synthesized by the operator are changed, the ones owned by the teams are left untouched.
+func reportVsToBeModified(ctx model.SessionContext, vss *istionetwork.VirtualServiceList, hostName model.HostName, targetVersion string, report model.LocatorStatusReporter) {
 	for i := range vss.Items {
 		vs := vss.Items[i]
-		if !mutationRequired(vs, hostName, targetVersion) {
+		if !refersToHost(vs, hostName) || !mutationRequired(vs, hostName, targetVersion) {
+			continue
+		}
+		if ctx.GitOps && vs.Labels[LabelIkeSynthesized] != LabelIkeSynthesizedValue {
 			continue
 		}
 
@@ -147,27 +216,105 @@ func VirtualServiceModificator(ctx model.SessionContext, ref model.Ref, store mo
 }
 
 func actionCreateVirtualService(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
-	vs, err := getVirtualService(ctx, resource.Namespace, resource.Name)
-	if err != nil {
+	hostName := model.NewHostName(resource.Labels["host"])
+
+	var mutatedVs istionetwork.VirtualService
+	if resource.Labels[LabelIkeSynthesized] == LabelIkeSynthesizedValue {
+		actionCreateBaseVirtualService(ctx, ref, store, report, resource)
+
+		return
+	} else if resource.Labels[model.LabelIkeAlias] == "true" {
+		mutatedVs = createAliasVirtualService(ctx, store, hostName)
+	} else {
+		vs, err := getVirtualService(ctx, resource.Namespace, resource.Name)
+		if err != nil {
+			report(model.ModificatorStatus{
+				LocatorStatus: resource,
+				Success:       false,
+				Error:         err})
+
+			return
+		}
+		mutatedVs = mutateConnectedVirtualService(ctx, store, hostName, *vs)
+	}
+
+	if err := reference.Add(ctx.ToNamespacedName(), &mutatedVs); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", mutatedVs.Kind, "name", mutatedVs.Name)
+	}
+	reference.AddRefMarker(&mutatedVs, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+	drifted := false
+	err := ctx.Client.Create(ctx, &mutatedVs)
+	if k8sErrors.IsAlreadyExists(err) && resource.Labels[model.LabelIkeAlias] == "true" { // alias is owned by the session as a whole
+		drifted, err = reapplyVirtualService(ctx, &mutatedVs)
+	}
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
 		report(model.ModificatorStatus{
 			LocatorStatus: resource,
 			Success:       false,
-			Error:         err})
+			Error:         errors.WrapIfWithDetails(err, "failed creating virtual service", "kind", VirtualServiceKind, "name", mutatedVs.Name, "host", hostName.String())})
 
 		return
 	}
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true,
+		Prop:          driftedProp(drifted),
+		Target: &model.Resource{
+			Namespace: mutatedVs.Namespace,
+			Kind:      VirtualServiceKind,
+			Name:      mutatedVs.Name}})
+}
 
+// reapplyVirtualService restores the spec of the already existing VirtualService created by the session when it has changed since.
+func reapplyVirtualService(ctx model.SessionContext, desired *istionetwork.VirtualService) (bool, error) {
+	drifted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		live, err := getVirtualService(ctx, desired.Namespace, desired.Name)
+		if err != nil {
+			return err
+		}
+		if drifted = !specsEqual(&live.Spec, &desired.Spec); !drifted {
+			return nil
+		}
+
+		patch := MergeFrom(live.DeepCopy())
+		live.Spec = *desired.Spec.DeepCopy()
+
+		return errors.WrapIfWithDetails(ctx.Client.Patch(ctx, live, patch), "failed restoring virtual service", "kind", VirtualServiceKind, "name", live.Name)
+	})
+
+	return drifted, err
+}
+
+// actionCreateBaseVirtualService creates the synthesized VirtualService with the session route already in place. It is shared
+// by all sessions targeting the host, so it is marked as modified and removed only once the last of them is reverted.
+func actionCreateBaseVirtualService(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
 	hostName := model.NewHostName(resource.Labels["host"])
 
-	mutatedVs := mutateConnectedVirtualService(ctx, store, hostName, *vs)
+	mutatedVs, err := mutateVirtualService(ctx, store, hostName, createBaseVirtualService(resource))
+	if err != nil {
+		report(model.ModificatorStatus{
+			LocatorStatus: resource,
+			Success:       false,
+			Error:         errors.WrapIfWithDetails(err, "failed mutating virtual service", "kind", VirtualServiceKind, "name", resource.Name, "host", hostName.String())})
+
+		return
+	}
 
 	if err = reference.Add(ctx.ToNamespacedName(), &mutatedVs); err != nil {
 		ctx.Log.Error(err, "failed to add relation reference", "kind", mutatedVs.Kind, "name", mutatedVs.Name)
 	}
-	reference.AddRefMarker(&mutatedVs, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+	reference.AddRefMarker(&mutatedVs, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(model.ActionModify), ref.Hash())
 
 	err = ctx.Client.Create(ctx, &mutatedVs)
-	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+	if k8sErrors.IsAlreadyExists(err) { // synthesized by other session in the meantime
+		resource.Action = model.ActionModify
+		actionModifyVirtualService(ctx, ref, store, report, resource)
+
+		return
+	}
+	if err != nil {
 		report(model.ModificatorStatus{
 			LocatorStatus: resource,
 			Success:       false,
@@ -206,7 +353,12 @@ func actionDeleteVirtualService(ctx model.SessionContext, report model.Modificat
 }
 
 func actionModifyVirtualService(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
-	vs, err := getVirtualService(ctx, resource.Namespace, resource.Name)
+	drifted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		drifted, err = patchVirtualServiceModification(ctx, ref, store, resource)
+
+		return err
+	})
 	if err != nil {
 		report(model.ModificatorStatus{
 			LocatorStatus: resource,
@@ -215,70 +367,88 @@ func actionModifyVirtualService(ctx model.SessionContext, ref model.Ref, store m
 
 		return
 	}
+	report(model.ModificatorStatus{LocatorStatus: resource, Success: true, Prop: driftedProp(drifted)})
+}
 
-	hostName := model.NewHostName(resource.Labels["host"])
-	if vsAlreadyMutated(*vs, hostName, model.GetCreatedVersion(store, ctx.Name)) {
-		report(model.ModificatorStatus{LocatorStatus: resource, Success: true})
-
-		return
+// patchVirtualServiceModification adds the session route to the live VirtualService. It is shared with other sessions and
+// controllers, so the route is computed again from the changed VirtualService when the patch conflicts.
+func patchVirtualServiceModification(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, resource model.LocatorStatus) (bool, error) {
+	vs, err := getVirtualService(ctx, resource.Namespace, resource.Name)
+	if err != nil {
+		return false, err
 	}
-	patch := client.MergeFrom(vs.DeepCopy())
-	mutatedVs, err := mutateVirtualService(ctx, store, hostName, *vs)
+
+	hostName := model.NewHostName(resource.Labels["host"])
+	newVersion := model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name)
+	labelKey := reference.CreateRefMarker(ctx.Name, ref.KindName.String())
+	_, hash := reference.GetRefMarker(vs, labelKey)
+	applied := hash == ref.Hash()
+	if !applied && vsAlreadyMutated(*vs, hostName, newVersion) {
+		return false, nil
+	}
+	patch := MergeFrom(vs.DeepCopy())
+	// the session routes are computed from scratch, so the ones changed since they were applied are restored
+	mutatedVs, err := mutateVirtualService(ctx, store, hostName, revertVirtualService(newVersion, *vs.DeepCopy()))
 	if err != nil {
-		report(model.ModificatorStatus{
-			LocatorStatus: resource,
-			Success:       false,
-			Error:         errors.WrapIfWithDetails(err, "failed mutating virtual service", "kind", VirtualServiceKind, "name", resource.Name, "host", hostName.String())})
+		return false, errors.WrapIfWithDetails(err, "failed mutating virtual service", "kind", VirtualServiceKind, "name", resource.Name, "host", hostName.String())
+	}
+	drifted := applied && !routesEqual(sessionRoutes(*vs, newVersion), sessionRoutes(mutatedVs, newVersion))
+	if applied && !drifted {
+		return false, nil
 	}
 
 	if err = reference.Add(ctx.ToNamespacedName(), &mutatedVs); err != nil {
 		ctx.Log.Error(err, "failed to add relation reference", "kind", mutatedVs.Kind, "name", mutatedVs.Name)
 	}
-	reference.AddRefMarker(&mutatedVs, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+	reference.AddRefMarker(&mutatedVs, labelKey, string(resource.Action), ref.Hash())
 
-	err = ctx.Client.Patch(ctx, &mutatedVs, patch)
+	return drifted, errors.WrapIfWithDetails(ctx.Client.Patch(ctx, &mutatedVs, patch),
+		"failed updating virtual service", "kind", VirtualServiceKind, "name", mutatedVs.Name, "host", hostName.String())
+}
+
+func actionRevertVirtualService(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return patchVirtualServiceRevert(ctx, ref, store, resource)
+	})
 	if err != nil {
-		report(model.ModificatorStatus{
-			LocatorStatus: resource,
-			Success:       false,
-			Error:         errors.WrapIfWithDetails(err, "failed updating virtual service", "kind", VirtualServiceKind, "name", mutatedVs.Name, "host", hostName.String())})
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
 
 		return
 	}
 	report(model.ModificatorStatus{LocatorStatus: resource, Success: true})
 }
 
-func actionRevertVirtualService(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+// patchVirtualServiceRevert removes the session route from the live VirtualService, keeping the changes made by others.
+func patchVirtualServiceRevert(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, resource model.LocatorStatus) error {
 	vs, err := getVirtualService(ctx, resource.Namespace, resource.Name)
 	if err != nil {
-		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
-
-		return
+		return err
 	}
 
-	patch := client.MergeFrom(vs.DeepCopy())
-	mutatedVs := revertVirtualService(model.GetDeletedVersion(store), *vs)
+	patch := MergeFrom(vs.DeepCopy())
+	mutatedVs := revertVirtualService(model.GetDeletedVersion(store, ctx.GetVersionLabel()), *vs)
 	if err = reference.Remove(ctx.ToNamespacedName(), &mutatedVs); err != nil {
 		ctx.Log.Error(err, "failed to add relation reference", "kind", mutatedVs.Kind, "name", mutatedVs.Name)
 	}
 	reference.RemoveRefMarker(&mutatedVs, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
 
-	err = ctx.Client.Patch(ctx, &mutatedVs, patch)
-	if err != nil {
-		report(model.ModificatorStatus{
-			LocatorStatus: resource,
-			Success:       false,
-			Error:         errors.WrapWithDetails(err, "failed updating VirtualService", "kind", VirtualServiceKind, "name", vs.Name)})
-
-		return
+	// synthesized VirtualService is not needed once no session relies on it
+	if mutatedVs.Labels[LabelIkeSynthesized] == LabelIkeSynthesizedValue && !reference.HasRefMarkers(&mutatedVs) {
+		err = ctx.Client.Delete(ctx, &mutatedVs, client.Preconditions{ResourceVersion: &vs.ResourceVersion})
+	} else {
+		err = ctx.Client.Patch(ctx, &mutatedVs, patch)
 	}
-	report(model.ModificatorStatus{LocatorStatus: resource, Success: true})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WrapWithDetails(err, "failed updating VirtualService", "kind", VirtualServiceKind, "name", vs.Name)
+	}
+
+	return nil
 }
 
 func mutateVirtualService(ctx model.SessionContext, store model.LocatorStatusStore,
 	hostName model.HostName, source istionetwork.VirtualService) (istionetwork.VirtualService, error) {
-	version := model.GetVersion(store)
-	newVersion := model.GetCreatedVersion(store, ctx.Name)
+	version := model.GetVersion(store, ctx.GetVersionLabel())
+	newVersion := model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name)
 	target := source.DeepCopy()
 	clonedSource := source.DeepCopy()
 
@@ -295,22 +465,37 @@ func mutateVirtualService(ctx model.SessionContext, store model.LocatorStatusSto
 
 func mutateConnectedVirtualService(ctx model.SessionContext, store model.LocatorStatusStore,
 	hostName model.HostName, source istionetwork.VirtualService) istionetwork.VirtualService {
-	version := model.GetVersion(store)
-	newVersion := model.GetCreatedVersion(store, ctx.Name)
+	version := model.GetVersion(store, ctx.GetVersionLabel())
+	newVersion := model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name)
 	target := source.DeepCopy()
 	clonedSource := source.DeepCopy()
 	gateways, _ := connectedToGateway(*target)
 	hosts := getHostsFromGateway(ctx, store, gateways)
 
 	target.SetName(target.Name + "-" + ctx.Name)
-	target.Spec.Hosts = hosts
+	if len(hosts) > 0 {
+		target.Spec.Hosts = hosts
+	}
 	target.ResourceVersion = ""
 	if target.Labels == nil {
 		target.Labels = map[string]string{}
 	}
 	target.Labels[LabelIkeMutated] = LabelIkeMutatedValue
+	if ctx.GitOps {
+		target.Spec.Gateways = getSessionGateways(ctx, store, target.Namespace, gateways)
+	}
 
 	targetsHTTP := findRoutes(clonedSource, hostName, version)
+	if len(hosts) == 0 {
+		// catch-all Gateway hosts leave no dedicated session host, the clone shares the original hosts and only routes
+		// the requests carrying the session route, leaving all the other traffic to the original VirtualService
+		target.Spec.Http = nil
+		for _, tHTTP := range targetsHTTP {
+			simplifyTargetRoute(ctx, *tHTTP, hostName, version, newVersion, target)
+		}
+
+		return *target
+	}
 	for _, tHTTP := range targetsHTTP {
 		simplifyTargetRouteWithoutMatch(*tHTTP, hostName, version, newVersion, target)
 	}
@@ -322,9 +507,73 @@ func mutateConnectedVirtualService(ctx model.SessionContext, store model.Locator
 	return *target
 }
 
+// createAliasVirtualService routes the in-mesh alias host of the target directly to the session version. The route is added
+// to the forwarded request, so the calls further down the chain stay within the session.
+func createAliasVirtualService(ctx model.SessionContext, store model.LocatorStatusStore, hostName model.HostName) istionetwork.VirtualService {
+	newVersion := model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name)
+	alias := hostName.Alias(ctx.Name)
+
+	http := v1alpha3.HTTPRoute{
+		Route: []*v1alpha3.HTTPRouteDestination{
+			{
+				Destination: &v1alpha3.Destination{
+					Host:   hostName.String(),
+					Subset: newVersion,
+				},
+			},
+		},
+	}
+	http = addHeaderRequest(http, ctx.Route)
+	http = addVersionResponseHeader(http, newVersion)
+
+	return istionetwork.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      alias.Name,
+			Namespace: ctx.Namespace,
+			Labels: map[string]string{
+				LabelIkeMutated:     LabelIkeMutatedValue,
+				model.LabelIkeAlias: "true",
+			},
+		},
+		Spec: v1alpha3.VirtualService{
+			Hosts: []string{alias.Name},
+			Http:  []*v1alpha3.HTTPRoute{&http},
+		},
+	}
+}
+
+// createBaseVirtualService creates VirtualService routing all the mesh traffic of the host to the service as is, the same
+// way it is routed when there is no VirtualService at all.
+func createBaseVirtualService(resource model.LocatorStatus) istionetwork.VirtualService {
+	return istionetwork.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+			Labels: map[string]string{
+				LabelIkeSynthesized: LabelIkeSynthesizedValue,
+			},
+		},
+		Spec: v1alpha3.VirtualService{
+			Hosts: []string{resource.Labels["host"]},
+			Http: []*v1alpha3.HTTPRoute{
+				{
+					Route: []*v1alpha3.HTTPRouteDestination{
+						{
+							Destination: &v1alpha3.Destination{
+								Host: resource.Labels["host"],
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func simplifyTargetRouteWithoutMatch(targetHTTP v1alpha3.HTTPRoute, hostName model.HostName, version, newVersion string, target *istionetwork.VirtualService) {
 	targetHTTP = removeOtherRoutes(targetHTTP, hostName, version)
 	targetHTTP = updateSubset(targetHTTP, newVersion)
+	targetHTTP = addVersionResponseHeader(targetHTTP, newVersion)
 	targetHTTP = removeWeight(targetHTTP)
 	targetHTTP.Mirror = nil
 	targetHTTP.Redirect = nil
@@ -336,6 +585,7 @@ func simplifyTargetRoute(ctx model.SessionContext, targetHTTP v1alpha3.HTTPRoute
 	targetHTTP = removeOtherRoutes(targetHTTP, hostName, version)
 	targetHTTP = updateSubset(targetHTTP, newVersion)
 	targetHTTP = addHeaderMatch(targetHTTP, ctx.Route)
+	targetHTTP = addVersionResponseHeader(targetHTTP, newVersion)
 	targetHTTP = removeWeight(targetHTTP)
 	targetHTTP.Mirror = nil
 	targetHTTP.Redirect = nil
@@ -373,6 +623,113 @@ func getVirtualServices(ctx model.SessionContext, namespace string, opts ...clie
 	return &virtualServices, errors.WrapWithDetails(err, "failed finding virtual services in namespace", "namespace", namespace)
 }
 
+// getDelegates returns the VirtualServices the routes of the given one are delegated to.
+func getDelegates(vs istionetwork.VirtualService) []types.NamespacedName {
+	var delegates []types.NamespacedName
+	for _, http := range vs.Spec.Http {
+		if http.Delegate == nil {
+			continue
+		}
+		namespace := http.Delegate.Namespace
+		if namespace == "" {
+			namespace = vs.Namespace
+		}
+		delegates = append(delegates, types.NamespacedName{Namespace: namespace, Name: http.Delegate.Name})
+	}
+
+	return delegates
+}
+
+// getDelegatedVirtualServices returns the delegated VirtualServices of the given ones which are not already part of the list,
+// as they can live in other namespaces.
+func getDelegatedVirtualServices(ctx model.SessionContext, vss *istionetwork.VirtualServiceList) ([]istionetwork.VirtualService, error) {
+	known := map[types.NamespacedName]bool{}
+	for i := range vss.Items {
+		known[types.NamespacedName{Namespace: vss.Items[i].Namespace, Name: vss.Items[i].Name}] = true
+	}
+
+	var delegated []istionetwork.VirtualService
+	for i := range vss.Items {
+		for _, delegate := range getDelegates(vss.Items[i]) {
+			if known[delegate] {
+				continue
+			}
+			known[delegate] = true
+			vs, err := getVirtualService(ctx, delegate.Namespace, delegate.Name)
+			if k8sErrors.IsNotFound(errors.Cause(err)) {
+				ctx.Log.Info("delegated virtual service not found", "name", delegate.Name, "namespace", delegate.Namespace)
+
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			delegated = append(delegated, *vs)
+		}
+	}
+
+	return delegated, nil
+}
+
+// getMarkedVirtualServices returns the VirtualServices marked by the ref, both in the searched namespaces and in the namespaces
+// the VirtualServices from there delegate to.
+func getMarkedVirtualServices(ctx model.SessionContext, vss *istionetwork.VirtualServiceList, labelKey string) (*istionetwork.VirtualServiceList, error) {
+	namespaces := getVirtualServiceNamespaces(ctx)
+	for i := range vss.Items {
+		for _, delegate := range getDelegates(vss.Items[i]) {
+			namespaces = append(namespaces, delegate.Namespace)
+		}
+	}
+
+	return getVirtualServicesInNamespaces(ctx, namespaces, reference.RefMarkerMatch(labelKey))
+}
+
+// getVirtualServiceNamespaces returns the namespaces searched for VirtualServices routing to the targets, starting with the
+// session namespace. All the namespaces are represented by a single empty one.
+func getVirtualServiceNamespaces(ctx model.SessionContext) []string {
+	if isInSlice(ctx.VirtualServiceNamespaces, "*") {
+		return []string{metav1.NamespaceAll}
+	}
+
+	return append([]string{ctx.Namespace}, ctx.VirtualServiceNamespaces...)
+}
+
+// getVirtualServicesInNamespaces lists the VirtualServices in all the given namespaces, each of them searched only once.
+func getVirtualServicesInNamespaces(ctx model.SessionContext, namespaces []string, opts ...client.ListOption) (*istionetwork.VirtualServiceList, error) {
+	virtualServices := istionetwork.VirtualServiceList{}
+	searched := map[string]bool{}
+	for _, namespace := range namespaces {
+		if searched[namespace] || (searched[metav1.NamespaceAll] && namespace != metav1.NamespaceAll) {
+			continue
+		}
+		searched[namespace] = true
+		vss, err := getVirtualServices(ctx, namespace, opts...)
+		if err != nil {
+			return &virtualServices, err
+		}
+		virtualServices.Items = append(virtualServices.Items, vss.Items...)
+	}
+
+	return &virtualServices, nil
+}
+
+// refersToHost checks if the VirtualService can refer to the host, as short names are resolved within the namespace of
+// the VirtualService and only fully qualified names reach the host from the other namespaces.
+func refersToHost(vs istionetwork.VirtualService, hostName model.HostName) bool {
+	if hostName.Namespace == "" || vs.Namespace == hostName.Namespace {
+		return true
+	}
+	for _, http := range vs.Spec.Http {
+		for _, route := range http.Route {
+			if route.Destination != nil && route.Destination.Host == hostName.String() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func mutationRequired(vs istionetwork.VirtualService, targetHost model.HostName, targetVersion string) bool {
 	for _, http := range vs.Spec.Http {
 		for _, route := range http.Route {
@@ -387,6 +744,37 @@ func mutationRequired(vs istionetwork.VirtualService, targetHost model.HostName,
 	return false
 }
 
+// sessionRoutes returns the JSON representation of the routes leading to the given version, so they can be compared regardless
+// of the internal fields of the generated types.
+func sessionRoutes(vs istionetwork.VirtualService, version string) []string {
+	routes := []string{}
+	for _, http := range vs.Spec.Http {
+		for _, route := range http.Route {
+			if route.Destination != nil && strings.Contains(route.Destination.Subset, version) {
+				routes = append(routes, specJSON(http))
+
+				break
+			}
+		}
+	}
+	sort.Strings(routes)
+
+	return routes
+}
+
+func routesEqual(routes, otherRoutes []string) bool {
+	if len(routes) != len(otherRoutes) {
+		return false
+	}
+	for i := range routes {
+		if routes[i] != otherRoutes[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func vsAlreadyMutated(vs istionetwork.VirtualService, targetHost model.HostName, targetVersion string) bool {
 	for _, http := range vs.Spec.Http {
 		for _, route := range http.Route {
@@ -399,6 +787,22 @@ func vsAlreadyMutated(vs istionetwork.VirtualService, targetHost model.HostName,
 	return false
 }
 
+func routesMeshTraffic(vs istionetwork.VirtualService) bool {
+	return len(vs.Spec.Gateways) == 0 || isInSlice(vs.Spec.Gateways, "mesh")
+}
+
+func routesToHost(vs istionetwork.VirtualService, hostName model.HostName) bool {
+	for _, http := range vs.Spec.Http {
+		for _, route := range http.Route {
+			if route.Destination != nil && hostName.Match(route.Destination.Host) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func connectedToGateway(vs istionetwork.VirtualService) ([]string, bool) {
 	return vs.Spec.Gateways, len(vs.Spec.Gateways) > 0
 }
@@ -437,11 +841,17 @@ func updateSubset(http v1alpha3.HTTPRoute, subset string) v1alpha3.HTTPRoute {
 
 func addHeaderMatch(http v1alpha3.HTTPRoute, route model.Route) v1alpha3.HTTPRoute {
 	addHeader := func(m *v1alpha3.HTTPMatchRequest, route model.Route) {
-		if route.Type == "header" {
+		switch route.Type {
+		case model.RouteTypeHeader:
 			if m.Headers == nil {
 				m.Headers = map[string]*v1alpha3.StringMatch{}
 			}
 			m.Headers[route.Name] = &v1alpha3.StringMatch{MatchType: &v1alpha3.StringMatch_Exact{Exact: route.Value}}
+		case model.RouteTypeBaggage:
+			if m.Headers == nil {
+				m.Headers = map[string]*v1alpha3.StringMatch{}
+			}
+			m.Headers[BaggageHeader] = &v1alpha3.StringMatch{MatchType: &v1alpha3.StringMatch_Regex{Regex: baggageEntryRegex(route.Name, route.Value)}}
 		}
 	}
 	if len(http.Match) > 0 {
@@ -470,7 +880,35 @@ func addHeaderRequest(http v1alpha3.HTTPRoute, route model.Route) v1alpha3.HTTPR
 			Add: map[string]string{},
 		}
 	}
-	http.Headers.Request.Add[route.Name] = route.Value
+	if route.Type == model.RouteTypeBaggage {
+		http.Headers.Request.Add[BaggageHeader] = route.Name + "=" + route.Value
+	} else {
+		http.Headers.Request.Add[route.Name] = route.Value
+	}
+
+	return http
+}
+
+// baggageEntryRegex matches W3C baggage header containing the given key and value as one of its list members,
+// optionally followed by properties, e.g. "userId=alice,workspace=feature-x;ttl=60".
+func baggageEntryRegex(key, value string) string {
+	return `^(.*,)?\s*` + regexp.QuoteMeta(key) + `\s*=\s*` + regexp.QuoteMeta(value) + `\s*(;[^,]*)?(,.*)?$`
+}
+
+// addVersionResponseHeader lets the caller know which version answered, so the session routing can be verified end to end.
+func addVersionResponseHeader(http v1alpha3.HTTPRoute, version string) v1alpha3.HTTPRoute {
+	headers := &v1alpha3.Headers{}
+	if http.Headers != nil {
+		headers = http.Headers.DeepCopy()
+	}
+	if headers.Response == nil {
+		headers.Response = &v1alpha3.Headers_HeaderOperations{}
+	}
+	if headers.Response.Set == nil {
+		headers.Response.Set = map[string]string{}
+	}
+	headers.Response.Set[ResponseVersionHeader] = version
+	http.Headers = headers
 
 	return http
 }
@@ -483,6 +921,32 @@ func removeWeight(http v1alpha3.HTTPRoute) v1alpha3.HTTPRoute {
 	return http
 }
 
+// getSessionGateways replaces the Gateways the session created its own ones for, so the VirtualService routes the session hosts
+// through them. Gateways in other namespaces than the one of the VirtualService are referred to by <namespace>/<name>.
+func getSessionGateways(ctx model.SessionContext, store model.LocatorStatusStore, vsNamespace string, gateways []string) []string {
+	sessionGateways := []string{}
+	for _, gateway := range gateways {
+		sessionGateway := gateway
+		for _, located := range store(GatewayKind) {
+			if located.Action != model.ActionCreate || (located.GetNamespaceName() != gateway && located.Name != gateway) {
+				continue
+			}
+			if len(getHostsFromGateway(ctx, store, []string{gateway})) == 0 { // catch-all hosts only, no session Gateway created
+				break
+			}
+			sessionGateway = SessionGatewayName(located.Name, ctx.Name)
+			if vsNamespace != ctx.Namespace {
+				sessionGateway = ctx.Namespace + "/" + sessionGateway
+			}
+
+			break
+		}
+		sessionGateways = append(sessionGateways, sessionGateway)
+	}
+
+	return sessionGateways
+}
+
 func getHostsFromGateway(ctx model.SessionContext, store model.LocatorStatusStore, gateways []string) []string {
 	var hosts []string
 	gwByName := func(store model.LocatorStatusStore, gatewayName string) []model.LocatorStatus {
@@ -499,7 +963,9 @@ func getHostsFromGateway(ctx model.SessionContext, store model.LocatorStatusStor
 	for _, gateway := range gateways {
 		for _, gwTarget := range gwByName(store, gateway) {
 			for _, host := range strings.Split(gwTarget.Labels[LabelIkeHosts], ",") {
-				hosts = append(hosts, ctx.Name+"."+host)
+				if _, exposedHost, exposed := sessionHost(ctx.Name, host); exposed && !isInSlice(hosts, exposedHost) {
+					hosts = append(hosts, exposedHost)
+				}
 			}
 		}
 	}
@@ -1,6 +1,8 @@
 package istio //nolint:testpackage //reason we want to test mutationRequired in isolation
 
 import (
+	"regexp"
+
 	"github.com/maistra/istio-workspace/api/maistra/v1alpha1"
 	"github.com/maistra/istio-workspace/pkg/log"
 	"github.com/maistra/istio-workspace/pkg/model"
@@ -135,6 +137,440 @@ var _ = Describe("Operations for istio VirtualService kind", func() {
 			Expect(actions[0].Name).To(Equal("details"))
 		})
 
+		It("should leave team owned virtual service untouched in GitOps mode", func() {
+			// given
+			ctx.GitOps = true
+
+			// when
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+
+			// then
+			Expect(locators.Store(VirtualServiceKind)).To(BeEmpty())
+		})
+
+		It("should not report drift when session route is unchanged", func() {
+			// given
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+			VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+			// when
+			newLocatorStore := createLocatorStore()
+			err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+			Expect(err).ToNot(HaveOccurred())
+			modificators = model.ModificatorStore{}
+			VirtualServiceModificator(ctx, ref, newLocatorStore.Store, modificators.Report)
+
+			// then
+			Expect(modificators.Stored).To(HaveLen(1))
+			Expect(modificators.Stored[0].Success).To(BeTrue())
+			Expect(modificators.Stored[0].Prop).ToNot(HaveKey(PropDrifted))
+			Expect(get.VirtualService("test", "details").Spec.Http).To(HaveLen(2))
+		})
+
+		It("should restore session route changed since it was applied", func() {
+			// given
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+			VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+			mutated := get.VirtualService("test", "details")
+			mutated.Spec.Http[0].Match[0].Headers[ctx.Route.Name] = &istionetworkv1alpha3.StringMatch{
+				MatchType: &istionetworkv1alpha3.StringMatch_Exact{Exact: "changed"},
+			}
+			Expect(c.Update(ctx, &mutated)).To(Succeed())
+
+			// when
+			newLocatorStore := createLocatorStore()
+			err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+			Expect(err).ToNot(HaveOccurred())
+			modificators = model.ModificatorStore{}
+			VirtualServiceModificator(ctx, ref, newLocatorStore.Store, modificators.Report)
+
+			// then
+			Expect(modificators.Stored).To(HaveLen(1))
+			Expect(modificators.Stored[0].Prop).To(HaveKeyWithValue(PropDrifted, "true"))
+
+			restored := get.VirtualService("test", "details")
+			Expect(restored.Spec.Http).To(HaveLen(2))
+			Expect(restored.Spec.Http[0].Match[0].Headers[ctx.Route.Name].GetExact()).To(Equal(ctx.Route.Value))
+		})
+
+		It("should keep changes made concurrently by others", func() {
+			// given
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+			sessionClient := &testclient.InterferingClient{Client: c, Interfere: func() {
+				concurrent := get.VirtualService("test", "details")
+				concurrent.Spec.Hosts = append(concurrent.Spec.Hosts, "details.test.svc.cluster.local")
+				Expect(c.Update(ctx, &concurrent)).To(Succeed())
+			}}
+			ctx.Client = sessionClient
+
+			// when
+			VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+			// then
+			Expect(modificators.Stored).To(HaveLen(1))
+			Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+			Expect(sessionClient.Writes).To(Equal(2))
+
+			modified := get.VirtualService("test", "details")
+			Expect(modified.Spec.Hosts).To(ConsistOf("details", "details.test.svc.cluster.local"))
+			Expect(modified.Spec.Http).To(HaveLen(2))
+		})
+
+		It("should trigger create action for alias when session asks for it", func() {
+			// given
+			ctx.Route.Alias = true
+
+			// when
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+
+			// then
+			actions := locators.Store(VirtualServiceKind)
+			Expect(actions).To(HaveLen(2))
+			Expect(actions).To(ContainElement(And(
+				WithTransform(func(l model.LocatorStatus) string { return l.Name }, Equal("details-vs-test")),
+				WithTransform(func(l model.LocatorStatus) model.StatusAction { return l.Action }, Equal(model.ActionCreate)),
+			)))
+		})
+
+		It("should create alias routing directly to the session version", func() {
+			// given
+			ctx.Route.Alias = true
+			locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: "test", Name: "details-v1"}, Action: model.ActionCreate, Labels: map[string]string{"version": "v1"}})
+
+			// when
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+			VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+			// then
+			alias := get.VirtualService("test", "details-vs-test")
+			Expect(alias.Labels).To(HaveKeyWithValue(model.LabelIkeAlias, "true"))
+			Expect(alias.Spec.Hosts).To(ConsistOf("details-vs-test"))
+			Expect(alias.Spec.Http).To(HaveLen(1))
+			Expect(alias.Spec.Http[0].Match).To(BeEmpty())
+			Expect(alias.Spec.Http[0].Route[0].Destination.Host).To(Equal("details.test.svc.cluster.local"))
+			Expect(alias.Spec.Http[0].Route[0].Destination.Subset).To(Equal(model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)))
+			Expect(alias.Spec.Http[0].Headers.Request.Add).To(HaveKeyWithValue(ctx.Route.Name, ctx.Route.Value))
+		})
+
+		It("should trigger delete action for alias when session no longer asks for it", func() {
+			// given
+			ctx.Route.Alias = true
+			err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+			Expect(err).ToNot(HaveOccurred())
+			VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+			// when
+			ctx.Route.Alias = false
+			newLocatorStore := createLocatorStore()
+			err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+			Expect(err).ToNot(HaveOccurred())
+
+			// then
+			Expect(newLocatorStore.Store(VirtualServiceKind)).To(ContainElement(And(
+				WithTransform(func(l model.LocatorStatus) string { return l.Name }, Equal("details-vs-test")),
+				WithTransform(func(l model.LocatorStatus) model.StatusAction { return l.Action }, Equal(model.ActionDelete)),
+			)))
+		})
+
+		Context("service without virtual service", func() {
+
+			unversionedLocatorStore := func() model.LocatorStore {
+				locators := model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "ratings"}})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: "test", Name: "ratings"}, Action: model.ActionCreate, Labels: map[string]string{"app": "ratings"}})
+
+				return locators
+			}
+
+			BeforeEach(func() {
+				locators = unversionedLocatorStore()
+			})
+
+			It("should trigger create action for base virtual service", func() {
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then
+				actions := locators.Store(VirtualServiceKind)
+				Expect(actions).To(HaveLen(1))
+				Expect(actions[0].Action).To(Equal(model.ActionCreate))
+				Expect(actions[0].Name).To(Equal("ratings-ike-base"))
+				Expect(actions[0].Labels).To(HaveKeyWithValue(LabelIkeSynthesized, LabelIkeSynthesizedValue))
+			})
+
+			It("should synthesize base virtual service with session route", func() {
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+				// then
+				vs := get.VirtualService("test", "ratings-ike-base")
+				Expect(vs.Spec.Hosts).To(ConsistOf("ratings.test.svc.cluster.local"))
+				Expect(vs.Spec.Http).To(HaveLen(2))
+				Expect(vs.Spec.Http[0].Match[0].Headers).To(HaveKey(ctx.Route.Name))
+				Expect(vs.Spec.Http[0].Route[0].Destination.Subset).To(Equal(model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)))
+				Expect(vs.Spec.Http[1].Route[0].Destination.Subset).To(BeEmpty())
+			})
+
+			It("should not synthesize base virtual service again", func() {
+				// given
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+				// when
+				newLocatorStore := unversionedLocatorStore()
+				err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then
+				actions := newLocatorStore.Store(VirtualServiceKind)
+				Expect(actions).To(HaveLen(1))
+				Expect(actions[0].Action).To(Equal(model.ActionModify))
+				Expect(actions[0].Name).To(Equal("ratings-ike-base"))
+			})
+
+			It("should delete base virtual service when reference is removed", func() {
+				// given
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				createdVersion := model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)
+
+				// when
+				ref.Remove = true
+				newLocatorStore := model.LocatorStore{}
+				newLocatorStore.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: "test", Name: "ratings-" + createdVersion}, Action: model.ActionDelete, Labels: map[string]string{"version": createdVersion}})
+				err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, newLocatorStore.Store, modificators.Report)
+
+				// then
+				for _, stored := range modificators.Stored {
+					Expect(stored.Error).ToNot(HaveOccurred())
+				}
+				Expect(get.VirtualServices("test").Items).To(HaveLen(1))
+			})
+		})
+
+		Context("virtual services in other namespaces", func() {
+
+			otherNamespaceVirtualService := func(name, host string) *istionetwork.VirtualService {
+				return &istionetwork.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "frontend",
+					},
+					Spec: istionetworkv1alpha3.VirtualService{
+						Hosts: []string{host},
+						Http: []*istionetworkv1alpha3.HTTPRoute{
+							{
+								Route: []*istionetworkv1alpha3.HTTPRouteDestination{
+									{
+										Destination: &istionetworkv1alpha3.Destination{
+											Host: host,
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				objects = append(objects,
+					otherNamespaceVirtualService("details-fqdn", "details.test.svc.cluster.local"),
+					otherNamespaceVirtualService("details-local", "details"),
+				)
+			})
+
+			It("should not look beyond session namespace by default", func() {
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then
+				actions := locators.Store(VirtualServiceKind)
+				Expect(actions).To(HaveLen(1))
+				Expect(actions[0].Namespace).To(Equal("test"))
+			})
+
+			It("should trigger modify action for virtual service routing to fully qualified host in additional namespace", func() {
+				// given
+				ctx.VirtualServiceNamespaces = []string{"frontend"}
+
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then
+				actions := locators.Store(VirtualServiceKind)
+				Expect(actions).To(HaveLen(2))
+				Expect(actions).To(ContainElement(And(
+					WithTransform(func(l model.LocatorStatus) string { return l.Namespace + "/" + l.Name }, Equal("frontend/details-fqdn")),
+					WithTransform(func(l model.LocatorStatus) model.StatusAction { return l.Action }, Equal(model.ActionModify)),
+				)))
+			})
+
+			It("should search all namespaces when asked for", func() {
+				// given
+				ctx.VirtualServiceNamespaces = []string{"*"}
+
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then
+				actions := locators.Store(VirtualServiceKind)
+				Expect(actions).To(HaveLen(2))
+				Expect(actions).ToNot(ContainElement(
+					WithTransform(func(l model.LocatorStatus) string { return l.Name }, Equal("details-local")),
+				))
+			})
+
+			It("should revert virtual service in additional namespace when reference is removed", func() {
+				// given
+				ctx.VirtualServiceNamespaces = []string{"frontend"}
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				mutated := get.VirtualService("frontend", "details-fqdn")
+				Expect(mutated.Spec.Http).To(HaveLen(2))
+				Expect(mutated.Annotations).To(HaveKeyWithValue(reference.NamespacedNameAnnotation, "test/vs-test"))
+
+				// when
+				ref.Remove = true
+				newLocatorStore := createLocatorStore()
+				err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, newLocatorStore.Store, modificators.Report)
+
+				// then
+				reverted := get.VirtualService("frontend", "details-fqdn")
+				Expect(reverted.Spec.Http).To(HaveLen(1))
+				Expect(reference.HasRefMarkers(&reverted)).To(BeFalse())
+			})
+		})
+
+		Context("delegated virtual service", func() {
+
+			delegatedLocatorStore := func() model.LocatorStore {
+				locators := model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "reviews"}})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: "test", Name: "reviews-v1"}, Labels: map[string]string{"version": "v1"}})
+
+				return locators
+			}
+
+			BeforeEach(func() {
+				locators = delegatedLocatorStore()
+				objects = []runtime.Object{
+					&istionetwork.VirtualService{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "reviews-root",
+							Namespace: "test",
+						},
+						Spec: istionetworkv1alpha3.VirtualService{
+							Hosts: []string{"reviews"},
+							Http: []*istionetworkv1alpha3.HTTPRoute{
+								{
+									Delegate: &istionetworkv1alpha3.Delegate{
+										Name:      "reviews-routes",
+										Namespace: "reviews-team",
+									},
+								},
+							},
+						},
+					},
+					&istionetwork.VirtualService{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "reviews-routes",
+							Namespace: "reviews-team",
+						},
+						Spec: istionetworkv1alpha3.VirtualService{
+							Http: []*istionetworkv1alpha3.HTTPRoute{
+								{
+									Route: []*istionetworkv1alpha3.HTTPRouteDestination{
+										{
+											Destination: &istionetworkv1alpha3.Destination{
+												Host:   "reviews.test.svc.cluster.local",
+												Subset: "v1",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("should trigger modify action for delegated virtual service only", func() {
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+
+				// then
+				actions := locators.Store(VirtualServiceKind)
+				Expect(actions).To(HaveLen(1))
+				Expect(actions[0].Action).To(Equal(model.ActionModify))
+				Expect(actions[0].Namespace).To(Equal("reviews-team"))
+				Expect(actions[0].Name).To(Equal("reviews-routes"))
+			})
+
+			It("should add session route to delegated virtual service and leave the root alone", func() {
+				// when
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+
+				// then
+				delegated := get.VirtualService("reviews-team", "reviews-routes")
+				Expect(delegated.Spec.Http).To(HaveLen(2))
+				Expect(delegated.Spec.Http[0].Match[0].Headers).To(HaveKey(ctx.Route.Name))
+				Expect(delegated.Spec.Http[0].Route[0].Destination.Subset).To(Equal(model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)))
+
+				root := get.VirtualService("test", "reviews-root")
+				Expect(root.Spec.Http).To(HaveLen(1))
+				Expect(reference.HasRefMarkers(&root)).To(BeFalse())
+			})
+
+			It("should revert delegated virtual service when reference is removed", func() {
+				// given
+				err := VirtualServiceLocator(ctx, ref, locators.Store, locators.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				createdVersion := model.GetCreatedVersion(locators.Store, model.DefaultVersionLabel, ctx.Name)
+
+				// when
+				ref.Remove = true
+				newLocatorStore := model.LocatorStore{}
+				newLocatorStore.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Namespace: "test", Name: "reviews-" + createdVersion}, Action: model.ActionDelete, Labels: map[string]string{"version": createdVersion}})
+				err = VirtualServiceLocator(ctx, ref, newLocatorStore.Store, newLocatorStore.Report)
+				Expect(err).ToNot(HaveOccurred())
+				VirtualServiceModificator(ctx, ref, newLocatorStore.Store, modificators.Report)
+
+				// then
+				for _, stored := range modificators.Stored {
+					Expect(stored.Error).ToNot(HaveOccurred())
+				}
+				delegated := get.VirtualService("reviews-team", "reviews-routes")
+				Expect(delegated.Spec.Http).To(HaveLen(1))
+				Expect(delegated.Spec.Http[0].Route[0].Destination.Subset).To(Equal("v1"))
+				Expect(reference.HasRefMarkers(&delegated)).To(BeFalse())
+			})
+		})
+
 	})
 
 	Context("manipulation", func() {
@@ -392,6 +828,36 @@ var _ = Describe("Operations for istio VirtualService kind", func() {
 					}
 				})
 
+				It("reports answering version in response header", func() {
+					locators.Report(targetV1)
+					locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Name: "details"}})
+
+					VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+					Expect(modificators.Stored).To(HaveLen(1))
+					Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+					virtualService := get.VirtualService("test", "details")
+					mutated := GetMutatedRoute(virtualService, targetV1Host, targetV1Subset)
+					Expect(mutated.Headers.Response.Set).To(HaveKeyWithValue(ResponseVersionHeader, targetV1Subset))
+				})
+
+				It("matches baggage entry for baggage route", func() {
+					ctx.Route = model.Route{Type: "baggage", Name: "workspace", Value: "vs-test"}
+					locators.Report(targetV4)
+					locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Name: "details"}})
+
+					VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+					Expect(modificators.Stored).To(HaveLen(1))
+					Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+					virtualService := get.VirtualService("test", "details")
+					mutated := GetMutatedRoute(virtualService, targetV4Host, targetV4Subset)
+					Expect(mutated).ToNot(BeNil())
+					Expect(mutated.Match).To(HaveLen(1))
+					Expect(mutated.Match[0].Headers).To(HaveKey(BaggageHeader))
+					Expect(mutated.Match[0].Headers[BaggageHeader].GetRegex()).To(Equal(baggageEntryRegex("workspace", "vs-test")))
+				})
+
 				It("remove weighted destination", func() {
 					locators.Report(targetV1)
 					locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Name: "details"}})
@@ -708,6 +1174,93 @@ var _ = Describe("Operations for istio VirtualService kind", func() {
 				Expect(created.Spec.Hosts).To(ContainElement(ctx.Name + ".redhat-kubecon.io"))
 			})
 
+			It("should attach to the session gateway in GitOps mode", func() {
+				ctx.GitOps = true
+				ref := model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators := model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "customer"}})
+				locators.Report(model.LocatorStatus{
+					Resource: model.Resource{
+						Kind:      "Gateway",
+						Namespace: "test",
+						Name:      "test-gateway",
+					},
+					Labels: map[string]string{LabelIkeHosts: "redhat-kubecon.io"},
+					Action: model.ActionCreate,
+				})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: VirtualServiceKind, Namespace: "test", Name: "customer"}, Action: model.ActionCreate})
+				modificators := model.ModificatorStore{}
+
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				created := get.VirtualService("test", "customer-"+ctx.Name)
+				Expect(created.Spec.Hosts).To(ConsistOf(ctx.Name + ".redhat-kubecon.io"))
+				Expect(created.Spec.Gateways).To(ConsistOf(SessionGatewayName("test-gateway", ctx.Name)))
+				Expect(get.VirtualService("test", "customer").Spec.Gateways).To(ConsistOf("test-gateway"))
+			})
+
+			It("should attach to a host covered by wildcard and namespace scoped hosts", func() {
+				ref := model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators := model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "customer"}})
+				locators.Report(model.LocatorStatus{
+					Resource: model.Resource{
+						Kind:      "Gateway",
+						Namespace: "test",
+						Name:      "test-gateway",
+					},
+					Labels: map[string]string{LabelIkeHosts: "*.redhat-kubecon.io,test/redhat.com,*"},
+				})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: VirtualServiceKind, Namespace: "test", Name: "customer"}, Action: model.ActionCreate})
+				modificators := model.ModificatorStore{}
+
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				created := get.VirtualService("test", "customer-"+ctx.Name)
+				Expect(created.Spec.Hosts).To(ConsistOf(ctx.Name+".redhat-kubecon.io", ctx.Name+".redhat.com"))
+			})
+
+			It("should only route requests carrying the session route for catch-all gateway host", func() {
+				ref := model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators := model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "customer"}})
+				locators.Report(model.LocatorStatus{
+					Resource: model.Resource{
+						Kind:      "Gateway",
+						Namespace: "test",
+						Name:      "test-gateway",
+					},
+					Labels: map[string]string{LabelIkeHosts: "*"},
+				})
+				locators.Report(model.LocatorStatus{
+					Resource: model.Resource{Kind: VirtualServiceKind, Namespace: "test", Name: "customer"},
+					Action:   model.ActionCreate,
+					Labels:   map[string]string{"host": "customer"},
+				})
+				modificators := model.ModificatorStore{}
+
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				created := get.VirtualService("test", "customer-"+ctx.Name)
+				Expect(created.Spec.Hosts).To(ConsistOf("*"))
+				Expect(created.Spec.Http).To(HaveLen(1))
+				Expect(created.Spec.Http[0].Match).To(HaveLen(1))
+				Expect(created.Spec.Http[0].Match[0].Headers).To(HaveKey(ctx.Route.Name))
+				Expect(created.Spec.Http[0].Match[0].Headers[ctx.Route.Name].GetExact()).To(Equal(ctx.Route.Value))
+			})
+
 			It("should add request headers", func() {
 				ref := model.Ref{
 					KindName: model.ParseRefKindName("customer-v1"),
@@ -733,6 +1286,32 @@ var _ = Describe("Operations for istio VirtualService kind", func() {
 				Expect(created.Spec.Http[0].Headers.Request.Add).To(HaveKeyWithValue(ctx.Route.Name, ctx.Route.Value))
 			})
 
+			It("should add baggage request header for baggage route", func() {
+				ctx.Route = model.Route{Type: "baggage", Name: "workspace", Value: "vs-test"}
+				ref := model.Ref{
+					KindName: model.ParseRefKindName("customer-v1"),
+				}
+				locators := model.LocatorStore{}
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "customer"}})
+				locators.Report(model.LocatorStatus{
+					Resource: model.Resource{
+						Kind:      "Gateway",
+						Namespace: "test",
+						Name:      "test-gateway",
+					},
+					Labels: map[string]string{LabelIkeHosts: "redhat-kubecon.io"},
+				})
+				locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: VirtualServiceKind, Namespace: "test", Name: "customer"}, Action: model.ActionCreate})
+				modificators := model.ModificatorStore{}
+
+				VirtualServiceModificator(ctx, ref, locators.Store, modificators.Report)
+				Expect(modificators.Stored).To(HaveLen(1))
+				Expect(modificators.Stored[0].Error).ToNot(HaveOccurred())
+
+				created := get.VirtualService("test", "customer-"+ctx.Name)
+				Expect(created.Spec.Http[0].Headers.Request.Add).To(HaveKeyWithValue(BaggageHeader, "workspace=vs-test"))
+			})
+
 			It("should duplicate non effected vs", func() {
 				ref := model.Ref{
 					KindName: model.ParseRefKindName("customer-v1"),
@@ -811,6 +1390,27 @@ var _ = Describe("Operations for istio VirtualService kind", func() {
 	})
 })
 
+var _ = Describe("Baggage route matching", func() {
+
+	var matcher *regexp.Regexp
+
+	BeforeEach(func() {
+		matcher = regexp.MustCompile(baggageEntryRegex("workspace", "feature-x"))
+	})
+
+	It("should match entry among other list members", func() {
+		Expect(matcher.MatchString("workspace=feature-x")).To(BeTrue())
+		Expect(matcher.MatchString("userId=alice, workspace = feature-x ,serverNode=DF28")).To(BeTrue())
+		Expect(matcher.MatchString("userId=alice,workspace=feature-x;ttl=60")).To(BeTrue())
+	})
+
+	It("should not match different key or value", func() {
+		Expect(matcher.MatchString("workspace=feature-xy")).To(BeFalse())
+		Expect(matcher.MatchString("my-workspace=feature-x")).To(BeFalse())
+		Expect(matcher.MatchString("userId=workspace")).To(BeFalse())
+	})
+})
+
 func createLocatorStore() model.LocatorStore {
 	locators := model.LocatorStore{}
 	locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "details"}})
@@ -69,6 +69,10 @@ func VirtualServiceGatewayLocator(ctx model.SessionContext, ref model.Ref, store
 						continue
 					}
 
+					if gw.Labels[LabelIkeMutated] == LabelIkeMutatedValue { // created by the session itself
+						continue
+					}
+
 					existingHosts := extractExistingHosts(gw)
 
 					var hosts []string
@@ -76,13 +80,17 @@ func VirtualServiceGatewayLocator(ctx model.SessionContext, ref model.Ref, store
 						hosts = findNewHosts(server, existingHosts, hosts)
 					}
 
+					action := model.ActionModify
+					if ctx.GitOps { // session hosts are served by Gateway of the session
+						action = model.ActionCreate
+					}
 					report(model.LocatorStatus{
 						Resource: model.Resource{
 							Kind:      GatewayKind,
 							Namespace: gwNs,
 							Name:      gwName,
 						},
-						Labels: map[string]string{LabelIkeHosts: strings.Join(hosts, ",")}, Action: model.ActionModify})
+						Labels: map[string]string{LabelIkeHosts: strings.Join(hosts, ",")}, Action: action})
 				}
 			}
 		}
@@ -0,0 +1,175 @@
+package istio
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/model"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// SidecarKind is the k8s Kind for a istio Sidecar.
+	SidecarKind = "Sidecar"
+
+	// exportToAnnotation limits the namespaces a Service is visible in, the same way exportTo does for istio resources.
+	exportToAnnotation = "networking.istio.io/exportTo"
+
+	exportToAll       = "*"
+	exportToNamespace = "."
+)
+
+// FindRouteVisibilityIssues explains why the session route will not take effect for some of the callers of the targets.
+// Sidecars importing the target host but not the in-mesh alias host of the session keep their workloads from reaching
+// the alias, while VirtualServices exported to fewer namespaces than the Service they route to are bypassed by the callers
+// from the remaining namespaces. In GitOps mode the VirtualServices owned by the teams are not changed at all, leaving the session
+// route out of the mesh.
+func FindRouteVisibilityIssues(ctx model.SessionContext, store model.LocatorStatusStore) ([]string, error) {
+	issues := []string{}
+	hostNames := model.GetTargetHostNames(store)
+	if len(hostNames) == 0 {
+		return issues, nil
+	}
+
+	sidecars := istionetwork.SidecarList{}
+	if err := ctx.Client.List(ctx, &sidecars); err != nil {
+		return issues, errors.WrapWithDetails(err, "failed listing sidecars")
+	}
+	virtualServices, err := getVirtualServicesInNamespaces(ctx, getVirtualServiceNamespaces(ctx))
+	if err != nil {
+		return issues, err
+	}
+
+	for _, hostName := range hostNames {
+		if ctx.Route.Alias {
+			alias := hostName.Alias(ctx.Name)
+			for i := range sidecars.Items {
+				sidecar := sidecars.Items[i]
+				if importsHost(&sidecar, ctx.Namespace, hostName.String()) && !importsHost(&sidecar, ctx.Namespace, alias.String()) {
+					issues = append(issues, fmt.Sprintf("%s %s/%s does not import alias host %s, its workloads can not reach the session",
+						SidecarKind, sidecar.Namespace, sidecar.Name, alias.String()))
+				}
+			}
+		}
+
+		serviceNamespace, serviceExportTo, err := getServiceExportTo(ctx, hostName)
+		if err != nil {
+			return issues, err
+		}
+		for i := range virtualServices.Items {
+			vs := virtualServices.Items[i]
+			if vs.Labels[LabelIkeMutated] == LabelIkeMutatedValue || !routesMeshTraffic(vs) || !refersToHost(vs, hostName) || !routesToHost(vs, hostName) {
+				continue
+			}
+			if ctx.GitOps && vs.Labels[LabelIkeSynthesized] != LabelIkeSynthesizedValue {
+				issues = append(issues, fmt.Sprintf("%s %s/%s routing %s is not changed in GitOps mode, calls from the mesh reach the session through the alias host only",
+					VirtualServiceKind, vs.Namespace, vs.Name, hostName.String()))
+			}
+			if notExportedTo := missingExports(vs.Namespace, vs.Spec.ExportTo, serviceNamespace, serviceExportTo); len(notExportedTo) > 0 {
+				issues = append(issues, fmt.Sprintf("%s %s/%s routing %s is not exported to %s, calls from there bypass the session route",
+					VirtualServiceKind, vs.Namespace, vs.Name, hostName.String(), strings.Join(notExportedTo, ",")))
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// importsHost checks if any of the egress listeners of the Sidecar imports the host defined in the given namespace.
+// Sidecars without egress listeners import all the hosts.
+func importsHost(sidecar *istionetwork.Sidecar, namespace, host string) bool {
+	if len(sidecar.Spec.Egress) == 0 {
+		return true
+	}
+	for _, egress := range sidecar.Spec.Egress {
+		for _, egressHost := range egress.Hosts {
+			parts := strings.SplitN(egressHost, "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if matchesEgressNamespace(parts[0], sidecar.Namespace, namespace) && matchesEgressHost(parts[1], host) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchesEgressNamespace(egressNamespace, sidecarNamespace, namespace string) bool {
+	switch egressNamespace {
+	case exportToAll:
+		return true
+	case exportToNamespace:
+		return sidecarNamespace == namespace
+	default:
+		return egressNamespace == namespace
+	}
+}
+
+func matchesEgressHost(egressHost, host string) bool {
+	if egressHost == exportToAll {
+		return true
+	}
+	if strings.HasPrefix(egressHost, "*.") {
+		return strings.HasSuffix(host, egressHost[1:])
+	}
+
+	return egressHost == host
+}
+
+// missingExports returns the namespaces the Service is exported to, but the resource defined in the given namespace is not.
+// Both are exported to all namespaces when no exportTo is defined.
+func missingExports(namespace string, exportTo []string, serviceNamespace string, serviceExportTo []string) []string {
+	resolve := func(exports []string, ns string) []string {
+		if len(exports) == 0 {
+			return []string{exportToAll}
+		}
+		resolved := []string{}
+		for _, export := range exports {
+			if export == exportToNamespace {
+				export = ns
+			}
+			resolved = append(resolved, export)
+		}
+
+		return resolved
+	}
+
+	exported := resolve(exportTo, namespace)
+	if isInSlice(exported, exportToAll) {
+		return []string{}
+	}
+
+	missing := []string{}
+	for _, serviceExport := range resolve(serviceExportTo, serviceNamespace) {
+		if !isInSlice(exported, serviceExport) {
+			missing = append(missing, serviceExport)
+		}
+	}
+
+	return missing
+}
+
+func getServiceExportTo(ctx model.SessionContext, hostName model.HostName) (string, []string, error) {
+	namespace := hostName.Namespace
+	if namespace == "" {
+		namespace = ctx.Namespace
+	}
+	service := corev1.Service{}
+	if err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: hostName.Name}, &service); err != nil {
+		return namespace, nil, errors.WrapWithDetails(err, "failed finding service in namespace", "name", hostName.Name, "namespace", namespace)
+	}
+
+	exportTo := []string{}
+	for _, export := range strings.Split(service.Annotations[exportToAnnotation], ",") {
+		if export = strings.TrimSpace(export); export != "" {
+			exportTo = append(exportTo, export)
+		}
+	}
+
+	return namespace, exportTo, nil
+}
@@ -0,0 +1,222 @@
+package istio_test
+
+import (
+	"github.com/maistra/istio-workspace/api/maistra/v1alpha1"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/log"
+	"github.com/maistra/istio-workspace/pkg/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"istio.io/api/networking/v1alpha3"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Route visibility", func() {
+
+	var (
+		objects  []runtime.Object
+		locators model.LocatorStore
+		route    model.Route
+		gitOps   bool
+	)
+
+	service := func(annotations map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "ratings", Namespace: "test", Annotations: annotations},
+		}
+	}
+
+	sidecar := func(namespace string, hosts ...string) *istionetwork.Sidecar {
+		return &istionetwork.Sidecar{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace},
+			Spec: v1alpha3.Sidecar{
+				Egress: []*v1alpha3.IstioEgressListener{{Hosts: hosts}},
+			},
+		}
+	}
+
+	virtualService := func(name string, exportTo ...string) *istionetwork.VirtualService {
+		return &istionetwork.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: v1alpha3.VirtualService{
+				Hosts:    []string{"ratings"},
+				ExportTo: exportTo,
+				Http: []*v1alpha3.HTTPRoute{
+					{Route: []*v1alpha3.HTTPRouteDestination{{Destination: &v1alpha3.Destination{Host: "ratings"}}}},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		route = model.Route{Type: "header", Name: "x", Value: "y"}
+		gitOps = false
+		locators = model.LocatorStore{}
+		locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Service", Namespace: "test", Name: "ratings"}})
+	})
+
+	findIssues := func() ([]string, error) {
+		schema, _ := v1alpha1.SchemeBuilder.Register(
+			&istionetwork.Sidecar{},
+			&istionetwork.SidecarList{},
+			&istionetwork.VirtualService{},
+			&istionetwork.VirtualServiceList{}).Build()
+		Expect(corev1.AddToScheme(schema)).To(Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(objects...).Build()
+		ctx := model.SessionContext{
+			Name:      "test",
+			Namespace: "test",
+			Route:     route,
+			GitOps:    gitOps,
+			Client:    c,
+			Log:       log.CreateOperatorAwareLogger("visibility"),
+		}
+
+		return istio.FindRouteVisibilityIssues(ctx, locators.Store)
+	}
+
+	It("should not report issues when nothing restricts visibility", func() {
+		// given
+		objects = []runtime.Object{service(nil), virtualService("ratings"), sidecar("bookinfo", "*/*")}
+		route.Alias = true
+
+		// when
+		issues, err := findIssues()
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+	})
+
+	It("should not report issues when no target service was located", func() {
+		// given
+		objects = []runtime.Object{}
+		locators = model.LocatorStore{}
+
+		// when
+		issues, err := findIssues()
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+	})
+
+	Context("sidecars", func() {
+
+		BeforeEach(func() {
+			route.Alias = true
+		})
+
+		It("should report sidecar importing the target host but not the alias host", func() {
+			// given
+			objects = []runtime.Object{service(nil), sidecar("bookinfo", "test/ratings.test.svc.cluster.local")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(ConsistOf(ContainSubstring("Sidecar bookinfo/default does not import alias host ratings-test.test.svc.cluster.local")))
+		})
+
+		It("should not report sidecar importing the target namespace with a wildcard", func() {
+			// given
+			objects = []runtime.Object{service(nil), sidecar("test", "./*.test.svc.cluster.local")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(BeEmpty())
+		})
+
+		It("should not report sidecar not importing the target host", func() {
+			// given
+			objects = []runtime.Object{service(nil), sidecar("bookinfo", "bookinfo/*")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(BeEmpty())
+		})
+
+		It("should ignore sidecars when the session does not use an alias host", func() {
+			// given
+			route.Alias = false
+			objects = []runtime.Object{service(nil), sidecar("bookinfo", "test/ratings.test.svc.cluster.local")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(BeEmpty())
+		})
+	})
+
+	Context("exportTo", func() {
+
+		It("should report virtual service exported to fewer namespaces than the service", func() {
+			// given
+			objects = []runtime.Object{service(nil), virtualService("ratings", ".")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(ConsistOf(ContainSubstring("VirtualService test/ratings routing ratings.test.svc.cluster.local is not exported to *")))
+		})
+
+		It("should not report virtual service exported to the same namespaces as the service", func() {
+			// given
+			objects = []runtime.Object{service(map[string]string{"networking.istio.io/exportTo": "., bookinfo"}), virtualService("ratings", "test", "bookinfo")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(BeEmpty())
+		})
+
+		It("should report namespaces the service is exported to but the virtual service is not", func() {
+			// given
+			objects = []runtime.Object{service(map[string]string{"networking.istio.io/exportTo": ".,bookinfo,reviews"}), virtualService("ratings", ".", "reviews")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(ConsistOf(HaveSuffix("is not exported to bookinfo, calls from there bypass the session route")))
+		})
+	})
+
+	Context("gitops", func() {
+
+		BeforeEach(func() {
+			gitOps = true
+		})
+
+		It("should report team owned virtual service left without the session route", func() {
+			// given
+			objects = []runtime.Object{service(nil), virtualService("ratings")}
+
+			// when
+			issues, err := findIssues()
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issues).To(ConsistOf(ContainSubstring("VirtualService test/ratings routing ratings.test.svc.cluster.local is not changed in GitOps mode")))
+		})
+	})
+})
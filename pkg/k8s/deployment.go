@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"encoding/json"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/maistra/istio-workspace/pkg/model"
@@ -128,7 +129,7 @@ func actionCreateDeployment(ctx model.SessionContext, ref model.Ref, store model
 		return
 	}
 
-	deploymentClone, err := cloneDeployment(engine, deployment.DeepCopy(), ref, model.GetCreatedVersion(store, ctx.Name))
+	deploymentClone, err := cloneDeployment(engine, deployment.DeepCopy(), ref, model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name), ctx.GetVersionLabel())
 	if err != nil {
 		ctx.Log.Info("Failed to clone Deployment", "name", deployment.Name)
 		report(model.ModificatorStatus{
@@ -141,16 +142,11 @@ func actionCreateDeployment(ctx model.SessionContext, ref model.Ref, store model
 	if err = reference.Add(ctx.ToNamespacedName(), deploymentClone); err != nil {
 		ctx.Log.Error(err, "failed to add relation reference", "kind", deploymentClone.Kind, "name", deploymentClone.Name)
 	}
+	AddPodTemplateReference(ctx, &deploymentClone.Spec.Template)
 	reference.AddRefMarker(deploymentClone, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
 
-	if _, err = getDeployment(ctx, deploymentClone.Namespace, deploymentClone.Name); err == nil {
-		report(model.ModificatorStatus{
-			LocatorStatus: resource,
-			Success:       true,
-			Target: &model.Resource{
-				Namespace: deploymentClone.Namespace,
-				Kind:      DeploymentKind,
-				Name:      deploymentClone.Name}})
+	if existing, errGet := getDeployment(ctx, deploymentClone.Namespace, deploymentClone.Name); errGet == nil {
+		reportRollout(ctx, report, resource, existing)
 
 		return
 	}
@@ -167,13 +163,64 @@ func actionCreateDeployment(ctx model.SessionContext, ref model.Ref, store model
 	}
 
 	ctx.Log.Info("Cloned Deployment", "name", deploymentClone.Name)
-	report(model.ModificatorStatus{
+	reportRollout(ctx, report, resource, deploymentClone)
+}
+
+// reportRollout reports the clone as pending until it is rolled out, so no traffic is routed to it before. The clone which
+// pods fail to run, or which is not available within the rollout timeout, is reported as failed together with the reason.
+func reportRollout(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus, clone *appsv1.Deployment) {
+	status := model.ModificatorStatus{
 		LocatorStatus: resource,
 		Success:       true,
 		Target: &model.Resource{
-			Namespace: deploymentClone.Namespace,
+			Namespace: clone.Namespace,
 			Kind:      DeploymentKind,
-			Name:      deploymentClone.Name}})
+			Name:      clone.Name}}
+	var selector map[string]string
+	if clone.Spec.Selector != nil {
+		selector = clone.Spec.Selector.MatchLabels
+	}
+	if err := PodsFailed(ctx, clone.Namespace, selector); err != nil {
+		status.Success = false
+		status.Error = errors.WrapWithDetails(err, "cloned Deployment fails to run", "kind", DeploymentKind, "name", clone.Name)
+		report(status)
+
+		return
+	}
+	if ctx.RolloutTimeout <= 0 || deploymentRolledOut(clone) {
+		report(status)
+
+		return
+	}
+
+	if !clone.CreationTimestamp.IsZero() && time.Since(clone.CreationTimestamp.Time) > ctx.RolloutTimeout {
+		status.Success = false
+		status.Error = errors.NewWithDetails("cloned Deployment not available within "+ctx.RolloutTimeout.String()+": "+
+			PodsWaitingReason(ctx, clone.Namespace, selector), "kind", DeploymentKind, "name", clone.Name)
+		report(status)
+
+		return
+	}
+
+	ctx.Log.Info("Waiting for cloned Deployment rollout", "name", clone.Name)
+	status.Prop = map[string]string{model.PropPending: "true"}
+	report(status)
+}
+
+// deploymentRolledOut tells if all the replicas of the latest Deployment revision are available, the same way
+// `kubectl rollout status` does.
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	return deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.Replicas <= deployment.Status.UpdatedReplicas &&
+		deployment.Status.AvailableReplicas >= deployment.Status.UpdatedReplicas
 }
 
 func actionDeleteDeployment(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
@@ -199,13 +246,13 @@ func actionDeleteDeployment(ctx model.SessionContext, report model.ModificatorSt
 	report(model.ModificatorStatus{LocatorStatus: resource, Success: true})
 }
 
-func cloneDeployment(engine template.Engine, deployment *appsv1.Deployment, ref model.Ref, version string) (*appsv1.Deployment, error) {
+func cloneDeployment(engine template.Engine, deployment *appsv1.Deployment, ref model.Ref, version, versionLabel string) (*appsv1.Deployment, error) {
 	originalDeployment, err := json.Marshal(deployment)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed reading deployment json")
 	}
 
-	modifiedDeployment, err := engine.Run(ref.Strategy, originalDeployment, version, ref.Args)
+	modifiedDeployment, err := engine.Run(ref.Strategy, originalDeployment, version, versionLabel, ref.Args)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to modify deployment")
 	}
@@ -2,7 +2,9 @@ package k8s_test
 
 import (
 	"context"
+	"time"
 
+	emperror "emperror.dev/errors"
 	"github.com/maistra/istio-workspace/pkg/k8s"
 	"github.com/maistra/istio-workspace/pkg/log"
 	"github.com/maistra/istio-workspace/pkg/model"
@@ -16,6 +18,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,6 +56,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 		schema := runtime.NewScheme()
 		err := appsv1.AddToScheme(schema)
 		Expect(err).ToNot(HaveOccurred())
+		Expect(v1.AddToScheme(schema)).To(Succeed())
 		c = fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(objects...).Build()
 		get = testclient.New(c)
 		ctx = model.SessionContext{
@@ -157,7 +161,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			d := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			d := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(reference.Get(&d)).To(HaveLen(1))
 		})
 
@@ -169,7 +173,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Success).To(BeTrue())
 
-			_ = get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_ = get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 		})
 
 		It("should remove liveness probe from cloned deployment", func() {
@@ -178,7 +182,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Template.Spec.Containers[0].LivenessProbe).To(BeNil())
 		})
 
@@ -188,7 +192,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Template.Spec.Containers[0].ReadinessProbe).To(BeNil())
 		})
 
@@ -198,7 +202,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Template.Spec.Containers[0].ReadinessProbe).To(BeNil())
 			Expect(deployment.Spec.Selector.MatchLabels["version"]).To(BeEquivalentTo(model.GetSha("v1") + "-test"))
 		})
@@ -213,7 +217,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, notMatchingRef, store.Store, modificatorStore.Report)
 			Expect(modificatorStore.Stored).To(HaveLen(0))
 
-			_, err := get.DeploymentWithError(ctx.Namespace, notMatchingRef.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, err := get.DeploymentWithError(ctx.Namespace, notMatchingRef.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsNotFound(err)).To(BeTrue())
 		})
@@ -226,20 +230,20 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Selector.MatchLabels["version"]).To(BeEquivalentTo(model.GetSha("v1") + "-test"))
 
 			// when Deployment is deleted
 			err := c.Delete(ctx, &deployment)
 			Expect(err).To(Not(HaveOccurred()))
 
-			_, err = get.DeploymentWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, err = get.DeploymentWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(err).To(HaveOccurred())
 
 			// then it should be recreated on next reconcile
 			k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment = get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment = get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Selector.MatchLabels["version"]).To(BeEquivalentTo(model.GetSha("v1") + "-test"))
 		})
 
@@ -251,7 +255,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 				modificatorStore := model.ModificatorStore{}
 				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-				deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+				deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 				Expect(deployment.Spec.Template.Spec.Containers[0].ReadinessProbe).To(BeNil())
 				Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(ContainSubstring("datawire/telepresence-k8s:"))
 			})
@@ -262,7 +266,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 				modificatorStore := model.ModificatorStore{}
 				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-				deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+				deployment := get.Deployment(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 				Expect(deployment.Spec.Template.Spec.Containers[0].Env[0].Name).To(Equal("TELEPRESENCE_CONTAINER_NAMESPACE"))
 				Expect(deployment.Spec.Template.Spec.Containers[0].Env[0].ValueFrom).ToNot(BeNil())
 			})
@@ -279,12 +283,133 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 				modificatorStore := model.ModificatorStore{}
 				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-				_, err := get.DeploymentWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+				_, err := get.DeploymentWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 				Expect(err).To(HaveOccurred())
 				Expect(errors.IsNotFound(err)).To(BeTrue())
 			})
 		})
 
+		Context("rollout", func() {
+
+			var (
+				ref   model.Ref
+				store model.LocatorStore
+			)
+
+			cloneName := func() string {
+				return ref.KindName.Name + "-" + model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name)
+			}
+
+			JustBeforeEach(func() {
+				ctx.RolloutTimeout = time.Minute
+				ref = CreateTestRef("test-ref")
+				store = CreateTestLocatorStoreWithRefToBeCreated(k8s.DeploymentKind)
+			})
+
+			It("should report clone as pending until it is rolled out", func() {
+				modificatorStore := model.ModificatorStore{}
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
+
+				Expect(modificatorStore.Stored).To(HaveLen(1))
+				Expect(modificatorStore.Stored[0].Success).To(BeTrue())
+				Expect(modificatorStore.Stored[0].Prop).To(HaveKeyWithValue(model.PropPending, "true"))
+			})
+
+			It("should report clone once it is rolled out", func() {
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, (&model.ModificatorStore{}).Report)
+				clone := get.Deployment(ctx.Namespace, cloneName())
+				clone.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1, ObservedGeneration: clone.Generation}
+				Expect(c.Update(ctx, &clone)).To(Succeed())
+
+				modificatorStore := model.ModificatorStore{}
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
+
+				Expect(modificatorStore.Stored).To(HaveLen(1))
+				Expect(modificatorStore.Stored[0].Success).To(BeTrue())
+				Expect(modificatorStore.Stored[0].Prop).ToNot(HaveKey(model.PropPending))
+			})
+
+			It("should fail with the reason pods are waiting for when clone is not rolled out in time", func() {
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, (&model.ModificatorStore{}).Report)
+				clone := get.Deployment(ctx.Namespace, cloneName())
+				clone.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+				Expect(c.Update(ctx, &clone)).To(Succeed())
+				Expect(c.Create(ctx, &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: cloneName() + "-abc", Namespace: ctx.Namespace, Labels: clone.Spec.Selector.MatchLabels},
+					Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+						Name:  "app",
+						State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+					}}},
+				})).To(Succeed())
+
+				modificatorStore := model.ModificatorStore{}
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
+
+				Expect(modificatorStore.Stored).To(HaveLen(1))
+				Expect(modificatorStore.Stored[0].Success).To(BeFalse())
+				Expect(modificatorStore.Stored[0].Error.Error()).To(ContainSubstring("not available within 1m0s: " + cloneName() + "-abc/app ContainerCreating"))
+			})
+
+			It("should fail right away with the container and the reason of the crashing pods", func() {
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, (&model.ModificatorStore{}).Report)
+				clone := get.Deployment(ctx.Namespace, cloneName())
+				for _, name := range []string{"abc", "def"} {
+					Expect(c.Create(ctx, &v1.Pod{
+						ObjectMeta: metav1.ObjectMeta{Name: cloneName() + "-" + name, Namespace: ctx.Namespace, Labels: clone.Spec.Selector.MatchLabels},
+						Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+							Name:                 "app",
+							RestartCount:         3,
+							State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+							LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 2}},
+						}}},
+					})).To(Succeed())
+				}
+
+				modificatorStore := model.ModificatorStore{}
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
+
+				Expect(modificatorStore.Stored).To(HaveLen(1))
+				Expect(modificatorStore.Stored[0].Success).To(BeFalse())
+				Expect(modificatorStore.Stored[0].Error.Error()).To(Equal("cloned Deployment fails to run: container app CrashLoopBackOff"))
+				podsFailed := &k8s.PodsFailedError{}
+				Expect(emperror.As(modificatorStore.Stored[0].Error, &podsFailed)).To(BeTrue())
+				Expect(podsFailed.Failures).To(HaveLen(2))
+			})
+
+			It("should describe the restarts and logs of the crashing pods", func() {
+				failure := k8s.PodFailure{
+					Namespace:    "test",
+					Pod:          "app-abc",
+					Container:    "app",
+					Reason:       "CrashLoopBackOff",
+					Message:      "Error, terminated with exit code 2",
+					RestartCount: 3,
+					Restarted:    true,
+				}
+				logs := func(_ context.Context, namespace, pod, container string, previous bool) (string, error) {
+					Expect(previous).To(BeTrue())
+
+					return "panic: missing config\n", nil
+				}
+
+				Expect(failure.Details(ctx, logs)).To(Equal("app-abc/app CrashLoopBackOff: Error, terminated with exit code 2 (restarted 3 times), last log lines:\npanic: missing config"))
+			})
+
+			It("should label pods of the clone, so they are watched", func() {
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, (&model.ModificatorStore{}).Report)
+
+				clone := get.Deployment(ctx.Namespace, cloneName())
+				Expect(k8s.ClonePodsSelector().Matches(labels.Set(clone.Spec.Template.Labels))).To(BeTrue())
+			})
+
+			It("should mark pods of the clone as related to the session", func() {
+				k8s.DeploymentModificator(template.NewDefaultEngine())(ctx, ref, store.Store, (&model.ModificatorStore{}).Report)
+
+				clone := get.Deployment(ctx.Namespace, cloneName())
+				Expect(clone.Spec.Template.Annotations).To(HaveKeyWithValue(reference.NamespacedNameAnnotation, ctx.Namespace+"/"+ctx.Name))
+			})
+		})
+
 	})
 
 	Context("revertors", func() {
@@ -332,7 +457,7 @@ var _ = Describe("Operations for k8s Deployment kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			newName := ref.KindName.Name + "-" + model.GetCreatedVersion(store.Store, ctx.Name)
+			newName := ref.KindName.Name + "-" + model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name)
 			_, mutatedFetchErr := get.DeploymentWithError(ctx.Namespace, newName)
 			Expect(mutatedFetchErr).ToNot(HaveOccurred())
 
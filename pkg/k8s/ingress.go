@@ -0,0 +1,280 @@
+package k8s
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/naming"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// IngressKind is the k8s Kind for an Ingress.
+	IngressKind = "Ingress"
+
+	// ingressHTTPPort is the port name of the istio ingress gateway Service serving plain HTTP.
+	ingressHTTPPort = "http2"
+)
+
+var (
+	_ model.Locator              = IngressLocator
+	_ model.ModificatorRegistrar = IngressRegistrar
+
+	// routeGroupKind identifies OpenShift Routes, which take precedence over Ingresses when available.
+	routeGroupKind = schema.GroupKind{Group: "route.openshift.io", Kind: "Route"}
+
+	errIngressNotCreatedForSession = errors.Sentinel("ingress of the session host name already exists and has not been created for the session")
+)
+
+func IngressRegistrar() (client.Object, model.Modificator) {
+	return &networkingv1.Ingress{}, IngressModificator
+}
+
+// IngressName returns the name of the Ingress exposing the given host.
+func IngressName(host string) string {
+	return naming.ConcatToMax(63, strings.ReplaceAll(host, ".", "-"))
+}
+
+// IngressLocator reports an Ingress for every plain HTTP host the session is exposed at through the istio Gateway.
+// It is the vanilla Kubernetes equivalent of the OpenShift Route and is only used when Routes are not available.
+// TLS hosts are skipped, as Ingress can not pass the encrypted traffic through to the gateway.
+func IngressLocator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+	if _, err := ctx.Client.RESTMapper().RESTMapping(routeGroupKind, "v1"); err == nil { // OpenShift, Routes are used instead
+		return nil
+	}
+
+	labelKey := reference.CreateRefMarker(ctx.Name, ref.KindName.String())
+	ingresses, err := getIngresses(ctx, reference.RefMarkerMatch(labelKey))
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to get all ingresses", "ref", ref.KindName.String())
+	}
+
+	exposedHosts := []istio.ExposedHost{}
+	if !ref.Remove {
+		if exposedHosts, err = istio.GetExposedHosts(ctx, store); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to get exposed hosts", "ref", ref.KindName.String())
+		}
+	}
+
+	existingIngresses := map[string]bool{}
+	for i := range ingresses.Items {
+		ingress := ingresses.Items[i]
+		action, hash := reference.GetRefMarker(&ingress, labelKey)
+		if ref.Hash() != hash || !isExposedOverHTTP(exposedHosts, ingressHost(ingress)) {
+			report(model.LocatorStatus{
+				Resource: model.Resource{
+					Kind:      IngressKind,
+					Namespace: ingress.Namespace,
+					Name:      ingress.Name,
+				},
+				Action: model.Flip(model.StatusAction(action))})
+
+			continue
+		}
+		existingIngresses[ingressHost(ingress)] = true
+	}
+
+	for _, exposedHost := range exposedHosts {
+		if exposedHost.TLS || existingIngresses[exposedHost.Name] {
+			continue
+		}
+		report(model.LocatorStatus{
+			Resource: model.Resource{
+				Kind:      IngressKind,
+				Namespace: exposedHost.ServiceNamespace,
+				Name:      IngressName(exposedHost.Name),
+			},
+			Action: model.ActionCreate,
+			Labels: map[string]string{"host": exposedHost.Name, "service": exposedHost.ServiceName}})
+	}
+
+	return nil
+}
+
+// IngressModificator creates and removes Ingresses exposing the session hosts.
+func IngressModificator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	for _, resource := range store(IngressKind) {
+		switch resource.Action {
+		case model.ActionCreate:
+			actionCreateIngress(ctx, ref, report, resource)
+		case model.ActionDelete:
+			actionDeleteIngress(ctx, ref, report, resource)
+		case model.ActionModify, model.ActionRevert, model.ActionLocated:
+			report(model.ModificatorStatus{
+				LocatorStatus: resource,
+				Success:       false,
+				Error:         errors.Errorf("Unknown action type for modificator: %v", resource.Action)})
+		}
+	}
+}
+
+func actionCreateIngress(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	// the ingress is shared by all refs of the session, which are synced concurrently
+	err := retry.OnError(retry.DefaultRetry, istio.IsConflict, func() error {
+		return applyIngress(ctx, ref, resource)
+	})
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true,
+		Target: &model.Resource{
+			Namespace: resource.Namespace,
+			Kind:      IngressKind,
+			Name:      resource.Name}})
+}
+
+// applyIngress creates the Ingress, or marks the one created for the session before as relied on by the ref.
+func applyIngress(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus) error {
+	ingress, err := getIngress(ctx, resource.Namespace, resource.Name)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	// the one of the same name created by someone else is never taken over
+	if exists && (ingressHost(*ingress) != resource.Labels["host"] || !reference.Has(ctx.ToNamespacedName(), ingress)) {
+		return errors.WithDetails(errIngressNotCreatedForSession, "kind", IngressKind, "name", ingress.Name, "host", resource.Labels["host"])
+	}
+
+	var patch client.Patch
+	if exists {
+		patch = istio.MergeFrom(ingress.DeepCopy())
+	} else {
+		ingress = createIngress(resource)
+	}
+
+	if err = reference.Add(ctx.ToNamespacedName(), ingress); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", IngressKind, "name", ingress.Name)
+	}
+	reference.AddRefMarker(ingress, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+	if exists {
+		err = ctx.Client.Patch(ctx, ingress, patch)
+	} else {
+		err = ctx.Client.Create(ctx, ingress)
+	}
+
+	return errors.WrapIfWithDetails(err, "failed to create Ingress", "kind", IngressKind, "name", ingress.Name, "host", resource.Labels["host"])
+}
+
+func actionDeleteIngress(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return removeIngress(ctx, ref, resource)
+	})
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	// ok, removed
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true})
+}
+
+// removeIngress removes the ref marker from the Ingress, deleting it once no other ref of the session relies on it.
+func removeIngress(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus) error {
+	ingress, err := getIngress(ctx, resource.Namespace, resource.Name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) { // Not found, nothing to clean
+			return nil
+		}
+
+		return err
+	}
+
+	patch := istio.MergeFrom(ingress.DeepCopy())
+	reference.RemoveRefMarker(ingress, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
+
+	// other refs of the session still rely on the ingress, or it has not been created for the session
+	if reference.HasRefMarkers(ingress) || !reference.Has(ctx.ToNamespacedName(), ingress) {
+		err = ctx.Client.Patch(ctx, ingress, patch)
+	} else {
+		err = ctx.Client.Delete(ctx, ingress, client.Preconditions{ResourceVersion: &ingress.ResourceVersion})
+	}
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WrapWithDetails(err, "failed to delete Ingress", "kind", IngressKind, "name", ingress.Name)
+	}
+
+	return nil
+}
+
+func createIngress(resource model.LocatorStatus) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: resource.Labels["host"],
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: resource.Labels["service"],
+											Port: networkingv1.ServiceBackendPort{Name: ingressHTTPPort},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ingressHost(ingress networkingv1.Ingress) string {
+	if len(ingress.Spec.Rules) == 0 {
+		return ""
+	}
+
+	return ingress.Spec.Rules[0].Host
+}
+
+func isExposedOverHTTP(exposedHosts []istio.ExposedHost, host string) bool {
+	for _, exposedHost := range exposedHosts {
+		if exposedHost.Name == host && !exposedHost.TLS {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getIngress(ctx model.SessionContext, namespace, name string) (*networkingv1.Ingress, error) {
+	ingress := networkingv1.Ingress{}
+	err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &ingress)
+
+	return &ingress, errors.WrapWithDetails(err, "failed finding ingress in namespace", "name", name, "namespace", namespace)
+}
+
+func getIngresses(ctx model.SessionContext, opts ...client.ListOption) (*networkingv1.IngressList, error) {
+	ingresses := networkingv1.IngressList{}
+	err := ctx.Client.List(ctx, &ingresses, opts...)
+
+	return &ingresses, errors.WrapWithDetails(err, "failed listing ingresses")
+}
@@ -0,0 +1,190 @@
+package k8s_test
+
+import (
+	"context"
+
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/k8s"
+	"github.com/maistra/istio-workspace/pkg/log"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/test/testclient"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"istio.io/api/networking/v1alpha3"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Operations for k8s Ingress kind", func() {
+
+	var (
+		mapper *meta.DefaultRESTMapper
+		c      client.Client
+		ctx    model.SessionContext
+	)
+
+	ref := model.Ref{KindName: model.ParseRefKindName("customer-v1"), Namespace: "test"}
+
+	locate := func(ref model.Ref) *model.LocatorStore {
+		locators := &model.LocatorStore{}
+		if !ref.Remove {
+			locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: istio.GatewayKind, Namespace: "test", Name: "gateway"}, Action: model.ActionModify})
+		}
+		Expect(k8s.IngressLocator(ctx, ref, locators.Store, locators.Report)).To(Succeed())
+
+		return locators
+	}
+
+	modify := func(ref model.Ref, locators *model.LocatorStore) {
+		modificators := model.ModificatorStore{}
+		k8s.IngressModificator(ctx, ref, locators.Store, modificators.Report)
+		for _, stored := range modificators.Stored {
+			Expect(stored.Error).ToNot(HaveOccurred())
+		}
+	}
+
+	getIngress := func(host string) (*networkingv1.Ingress, error) {
+		ingress := networkingv1.Ingress{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: "istio-system", Name: k8s.IngressName(host)}, &ingress)
+
+		return &ingress, err
+	}
+
+	BeforeEach(func() {
+		mapper = meta.NewDefaultRESTMapper(nil)
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(networkingv1.AddToScheme(scheme)).To(Succeed())
+		Expect(istionetwork.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithRuntimeObjects(
+			&istionetwork.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gateway",
+					Namespace: "test",
+				},
+				Spec: v1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*v1alpha3.Server{
+						{
+							Port:  &v1alpha3.Port{Protocol: "HTTP", Name: "http", Number: 80},
+							Hosts: []string{"domain.com"},
+						},
+						{
+							Port:  &v1alpha3.Port{Protocol: "HTTPS", Name: "https", Number: 443},
+							Hosts: []string{"secure.com"},
+							Tls:   &v1alpha3.ServerTLSSettings{Mode: v1alpha3.ServerTLSSettings_SIMPLE, CredentialName: "secure-cert"},
+						},
+					},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "istio-ingressgateway",
+					Namespace: "istio-system",
+					Labels:    map[string]string{"istio": "ingressgateway"},
+				},
+			},
+		).Build()
+		ctx = model.SessionContext{
+			Context:   context.Background(),
+			Name:      "feature-x",
+			Namespace: "test",
+			Log:       log.CreateOperatorAwareLogger("test").WithValues("type", "k8s-ingress"),
+			Client:    c,
+		}
+	})
+
+	It("should create ingress for plain http hosts only", func() {
+		locators := locate(ref)
+		Expect(locators.Store(k8s.IngressKind)).To(HaveLen(1))
+
+		modify(ref, locators)
+
+		ingress, err := getIngress("feature-x.domain.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ingress.Spec.Rules).To(HaveLen(1))
+		Expect(ingress.Spec.Rules[0].Host).To(Equal("feature-x.domain.com"))
+		backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+		Expect(backend.Name).To(Equal("istio-ingressgateway"))
+		Expect(backend.Port.Name).To(Equal("http2"))
+
+		_, err = getIngress("feature-x.secure.com")
+		Expect(k8sErrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should delete ingress when ref is removed", func() {
+		modify(ref, locate(ref))
+
+		removed := ref
+		removed.Remove = true
+		modify(removed, locate(removed))
+
+		_, err := getIngress("feature-x.domain.com")
+		Expect(k8sErrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not take over ingress of the same name created by someone else", func() {
+		Expect(c.Create(ctx, &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: k8s.IngressName("feature-x.domain.com"), Namespace: "istio-system"},
+			Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "feature-x.domain.com"}}},
+		})).To(Succeed())
+
+		modificators := model.ModificatorStore{}
+		k8s.IngressModificator(ctx, ref, locate(ref).Store, modificators.Report)
+
+		Expect(modificators.Stored).To(HaveLen(1))
+		Expect(modificators.Stored[0].Success).To(BeFalse())
+		ingress, err := getIngress("feature-x.domain.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ingress.Labels).To(BeEmpty())
+
+		removed := ref
+		removed.Remove = true
+		modify(removed, locate(removed))
+
+		_, err = getIngress("feature-x.domain.com")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should keep ingress when other ref of the session is marked concurrently", func() {
+		modify(ref, locate(ref))
+
+		other := ctx
+		details := model.Ref{KindName: model.ParseRefKindName("details-v1"), Namespace: "test"}
+		sessionClient := &testclient.InterferingClient{Client: c, Interfere: func() {
+			k8s.IngressModificator(other, details, locate(details).Store, (&model.ModificatorStore{}).Report)
+		}}
+		ctx.Client = sessionClient
+		removed := ref
+		removed.Remove = true
+		modify(removed, locate(removed))
+
+		Expect(sessionClient.Writes).To(Equal(2))
+		_, err := getIngress("feature-x.domain.com")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("OpenShift routes are available", func() {
+
+		BeforeEach(func() {
+			mapper.Add(schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}, meta.RESTScopeNamespace)
+		})
+
+		It("should not report anything", func() {
+			Expect(locate(ref).Store(k8s.IngressKind)).To(BeEmpty())
+		})
+	})
+})
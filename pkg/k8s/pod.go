@@ -0,0 +1,243 @@
+package k8s
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PodKind is the k8s Kind for a Pod.
+	PodKind = "Pod"
+
+	// LabelIkeClone marks the pods of the workloads cloned by the session.
+	LabelIkeClone = "ike.clone"
+
+	podLogLines = 10
+	podLogBytes = 2048
+)
+
+// failingReasons are the reasons of the waiting containers which are not going to start without changing the workload.
+var failingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// PodLogsReader returns the last lines logged by the container of the pod. When previous is true the logs of the last
+// terminated instance of the container are returned.
+type PodLogsReader func(ctx context.Context, namespace, pod, container string, previous bool) (string, error)
+
+// PodFailure describes the container of the workload pod which fails to run.
+type PodFailure struct {
+	Namespace    string
+	Pod          string
+	Container    string
+	Reason       string
+	Message      string
+	RestartCount int32
+	// Restarted tells that the logs of the last terminated instance of the container explain the failure.
+	Restarted bool
+}
+
+// String describes the failure by the container and the reason only, so the description does not change while the container
+// keeps failing, e.g. restarting over and over.
+func (f PodFailure) String() string {
+	return "container " + f.Container + " " + f.Reason
+}
+
+// Details describes the failure including the message, the number of restarts and the last lines logged by the container
+// when the logs can be read.
+func (f PodFailure) Details(ctx context.Context, logs PodLogsReader) string {
+	details := describeWaiting(f.Pod+"/"+f.Container, f.Reason, f.Message)
+	if f.RestartCount > 0 {
+		details += " (restarted " + strconv.Itoa(int(f.RestartCount)) + " times)"
+	}
+	if logs == nil {
+		return details
+	}
+	lines, err := logs(ctx, f.Namespace, f.Pod, f.Container, f.Restarted)
+	if err != nil {
+		return details + ", failed reading logs: " + err.Error()
+	}
+	if lines = strings.TrimSpace(lines); lines != "" {
+		details += ", last log lines:\n" + lines
+	}
+
+	return details
+}
+
+// PodsFailedError lists the containers of the workload pods which fail to run.
+type PodsFailedError struct {
+	Failures []PodFailure
+}
+
+func (e *PodsFailedError) Error() string {
+	descriptions := []string{}
+	described := map[string]bool{}
+	for i := range e.Failures {
+		if description := e.Failures[i].String(); !described[description] {
+			described[description] = true
+			descriptions = append(descriptions, description)
+		}
+	}
+
+	return strings.Join(descriptions, "; ")
+}
+
+// PodsFailures returns the containers of the pods matching the selector which fail to run, e.g. those in ImagePullBackOff
+// or CrashLoopBackOff.
+func PodsFailures(ctx model.SessionContext, namespace string, selector map[string]string) ([]PodFailure, error) {
+	pods := corev1.PodList{}
+	if err := ctx.Client.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels(selector)); err != nil {
+		return nil, errors.WrapWithDetails(err, "failed listing pods", "namespace", namespace)
+	}
+
+	failures := []PodFailure{}
+	for i := range pods.Items {
+		pod := pods.Items[i]
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			failure := PodFailure{
+				Namespace:    pod.Namespace,
+				Pod:          pod.Name,
+				Container:    status.Name,
+				RestartCount: status.RestartCount,
+				Restarted:    status.State.Terminated == nil && status.RestartCount > 0,
+			}
+			switch {
+			case status.State.Waiting != nil && failingReasons[status.State.Waiting.Reason]:
+				failure.Reason = status.State.Waiting.Reason
+				failure.Message = status.State.Waiting.Message
+				if terminated := status.LastTerminationState.Terminated; terminated != nil {
+					failure.Message = strings.TrimPrefix(failure.Message+"; ", "; ") + describeTermination(terminated)
+				}
+			case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+				failure.Reason = status.State.Terminated.Reason
+				failure.Message = describeTermination(status.State.Terminated)
+			default:
+				continue
+			}
+			failures = append(failures, failure)
+		}
+	}
+
+	return failures, nil
+}
+
+// PodsFailed returns the PodsFailedError describing the containers of the pods matching the selector which fail to run,
+// nil when all of them are fine.
+func PodsFailed(ctx model.SessionContext, namespace string, selector map[string]string) error {
+	failures, err := PodsFailures(ctx, namespace, selector)
+	if err != nil {
+		ctx.Log.Info("Failed checking pods", "namespace", namespace, "error", err.Error())
+
+		return nil //nolint:nilerr //reason pods which cannot be checked are not considered failing
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &PodsFailedError{Failures: failures}
+}
+
+// NewPodLogsReader returns the reader of the last lines logged by the containers through the given clientset.
+func NewPodLogsReader(clientset kubernetes.Interface) PodLogsReader {
+	return func(ctx context.Context, namespace, pod, container string, previous bool) (string, error) {
+		tailLines, limitBytes := int64(podLogLines), int64(podLogBytes)
+		logs, err := clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+			Container:  container,
+			Previous:   previous,
+			TailLines:  &tailLines,
+			LimitBytes: &limitBytes,
+		}).DoRaw(ctx)
+
+		return string(logs), errors.WrapWithDetails(err, "failed reading pod logs", "pod", pod, "container", container)
+	}
+}
+
+// AddPodTemplateReference marks the pods of the cloned workload as related to the session, so the session is reconciled
+// when they change. The label lets the operator watch only the pods of the cloned workloads.
+func AddPodTemplateReference(ctx model.SessionContext, template *corev1.PodTemplateSpec) {
+	pod := &corev1.Pod{ObjectMeta: template.ObjectMeta}
+	if err := reference.Add(ctx.ToNamespacedName(), pod); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", PodKind)
+
+		return
+	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[LabelIkeClone] = "true"
+	template.ObjectMeta = pod.ObjectMeta
+}
+
+// ClonePodsSelector selects the pods of the workloads cloned by the sessions.
+func ClonePodsSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{LabelIkeClone: "true"})
+}
+
+// PodsWaitingReason describes why the pods matching the selector are not running yet, e.g. ImagePullBackOff of their
+// containers or the pods not being scheduled.
+func PodsWaitingReason(ctx model.SessionContext, namespace string, selector map[string]string) string {
+	pods := corev1.PodList{}
+	if err := ctx.Client.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels(selector)); err != nil {
+		return "failed listing pods: " + err.Error()
+	}
+	if len(pods.Items) == 0 {
+		return "no pods created"
+	}
+
+	reasons := []string{}
+	for i := range pods.Items {
+		pod := pods.Items[i]
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				reasons = append(reasons, describeWaiting(pod.Name+"/"+status.Name, status.State.Waiting.Reason, status.State.Waiting.Message))
+			}
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				reasons = append(reasons, describeWaiting(pod.Name, condition.Reason, condition.Message))
+			}
+		}
+	}
+	if len(reasons) == 0 {
+		return "pods not ready"
+	}
+
+	return strings.Join(reasons, "; ")
+}
+
+func describeTermination(terminated *corev1.ContainerStateTerminated) string {
+	description := "terminated with exit code " + strconv.Itoa(int(terminated.ExitCode))
+	if terminated.Reason != "" {
+		description = terminated.Reason + ", " + description
+	}
+	if terminated.Message != "" {
+		description += ": " + terminated.Message
+	}
+
+	return description
+}
+
+func describeWaiting(subject, reason, message string) string {
+	if message == "" {
+		return subject + " " + reason
+	}
+
+	return subject + " " + reason + ": " + message
+}
@@ -2,9 +2,15 @@ package k8s
 
 import (
 	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/pkg/istio"
 	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/reference"
 	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -16,6 +22,8 @@ const (
 var (
 	_ model.Locator              = ServiceLocator
 	_ model.ModificatorRegistrar = ServiceRegistrar
+
+	errServiceNotCreatedForSession = errors.Sentinel("service of the alias name already exists and has not been created for the session")
 )
 
 func ServiceRegistrar() (client.Object, model.Modificator) {
@@ -23,7 +31,36 @@ func ServiceRegistrar() (client.Object, model.Modificator) {
 }
 
 // ServiceLocator attempts to locate the Services for the target Deployment/DeploymentConfig.
+// When the Session asks for in-mesh alias hosts, alias Service is reported to be created for every located one.
 func ServiceLocator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+	labelKey := reference.CreateRefMarker(ctx.Name, ref.KindName.String())
+	aliases, err := getServices(ctx, ctx.Namespace, reference.RefMarkerMatch(labelKey))
+	if err != nil {
+		ctx.Log.Error(err, "could not get alias Services")
+
+		return err
+	}
+
+	createAlias := !ref.Remove && ctx.Route.Alias
+	existingAliases := map[string]bool{}
+	for i := range aliases.Items {
+		alias := aliases.Items[i]
+		action, hash := reference.GetRefMarker(&alias, labelKey)
+		if !createAlias || ref.Hash() != hash {
+			report(model.LocatorStatus{
+				Resource: model.Resource{
+					Namespace: alias.Namespace,
+					Kind:      ServiceKind,
+					Name:      alias.Name,
+				},
+				Action: model.Flip(model.StatusAction(action)),
+			})
+
+			continue
+		}
+		existingAliases[alias.Name] = true
+	}
+
 	deployments := store("Deployment", "DeploymentConfig")
 
 	services, err := getServices(ctx, ctx.Namespace)
@@ -34,6 +71,9 @@ func ServiceLocator(ctx model.SessionContext, ref model.Ref, store model.Locator
 	}
 	for _, deployment := range deployments {
 		for _, service := range services.Items { //nolint:gocritic //reason for readability
+			if service.Labels[model.LabelIkeAlias] == "true" {
+				continue
+			}
 			selector := labels.SelectorFromSet(service.Spec.Selector)
 			if selector.Matches(labels.Set(deployment.Labels)) {
 				report(model.LocatorStatus{
@@ -45,6 +85,20 @@ func ServiceLocator(ctx model.SessionContext, ref model.Ref, store model.Locator
 					Action: model.ActionLocated,
 					Labels: service.Labels,
 				})
+
+				hostName := model.HostName{Name: service.Name, Namespace: ctx.Namespace}
+				alias := hostName.Alias(ctx.Name)
+				if createAlias && !existingAliases[alias.Name] {
+					report(model.LocatorStatus{
+						Resource: model.Resource{
+							Namespace: ctx.Namespace,
+							Kind:      ServiceKind,
+							Name:      alias.Name,
+						},
+						Action: model.ActionCreate,
+						Labels: map[string]string{"service": service.Name},
+					})
+				}
 			}
 		}
 	}
@@ -52,13 +106,17 @@ func ServiceLocator(ctx model.SessionContext, ref model.Ref, store model.Locator
 	return nil
 }
 
-// ServiceModificator will set a located service to modification status true.
+// ServiceModificator will set a located service to modification status true and manages alias Services of the session.
 func ServiceModificator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
 	for _, resource := range store(ServiceKind) {
 		switch resource.Action {
 		case model.ActionLocated:
 			actionLocatedService(report, resource)
-		case model.ActionModify, model.ActionRevert, model.ActionCreate, model.ActionDelete:
+		case model.ActionCreate:
+			actionCreateAliasService(ctx, ref, report, resource)
+		case model.ActionDelete:
+			actionDeleteAliasService(ctx, report, resource)
+		case model.ActionModify, model.ActionRevert:
 			report(model.ModificatorStatus{
 				LocatorStatus: resource,
 				Success:       false,
@@ -73,9 +131,127 @@ func actionLocatedService(report model.ModificatorStatusReporter, resource model
 		Success:       true})
 }
 
-func getServices(ctx model.SessionContext, namespace string) (*corev1.ServiceList, error) {
+func actionCreateAliasService(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	service, err := getService(ctx, resource.Namespace, resource.Labels["service"])
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	alias := createAliasService(service, resource.Name)
+	if err = reference.Add(ctx.ToNamespacedName(), &alias); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", ServiceKind, "name", alias.Name)
+	}
+	reference.AddRefMarker(&alias, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+	err = ctx.Client.Create(ctx, &alias)
+	if k8sErrors.IsAlreadyExists(err) {
+		err = markAliasService(ctx, ref, resource)
+	}
+	if err != nil {
+		report(model.ModificatorStatus{
+			LocatorStatus: resource,
+			Success:       false,
+			Error:         errors.WrapWithDetails(err, "failed creating alias service", "kind", ServiceKind, "name", alias.Name, "service", service.Name)})
+
+		return
+	}
+
+	aliasHost := model.HostName{Name: alias.Name, Namespace: alias.Namespace}
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true,
+		Prop: map[string]string{
+			"hosts": aliasHost.String(),
+		},
+		Target: &model.Resource{
+			Namespace: alias.Namespace,
+			Kind:      ServiceKind,
+			Name:      alias.Name}})
+}
+
+// markAliasService adds the ref marker to the alias Service which already exists, as long as it has been created for the session.
+// Service of the same name created by someone else is never taken over.
+func markAliasService(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus) error {
+	return errors.Wrap(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		alias, err := getService(ctx, resource.Namespace, resource.Name)
+		if err != nil {
+			return err
+		}
+		if alias.Labels[model.LabelIkeAlias] != "true" || !reference.Has(ctx.ToNamespacedName(), alias) {
+			return errors.WithDetails(errServiceNotCreatedForSession, "name", alias.Name)
+		}
+
+		patch := istio.MergeFrom(alias.DeepCopy())
+		reference.AddRefMarker(alias, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+		return errors.WrapIfWithDetails(ctx.Client.Patch(ctx, alias, patch), "failed marking alias service", "kind", ServiceKind, "name", alias.Name)
+	}), "alias service already exists")
+}
+
+func actionDeleteAliasService(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	alias := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+		},
+	}
+
+	if err := ctx.Client.Delete(ctx, &alias); err != nil && !k8sErrors.IsNotFound(err) { // Not found, nothing to clean
+		report(model.ModificatorStatus{
+			LocatorStatus: resource,
+			Success:       false,
+			Error:         errors.WrapWithDetails(err, "failed deleting alias service", "kind", ServiceKind, "name", alias.Name)})
+
+		return
+	}
+
+	// ok, removed
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true})
+}
+
+// createAliasService creates Service without selector exposing the same ports as the source one. It is only used to give the alias
+// host a cluster IP resolvable by in-mesh clients, the traffic is then routed by the alias VirtualService.
+func createAliasService(source *corev1.Service, name string) corev1.Service {
+	ports := make([]corev1.ServicePort, 0, len(source.Spec.Ports))
+	for _, port := range source.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    port.Protocol,
+			AppProtocol: port.AppProtocol,
+			Port:        port.Port,
+			TargetPort:  port.TargetPort,
+		})
+	}
+
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: source.Namespace,
+			Labels: map[string]string{
+				model.LabelIkeAlias: "true",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: ports,
+		},
+	}
+}
+
+func getService(ctx model.SessionContext, namespace, name string) (*corev1.Service, error) {
+	service := corev1.Service{}
+	err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &service)
+
+	return &service, errors.WrapWithDetails(err, "failed finding service in namespace", "name", name, "namespace", namespace)
+}
+
+func getServices(ctx model.SessionContext, namespace string, opts ...client.ListOption) (*corev1.ServiceList, error) {
 	services := corev1.ServiceList{}
-	err := ctx.Client.List(ctx, &services, client.InNamespace(namespace))
+	err := ctx.Client.List(ctx, &services, append(opts, client.InNamespace(namespace))...)
 
 	return &services, errors.WrapWithDetails(err, "failed listing services in namespace", "namespace", namespace)
 }
@@ -10,8 +10,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -146,4 +149,151 @@ var _ = Describe("Operations for k8s Service kind", func() {
 		})
 
 	})
+
+	Context("aliases", func() {
+
+		var ref model.Ref
+
+		locate := func() *model.LocatorStore {
+			store := CreateTestLocatorStore(k8s.DeploymentKind, map[string]string{"app": "x"})
+			Expect(k8s.ServiceLocator(ctx, ref, store.Store, store.Report)).To(Succeed())
+
+			return &store
+		}
+
+		modify := func(store *model.LocatorStore) model.ModificatorStore {
+			modificators := model.ModificatorStore{}
+			k8s.ServiceModificator(ctx, ref, store.Store, modificators.Report)
+			for _, stored := range modificators.Stored {
+				Expect(stored.Error).ToNot(HaveOccurred())
+			}
+
+			return modificators
+		}
+
+		getAlias := func() (*corev1.Service, error) {
+			alias := corev1.Service{}
+			err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: "test", Name: "test-1-test"}, &alias)
+
+			return &alias, err
+		}
+
+		BeforeEach(func() {
+			ref = CreateTestRef()
+			objects = []runtime.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-1",
+						Namespace: "test",
+					},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{
+							"app": "x",
+						},
+						Ports: []corev1.ServicePort{
+							{Name: "http", Port: 9080, TargetPort: intstr.FromInt(9080)},
+						},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			ctx.Route.Alias = true
+		})
+
+		It("should not report alias when not requested", func() {
+			ctx.Route.Alias = false
+
+			Expect(locate().Store(k8s.ServiceKind)).To(HaveLen(1))
+		})
+
+		It("should report alias to be created without treating it as target", func() {
+			store := locate()
+
+			services := store.Store(k8s.ServiceKind)
+			Expect(services).To(HaveLen(2))
+			Expect(services).To(ContainElement(And(
+				WithTransform(func(l model.LocatorStatus) string { return l.Name }, Equal("test-1-test")),
+				WithTransform(func(l model.LocatorStatus) model.StatusAction { return l.Action }, Equal(model.ActionCreate)),
+			)))
+
+			hosts := model.GetTargetHostNames(store.Store)
+			Expect(hosts).To(HaveLen(1))
+			Expect(hosts[0].Name).To(Equal("test-1"))
+		})
+
+		It("should create alias service with the same ports", func() {
+			modificators := modify(locate())
+
+			alias, err := getAlias()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(alias.Spec.Selector).To(BeEmpty())
+			Expect(alias.Spec.Ports).To(HaveLen(1))
+			Expect(alias.Spec.Ports[0].Port).To(Equal(int32(9080)))
+			Expect(alias.Labels).To(HaveKeyWithValue(model.LabelIkeAlias, "true"))
+
+			Expect(modificators.Stored).To(ContainElement(WithTransform(func(m model.ModificatorStatus) map[string]string {
+				return m.Prop
+			}, HaveKeyWithValue("hosts", "test-1-test.test.svc.cluster.local"))))
+		})
+
+		It("should not report alias again when already created", func() {
+			modify(locate())
+
+			Expect(locate().Store(k8s.ServiceKind)).To(HaveLen(1))
+		})
+
+		It("should delete alias when reference is removed", func() {
+			modify(locate())
+
+			ref.Remove = true
+			store := locate()
+			Expect(store.Store(k8s.ServiceKind)).To(ContainElement(WithTransform(func(l model.LocatorStatus) model.StatusAction {
+				return l.Action
+			}, Equal(model.ActionDelete))))
+			modify(store)
+
+			_, err := getAlias()
+			Expect(k8sErrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should mark alias already created for the session by another ref", func() {
+			modify(locate())
+
+			ref = CreateTestRef()
+			ref.KindName = model.RefKindName{Name: "test-ref-2"}
+			modify(locate())
+
+			alias, err := getAlias()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(alias.Labels).To(HaveLen(3))
+		})
+
+		Context("when unrelated service of the alias name exists", func() {
+
+			BeforeEach(func() {
+				objects = append(objects, &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-1-test",
+						Namespace: "test",
+					},
+				})
+			})
+
+			It("should fail instead of taking it over", func() {
+				modificators := model.ModificatorStore{}
+				k8s.ServiceModificator(ctx, ref, locate().Store, modificators.Report)
+
+				Expect(modificators.Stored).To(ContainElement(And(
+					WithTransform(func(m model.ModificatorStatus) string { return m.Name }, Equal("test-1-test")),
+					WithTransform(func(m model.ModificatorStatus) bool { return m.Success }, BeFalse()),
+				)))
+
+				alias, err := getAlias()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(alias.Labels).To(BeEmpty())
+			})
+		})
+	})
 })
@@ -6,6 +6,7 @@ import (
 	"github.com/maistra/istio-workspace/pkg/model"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var _ = Describe("Operations for model object", func() {
@@ -110,4 +111,120 @@ var _ = Describe("Operations for model object", func() {
 		})
 
 	})
+
+	Context("versioning", func() {
+
+		It("should calculate created version from version label", func() {
+			store := model.LocatorStore{}
+			store.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Name: "details"}, Action: model.ActionCreate, Labels: map[string]string{"version": "v1"}})
+
+			Expect(model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, "feature-x")).To(Equal(model.GetSha("v1") + "-feature-x"))
+		})
+
+		It("should calculate session unique version when target has no version label", func() {
+			store := model.LocatorStore{}
+			store.Report(model.LocatorStatus{Resource: model.Resource{Kind: "Deployment", Name: "details"}, Action: model.ActionCreate, Labels: map[string]string{"app": "details"}})
+
+			Expect(model.GetVersion(store.Store, model.DefaultVersionLabel)).To(Equal("unknown"))
+			Expect(model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, "feature-x")).To(Equal(model.GetSha("unknown") + "-feature-x"))
+			Expect(model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, "feature-y")).ToNot(Equal(model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, "feature-x")))
+		})
+	})
+
+	Context("sync", func() {
+
+		var called []string
+
+		locateX := func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+			report(model.LocatorStatus{Resource: model.Resource{Kind: "X", Name: "x"}, Action: model.ActionCreate})
+
+			return nil
+		}
+		modificator := func(name string, status model.ModificatorStatus) model.Modificator {
+			return func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+				called = append(called, name)
+				report(status)
+			}
+		}
+		// closed by the failed or pending X, guards the second modificator only
+		gate := model.Gate{
+			Closes: func(status model.ModificatorStatus) bool {
+				return status.Kind == "X" && (!status.Success || status.Prop[model.PropPending] == "true")
+			},
+			Guards: func(modificator int) bool { return modificator == 1 },
+		}
+		sync := func(ref model.Ref, first model.ModificatorStatus) {
+			model.NewSync([]model.Locator{locateX}, []model.Modificator{
+				modificator("first", first),
+				modificator("second", model.ModificatorStatus{Success: true}),
+				modificator("third", model.ModificatorStatus{Success: true}),
+			}, gate)(model.SessionContext{}, ref,
+				func(model.LocatorStatusStore) bool { return true },
+				func(model.LocatorStatusStore) {},
+				func(model.ModificatorStatus) {})
+		}
+		x := model.LocatorStatus{Resource: model.Resource{Kind: "X", Name: "x"}, Action: model.ActionCreate}
+
+		BeforeEach(func() {
+			called = []string{}
+		})
+
+		It("should run all modificators when changes are done", func() {
+			sync(model.Ref{}, model.ModificatorStatus{LocatorStatus: x, Success: true})
+
+			Expect(called).To(Equal([]string{"first", "second", "third"}))
+		})
+
+		It("should not run guarded modificators when change is pending", func() {
+			sync(model.Ref{}, model.ModificatorStatus{LocatorStatus: x, Success: true, Prop: map[string]string{model.PropPending: "true"}})
+
+			Expect(called).To(Equal([]string{"first", "third"}))
+		})
+
+		It("should not run guarded modificators when change failed", func() {
+			sync(model.Ref{}, model.ModificatorStatus{LocatorStatus: x, Success: false})
+
+			Expect(called).To(Equal([]string{"first", "third"}))
+		})
+
+		It("should run all modificators when change of other kind failed", func() {
+			y := model.LocatorStatus{Resource: model.Resource{Kind: "Y", Name: "y"}, Action: model.ActionCreate}
+			sync(model.Ref{}, model.ModificatorStatus{LocatorStatus: y, Success: false})
+
+			Expect(called).To(Equal([]string{"first", "second", "third"}))
+		})
+
+		It("should run all modificators when ref is removed", func() {
+			sync(model.Ref{Remove: true}, model.ModificatorStatus{LocatorStatus: x, Success: false})
+
+			Expect(called).To(Equal([]string{"first", "second", "third"}))
+		})
+
+		It("should count resources left by the previous version of the ref", func() {
+			orphans := func() float64 {
+				families, err := metrics.Registry.Gather()
+				Expect(err).ToNot(HaveOccurred())
+				for _, family := range families {
+					if family.GetName() == "session_orphaned_resources_total" {
+						return family.GetMetric()[0].GetCounter().GetValue()
+					}
+				}
+
+				return 0
+			}
+			before := orphans()
+			locateLeftover := func(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+				report(model.LocatorStatus{Resource: model.Resource{Kind: "X", Name: "x-previous"}, Action: model.ActionDelete})
+
+				return nil
+			}
+
+			model.NewSync([]model.Locator{locateX, locateLeftover}, []model.Modificator{})(model.SessionContext{}, model.Ref{},
+				func(model.LocatorStatusStore) bool { return true },
+				func(model.LocatorStatusStore) {},
+				func(model.ModificatorStatus) {})
+
+			Expect(orphans()).To(Equal(before + 1))
+		})
+	})
 })
@@ -4,6 +4,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// PropPending marks the status of the change which has been made, but is not in effect yet, e.g. the cloned workload still
+// rolling out.
+const PropPending = "pending"
+
 type ModificatorStatusReporter func(ModificatorStatus)
 
 type ModificatorController func(LocatorStatusStore) bool
@@ -3,6 +3,8 @@ package model
 import (
 	"reflect"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -25,27 +27,69 @@ var (
 		},
 		resourceVectors,
 	)
+	locatorDurations = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "session_locator_duration_seconds",
+			Help: "Time it takes the locator to find the resources of the ref",
+		},
+		[]string{"locator"},
+	)
+	modificatorDurations = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "session_modificator_duration_seconds",
+			Help: "Time it takes the modificator to change the resources of the ref",
+		},
+		[]string{"modificator"},
+	)
+	orphans = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "session_orphaned_resources_total",
+			Help: "Number of resources left behind by the previous version of the ref found and reverted",
+		},
+		[]string{"source_namespace", "source_kind"},
+	)
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(resources, resourceFailures)
+	metrics.Registry.MustRegister(resources, resourceFailures, locatorDurations, modificatorDurations, orphans)
 }
 
 // Sync is the entry point for ensuring the desired state for the given Ref is up-to-date.
 type Sync func(SessionContext, Ref, ModificatorController, LocatedReporter, ModificatorStatusReporter)
 
-func NewSync(locators []Locator, modificators []Modificator) Sync {
+// Gate keeps the modificators it guards from running once a status reported before them closes it, e.g. the routes are not
+// switched to the cloned workload which is still rolling out. Gates are used only when applying the ref, the removed ones
+// are reverted completely.
+type Gate struct {
+	// Closes tells if the reported status keeps the guarded modificators from running.
+	Closes func(status ModificatorStatus) bool
+	// Guards tells if the modificator at the given position is kept from running by the closed gate.
+	Guards func(modificator int) bool
+}
+
+func NewSync(locators []Locator, modificators []Modificator, gates ...Gate) Sync {
+	locatorNames := make([]string, len(locators))
+	for i := range locators {
+		locatorNames[i] = funcName(locators[i])
+	}
+	modificatorNames := make([]string, len(modificators))
+	for i := range modificators {
+		modificatorNames[i] = funcName(modificators[i])
+	}
+
 	return func(context SessionContext, ref Ref, modify ModificatorController, locatedReporter LocatedReporter, modificationReporter ModificatorStatusReporter) {
 		instrumentedReporter := instrumentedModificationStatusReporter(modificationReporter)
 		located := LocatorStore{}
-		for _, locator := range locators {
+		for i, locator := range locators {
+			started := time.Now()
 			err := locator(
 				context,
 				ref,
 				located.Store,
 				located.Report,
 			)
+			locatorDurations.WithLabelValues(locatorNames[i]).Observe(time.Since(started).Seconds())
 
 			if err != nil {
 				context.Log.Error(err, "locating failed", "locator", runtime.FuncForPC(reflect.ValueOf(locator).Pointer()).Name())
@@ -54,13 +98,59 @@ func NewSync(locators []Locator, modificators []Modificator) Sync {
 		if !modify(located.Store) {
 			return
 		}
+		if !ref.Remove { // undo actions found when applying the ref are left by its previous version
+			for _, status := range located.Store() {
+				if status.Action == ActionDelete || status.Action == ActionRevert {
+					orphans.WithLabelValues(status.Namespace, status.Kind).Inc()
+				}
+			}
+		}
 
 		locatedReporter(located.Store)
 
-		for _, modificator := range modificators {
-			modificator(context, ref, located.Store, instrumentedReporter)
+		closed := make([]bool, len(gates))
+		gatedReporter := func(status ModificatorStatus) {
+			for g := range gates {
+				if !ref.Remove && gates[g].Closes(status) {
+					closed[g] = true
+				}
+			}
+			instrumentedReporter(status)
+		}
+		for i, modificator := range modificators {
+			if guardedByClosed(gates, closed, i) {
+				continue
+			}
+			started := time.Now()
+			modificator(context, ref, located.Store, gatedReporter)
+			modificatorDurations.WithLabelValues(modificatorNames[i]).Observe(time.Since(started).Seconds())
+		}
+	}
+}
+
+func guardedByClosed(gates []Gate, closed []bool, modificator int) bool {
+	for g := range gates {
+		if closed[g] && gates[g].Guards(modificator) {
+			return true
 		}
 	}
+
+	return false
+}
+
+// funcName returns the name of the function without its package path and closure suffixes, e.g. k8s.DeploymentModificator.
+func funcName(f interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.Trim(name[i+len(".func"):], "0123456789") != "" {
+			break
+		}
+		name = name[:i]
+	}
+
+	return strings.TrimSuffix(name, "-fm")
 }
 
 func instrumentedModificationStatusReporter(report ModificatorStatusReporter) func(ModificatorStatus) {
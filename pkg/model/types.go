@@ -7,8 +7,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/maistra/istio-workspace/pkg/naming"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -30,6 +32,9 @@ const (
 
 	// StrategyExisting holds the name of the existing strategy.
 	StrategyExisting = "existing"
+
+	// LabelIkeAlias marks resources exposing in-mesh alias host of the session.
+	LabelIkeAlias = "ike.alias"
 )
 
 func Flip(action StatusAction) StatusAction {
@@ -52,11 +57,28 @@ func Flip(action StatusAction) StatusAction {
 type SessionContext struct {
 	context.Context //nolint:containedctx //reason needs refactoring https://github.com/maistra/istio-workspace/issues/1100
 
-	Name      string
-	Namespace string
-	Route     Route
-	Client    client.Client
-	Log       logr.Logger
+	Name         string
+	Namespace    string
+	Route        Route
+	VersionLabel string
+	// VirtualServiceNamespaces lists namespaces other than the session one where VirtualServices routing to the targets
+	// can be defined, "*" stands for all the namespaces.
+	VirtualServiceNamespaces []string
+	// GitOps makes the session create its own resources only, leaving the existing ones untouched.
+	GitOps bool
+	// RolloutTimeout limits the time the cloned workloads have to become available in, zero disables waiting for them.
+	RolloutTimeout time.Duration
+	Client         client.Client
+	Log            logr.Logger
+}
+
+// GetVersionLabel returns the label key holding the version of the targets, falling back to the DefaultVersionLabel.
+func (s *SessionContext) GetVersionLabel() string {
+	if s.VersionLabel == "" {
+		return DefaultVersionLabel
+	}
+
+	return s.VersionLabel
 }
 
 // ToNamespacedName returns a types.NamespaceName object that represents this Session.
@@ -67,11 +89,21 @@ func (s *SessionContext) ToNamespacedName() types.NamespacedName {
 	}
 }
 
+const (
+	// RouteTypeHeader holds the Route Type keyword for a Header based Route strategy.
+	RouteTypeHeader = "header"
+
+	// RouteTypeBaggage holds the Route Type keyword for a W3C baggage based Route strategy.
+	RouteTypeBaggage = "baggage"
+)
+
 // Route references the strategy used to route to the target Refs.
 type Route struct {
-	Type  string
-	Name  string
-	Value string
+	Type      string
+	Name      string
+	Value     string
+	Propagate bool
+	Alias     bool
 }
 
 // Ref references the user specified Resource target and configuration.
@@ -154,6 +186,11 @@ func (h *HostName) Match(name string) bool {
 	return equalsShortName || equalsFullDNSName
 }
 
+// Alias returns the in-mesh host name routing directly to the given session.
+func (h *HostName) Alias(session string) HostName {
+	return HostName{Name: naming.ConcatToMax(63, h.Name, session), Namespace: h.Namespace}
+}
+
 // String returns the String representation of a HostName.
 func (h *HostName) String() string {
 	if h.Namespace != "" {
@@ -178,6 +215,9 @@ func GetTargetHostNames(store LocatorStatusStore) []HostName {
 	targets := store("Service")
 	hosts := make([]HostName, 0, len(targets))
 	for _, service := range targets {
+		if service.Action == ActionCreate || service.Action == ActionDelete { // session owned aliases
+			continue
+		}
 		hosts = append(hosts, HostName{Name: service.Name, Namespace: service.Namespace})
 	}
 
@@ -5,14 +5,19 @@ import (
 	"fmt"
 )
 
-const unknownVersion = "unknown"
+const (
+	unknownVersion = "unknown"
+
+	// DefaultVersionLabel is the label key holding the version of the targets, unless configured otherwise.
+	DefaultVersionLabel = "version"
+)
 
 // GetVersion returns the version for the created resources if any. Returns unknown if not found.
-func GetVersion(store LocatorStatusStore) string {
+func GetVersion(store LocatorStatusStore, versionLabel string) string {
 	targets := store("Deployment", "DeploymentConfig")
 	for _, target := range targets {
 		if target.Action != ActionDelete && target.Action != ActionRevert {
-			if val, ok := target.Labels["version"]; ok {
+			if val, ok := target.Labels[versionLabel]; ok {
 				return val
 			}
 		}
@@ -22,11 +27,11 @@ func GetVersion(store LocatorStatusStore) string {
 }
 
 // GetDeletedVersion returns the version for the deleted resources if any. Returns unknown if not found.
-func GetDeletedVersion(store LocatorStatusStore) string {
+func GetDeletedVersion(store LocatorStatusStore, versionLabel string) string {
 	targets := store("Deployment", "DeploymentConfig")
 	for _, target := range targets {
 		if target.Action == ActionDelete || target.Action == ActionRevert {
-			if val, ok := target.Labels["version"]; ok {
+			if val, ok := target.Labels[versionLabel]; ok {
 				return val
 			}
 		}
@@ -35,14 +40,17 @@ func GetDeletedVersion(store LocatorStatusStore) string {
 	return unknownVersion
 }
 
-// GetCreatedVersion returns the new calculated version for the created resources if any. Returns unknown if not found.
-func GetCreatedVersion(store LocatorStatusStore, sessionName string) string {
+// GetCreatedVersion returns the new calculated version for the created resources if any. Targets without version label
+// are treated as being of unknown version, so the created version is still unique per session. Returns unknown if not found.
+func GetCreatedVersion(store LocatorStatusStore, versionLabel, sessionName string) string {
 	targets := store("Deployment", "DeploymentConfig")
 	for _, target := range targets {
 		if target.Action != ActionDelete && target.Action != ActionRevert {
-			if val, ok := target.Labels["version"]; ok {
+			if val, ok := target.Labels[versionLabel]; ok {
 				return GetSha(val) + "-" + sessionName
 			}
+
+			return GetSha(unknownVersion) + "-" + sessionName
 		}
 	}
 
@@ -2,9 +2,11 @@ package openshift
 
 import (
 	"encoding/json"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/maistra/istio-workspace/api"
+	"github.com/maistra/istio-workspace/pkg/k8s"
 	"github.com/maistra/istio-workspace/pkg/model"
 	"github.com/maistra/istio-workspace/pkg/reference"
 	"github.com/maistra/istio-workspace/pkg/template"
@@ -133,7 +135,7 @@ func actionCreateDeploymentConfig(ctx model.SessionContext, ref model.Ref, store
 		return
 	}
 
-	deploymentClone, err := cloneDeployment(engine, deployment.DeepCopy(), ref, model.GetCreatedVersion(store, ctx.Name))
+	deploymentClone, err := cloneDeployment(engine, deployment.DeepCopy(), ref, model.GetCreatedVersion(store, ctx.GetVersionLabel(), ctx.Name), ctx.GetVersionLabel())
 	if err != nil {
 		ctx.Log.Info("Failed to clone DeploymentConfig", "name", deployment.Name)
 		report(model.ModificatorStatus{
@@ -146,16 +148,13 @@ func actionCreateDeploymentConfig(ctx model.SessionContext, ref model.Ref, store
 	if err = reference.Add(ctx.ToNamespacedName(), deploymentClone); err != nil {
 		ctx.Log.Error(err, "failed to add relation reference", "kind", deploymentClone.Kind, "name", deploymentClone.Name)
 	}
+	if deploymentClone.Spec.Template != nil {
+		k8s.AddPodTemplateReference(ctx, deploymentClone.Spec.Template)
+	}
 	reference.AddRefMarker(deploymentClone, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
 
-	if _, err = getDeploymentConfig(ctx, deploymentClone.Namespace, deploymentClone.Name); err == nil {
-		report(model.ModificatorStatus{
-			LocatorStatus: resource,
-			Success:       true,
-			Target: &model.Resource{
-				Namespace: deploymentClone.Namespace,
-				Kind:      DeploymentConfigKind,
-				Name:      deploymentClone.Name}})
+	if existing, errGet := getDeploymentConfig(ctx, deploymentClone.Namespace, deploymentClone.Name); errGet == nil {
+		reportRollout(ctx, report, resource, existing)
 
 		return
 	}
@@ -172,13 +171,55 @@ func actionCreateDeploymentConfig(ctx model.SessionContext, ref model.Ref, store
 	}
 
 	ctx.Log.Info("Cloned Deployment", "name", deploymentClone.Name)
-	report(model.ModificatorStatus{
+	reportRollout(ctx, report, resource, deploymentClone)
+}
+
+// reportRollout reports the clone as pending until it is rolled out, so no traffic is routed to it before. The clone which
+// pods fail to run, or which is not available within the rollout timeout, is reported as failed together with the reason.
+func reportRollout(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus, clone *appsv1.DeploymentConfig) {
+	status := model.ModificatorStatus{
 		LocatorStatus: resource,
 		Success:       true,
 		Target: &model.Resource{
-			Namespace: deploymentClone.Namespace,
+			Namespace: clone.Namespace,
 			Kind:      DeploymentConfigKind,
-			Name:      deploymentClone.Name}})
+			Name:      clone.Name}}
+	if err := k8s.PodsFailed(ctx, clone.Namespace, clone.Spec.Selector); err != nil {
+		status.Success = false
+		status.Error = errors.WrapWithDetails(err, "cloned DeploymentConfig fails to run", "kind", DeploymentConfigKind, "name", clone.Name)
+		report(status)
+
+		return
+	}
+	if ctx.RolloutTimeout <= 0 || deploymentConfigRolledOut(clone) {
+		report(status)
+
+		return
+	}
+
+	if !clone.CreationTimestamp.IsZero() && time.Since(clone.CreationTimestamp.Time) > ctx.RolloutTimeout {
+		status.Success = false
+		status.Error = errors.NewWithDetails("cloned DeploymentConfig not available within "+ctx.RolloutTimeout.String()+": "+
+			k8s.PodsWaitingReason(ctx, clone.Namespace, clone.Spec.Selector), "kind", DeploymentConfigKind, "name", clone.Name)
+		report(status)
+
+		return
+	}
+
+	ctx.Log.Info("Waiting for cloned DeploymentConfig rollout", "name", clone.Name)
+	status.Prop = map[string]string{model.PropPending: "true"}
+	report(status)
+}
+
+// deploymentConfigRolledOut tells if all the replicas of the latest DeploymentConfig version are available.
+func deploymentConfigRolledOut(deployment *appsv1.DeploymentConfig) bool {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false
+	}
+
+	return deployment.Status.UpdatedReplicas >= deployment.Spec.Replicas &&
+		deployment.Status.Replicas <= deployment.Status.UpdatedReplicas &&
+		deployment.Status.AvailableReplicas >= deployment.Status.UpdatedReplicas
 }
 
 func actionDeleteDeploymentConfig(ctx model.SessionContext, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
@@ -204,13 +245,13 @@ func actionDeleteDeploymentConfig(ctx model.SessionContext, report model.Modific
 	report(model.ModificatorStatus{LocatorStatus: resource, Success: true})
 }
 
-func cloneDeployment(engine template.Engine, deployment *appsv1.DeploymentConfig, ref model.Ref, version string) (*appsv1.DeploymentConfig, error) {
+func cloneDeployment(engine template.Engine, deployment *appsv1.DeploymentConfig, ref model.Ref, version, versionLabel string) (*appsv1.DeploymentConfig, error) {
 	originalDeployment, err := json.Marshal(deployment)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed reading DeploymentConfig json")
 	}
 
-	modifiedDeployment, err := engine.Run(ref.Strategy, originalDeployment, version, ref.Args)
+	modifiedDeployment, err := engine.Run(ref.Strategy, originalDeployment, version, versionLabel, ref.Args)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to modify DeploymentConfig")
 	}
@@ -172,7 +172,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			dc := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			dc := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(reference.Get(&dc)).To(HaveLen(1))
 		})
 
@@ -185,7 +185,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Success).To(BeTrue())
 
-			_ = get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_ = get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 		})
 
 		It("should remove liveness probe from cloned deployment", func() {
@@ -194,7 +194,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Template.Spec.Containers[0].LivenessProbe).To(BeNil())
 		})
 
@@ -204,7 +204,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Template.Spec.Containers[0].ReadinessProbe).To(BeNil())
 		})
 
@@ -214,7 +214,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			modificatorStore := model.ModificatorStore{}
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Selector["version"]).To(BeEquivalentTo(model.GetSha("v1") + "-test"))
 		})
 
@@ -228,7 +228,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, notMatchingRef, store.Store, modificatorStore.Report)
 			Expect(modificatorStore.Stored).To(HaveLen(0))
 
-			_, err := get.DeploymentConfigWithError(ctx.Namespace, notMatchingRef.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, err := get.DeploymentConfigWithError(ctx.Namespace, notMatchingRef.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsNotFound(err)).To(BeTrue())
 		})
@@ -241,20 +241,20 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Selector["version"]).To(BeEquivalentTo(model.GetSha("v1") + "-test"))
 
 			// when DeploymentConfig is deleted
 			err := c.Delete(ctx, &deployment)
 			Expect(err).To(Not(HaveOccurred()))
 
-			_, err = get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, err = get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(err).To(HaveOccurred())
 
 			// then it should be recreated on next reconcile
 			openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-			deployment = get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment = get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(deployment.Spec.Selector["version"]).To(BeEquivalentTo(model.GetSha("v1") + "-test"))
 		})
 
@@ -267,7 +267,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 
 				openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-				deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+				deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 				Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(ContainSubstring("datawire/telepresence-k8s:"))
 			})
 
@@ -278,7 +278,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 
 				openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-				deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+				deployment := get.DeploymentConfig(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 				Expect(deployment.Spec.Template.Spec.Containers[0].Env[0].Name).To(Equal("TELEPRESENCE_CONTAINER_NAMESPACE"))
 				Expect(deployment.Spec.Template.Spec.Containers[0].Env[0].ValueFrom).ToNot(BeNil())
 			})
@@ -296,7 +296,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 
 				openshift.DeploymentConfigModificator(template.NewDefaultEngine())(ctx, ref, store.Store, modificatorStore.Report)
 
-				_, err := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+				_, err := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 				Expect(err).To(HaveOccurred())
 				Expect(errors.IsNotFound(err)).To(BeTrue())
 			})
@@ -346,7 +346,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			_, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(mutatedFetchErr).ToNot(HaveOccurred())
 
 			// Setup deleted ref
@@ -361,7 +361,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			_, revertedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, revertedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(revertedFetchErr).To(HaveOccurred())
 			Expect(errors.IsNotFound(revertedFetchErr)).To(BeTrue())
 		})
@@ -378,7 +378,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			_, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(mutatedFetchErr).ToNot(HaveOccurred())
 
 			// Setup deleted ref
@@ -393,7 +393,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			_, revertedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, revertedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(revertedFetchErr).To(HaveOccurred())
 			Expect(errors.IsNotFound(revertedFetchErr)).To(BeTrue())
 		})
@@ -410,7 +410,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(1))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			_, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			_, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(mutatedFetchErr).ToNot(HaveOccurred())
 
 			// Setup deleted ref
@@ -428,7 +428,7 @@ var _ = Describe("Operations for openshift DeploymentConfig kind", func() {
 			Expect(modificatorStore.Stored).To(HaveLen(2))
 			Expect(modificatorStore.Stored[0].Error).ToNot(HaveOccurred())
 
-			deployment, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, ctx.Name))
+			deployment, mutatedFetchErr := get.DeploymentConfigWithError(ctx.Namespace, ref.KindName.Name+"-"+model.GetCreatedVersion(store.Store, model.DefaultVersionLabel, ctx.Name))
 			Expect(mutatedFetchErr).ToNot(HaveOccurred())
 
 			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(imageName))
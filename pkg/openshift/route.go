@@ -0,0 +1,286 @@
+package openshift
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/maistra/istio-workspace/api"
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/naming"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	routev1 "github.com/openshift/api/route/v1"
+	errorsK8s "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	api.AddToSchemes = append(api.AddToSchemes, routev1.Install)
+}
+
+const (
+	// RouteKind is the k8s Kind for a openshift Route.
+	RouteKind = "Route"
+
+	// ingressHTTPPort and ingressHTTPSPort are the port names of the istio ingress gateway Service.
+	ingressHTTPPort  = "http2"
+	ingressHTTPSPort = "https"
+)
+
+var (
+	_ model.Locator              = RouteLocator
+	_ model.ModificatorRegistrar = RouteRegistrar
+
+	errRouteNotCreatedForSession = errors.Sentinel("route of the session host name already exists and has not been created for the session")
+)
+
+func RouteRegistrar() (client.Object, model.Modificator) {
+	return &routev1.Route{}, RouteModificator
+}
+
+// RouteName returns the name of the Route exposing the given host.
+func RouteName(host string) string {
+	return naming.ConcatToMax(63, strings.ReplaceAll(host, ".", "-"))
+}
+
+// RouteLocator reports a Route for every host the session is exposed at through the istio Gateway, so the host
+// is reachable from outside of the OpenShift cluster. Routes are created next to the ingress gateway Service
+// and shared by all refs of the session, each of them keeps its own ref marker on it.
+func RouteLocator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.LocatorStatusReporter) error {
+	if !RoutesAvailable(ctx.Client) { // Not OpenShift
+		return nil
+	}
+
+	labelKey := reference.CreateRefMarker(ctx.Name, ref.KindName.String())
+	routes, err := getRoutes(ctx, reference.RefMarkerMatch(labelKey))
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to get all routes", "ref", ref.KindName.String())
+	}
+
+	exposedHosts := []istio.ExposedHost{}
+	if !ref.Remove {
+		if exposedHosts, err = istio.GetExposedHosts(ctx, store); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to get exposed hosts", "ref", ref.KindName.String())
+		}
+	}
+
+	existingRoutes := map[string]bool{}
+	for i := range routes.Items {
+		route := routes.Items[i]
+		action, hash := reference.GetRefMarker(&route, labelKey)
+		if ref.Hash() != hash || !isExposed(exposedHosts, route.Spec.Host) {
+			report(model.LocatorStatus{
+				Resource: model.Resource{
+					Kind:      RouteKind,
+					Namespace: route.Namespace,
+					Name:      route.Name,
+				},
+				Action: model.Flip(model.StatusAction(action))})
+
+			continue
+		}
+		existingRoutes[route.Spec.Host] = true
+	}
+
+	for _, exposedHost := range exposedHosts {
+		if existingRoutes[exposedHost.Name] {
+			continue
+		}
+		report(model.LocatorStatus{
+			Resource: model.Resource{
+				Kind:      RouteKind,
+				Namespace: exposedHost.ServiceNamespace,
+				Name:      RouteName(exposedHost.Name),
+			},
+			Action: model.ActionCreate,
+			Labels: exposedHostLabels(exposedHost)})
+	}
+
+	return nil
+}
+
+// RouteModificator creates and removes Routes exposing the session hosts.
+func RouteModificator(ctx model.SessionContext, ref model.Ref, store model.LocatorStatusStore, report model.ModificatorStatusReporter) {
+	for _, resource := range store(RouteKind) {
+		switch resource.Action {
+		case model.ActionCreate:
+			actionCreateRoute(ctx, ref, report, resource)
+		case model.ActionDelete:
+			actionDeleteRoute(ctx, ref, report, resource)
+		case model.ActionModify, model.ActionRevert, model.ActionLocated:
+			report(model.ModificatorStatus{
+				LocatorStatus: resource,
+				Success:       false,
+				Error:         errors.Errorf("Unknown action type for modificator: %v", resource.Action)})
+		}
+	}
+}
+
+func actionCreateRoute(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	// the route is shared by all refs of the session, which are synced concurrently
+	err := retry.OnError(retry.DefaultRetry, istio.IsConflict, func() error {
+		return applyRoute(ctx, ref, resource)
+	})
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true,
+		Target: &model.Resource{
+			Namespace: resource.Namespace,
+			Kind:      RouteKind,
+			Name:      resource.Name}})
+}
+
+// applyRoute creates the Route, or marks the one created for the session before as relied on by the ref.
+func applyRoute(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus) error {
+	route, err := getRoute(ctx, resource.Namespace, resource.Name)
+	if err != nil && !errorsK8s.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	// the one of the same name created by someone else is never taken over
+	if exists && (route.Spec.Host != resource.Labels["host"] || !reference.Has(ctx.ToNamespacedName(), route)) {
+		return errors.WithDetails(errRouteNotCreatedForSession, "kind", RouteKind, "name", route.Name, "host", resource.Labels["host"])
+	}
+
+	var patch client.Patch
+	if exists {
+		patch = istio.MergeFrom(route.DeepCopy())
+	} else {
+		route = createRoute(resource)
+	}
+
+	if err = reference.Add(ctx.ToNamespacedName(), route); err != nil {
+		ctx.Log.Error(err, "failed to add relation reference", "kind", RouteKind, "name", route.Name)
+	}
+	reference.AddRefMarker(route, reference.CreateRefMarker(ctx.Name, ref.KindName.String()), string(resource.Action), ref.Hash())
+
+	if exists {
+		err = ctx.Client.Patch(ctx, route, patch)
+	} else {
+		err = ctx.Client.Create(ctx, route)
+	}
+
+	return errors.WrapIfWithDetails(err, "failed to create Route", "kind", RouteKind, "name", route.Name, "host", resource.Labels["host"])
+}
+
+func actionDeleteRoute(ctx model.SessionContext, ref model.Ref, report model.ModificatorStatusReporter, resource model.LocatorStatus) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return removeRoute(ctx, ref, resource)
+	})
+	if err != nil {
+		report(model.ModificatorStatus{LocatorStatus: resource, Success: false, Error: err})
+
+		return
+	}
+
+	// ok, removed
+	report(model.ModificatorStatus{
+		LocatorStatus: resource,
+		Success:       true})
+}
+
+// removeRoute removes the ref marker from the Route, deleting it once no other ref of the session relies on it.
+func removeRoute(ctx model.SessionContext, ref model.Ref, resource model.LocatorStatus) error {
+	route, err := getRoute(ctx, resource.Namespace, resource.Name)
+	if err != nil {
+		if errorsK8s.IsNotFound(err) { // Not found, nothing to clean
+			return nil
+		}
+
+		return err
+	}
+
+	patch := istio.MergeFrom(route.DeepCopy())
+	reference.RemoveRefMarker(route, reference.CreateRefMarker(ctx.Name, ref.KindName.String()))
+
+	// other refs of the session still rely on the route, or it has not been created for the session
+	if reference.HasRefMarkers(route) || !reference.Has(ctx.ToNamespacedName(), route) {
+		err = ctx.Client.Patch(ctx, route, patch)
+	} else {
+		err = ctx.Client.Delete(ctx, route, client.Preconditions{ResourceVersion: &route.ResourceVersion})
+	}
+	if err != nil && !errorsK8s.IsNotFound(err) {
+		return errors.WrapWithDetails(err, "failed to delete Route", "kind", RouteKind, "name", route.Name)
+	}
+
+	return nil
+}
+
+// createRoute creates Route pointing to the ingress gateway Service. TLS is not terminated by the router,
+// so the gateway serves the host with its own certificate.
+func createRoute(resource model.LocatorStatus) *routev1.Route {
+	route := routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: resource.Labels["host"],
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: resource.Labels["service"],
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString(ingressHTTPPort),
+			},
+		},
+	}
+	if resource.Labels["tls"] == "true" {
+		route.Spec.Port.TargetPort = intstr.FromString(ingressHTTPSPort)
+		route.Spec.TLS = &routev1.TLSConfig{
+			Termination: routev1.TLSTerminationPassthrough,
+		}
+	}
+
+	return &route
+}
+
+// RoutesAvailable checks if the cluster serves OpenShift Routes.
+func RoutesAvailable(c client.Client) bool {
+	_, err := c.RESTMapper().RESTMapping(routev1.GroupVersion.WithKind(RouteKind).GroupKind(), routev1.GroupVersion.Version)
+
+	return err == nil
+}
+
+func exposedHostLabels(exposedHost istio.ExposedHost) map[string]string {
+	tls := "false"
+	if exposedHost.TLS {
+		tls = "true"
+	}
+
+	return map[string]string{"host": exposedHost.Name, "service": exposedHost.ServiceName, "tls": tls}
+}
+
+func isExposed(exposedHosts []istio.ExposedHost, host string) bool {
+	for _, exposedHost := range exposedHosts {
+		if exposedHost.Name == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getRoute(ctx model.SessionContext, namespace, name string) (*routev1.Route, error) {
+	route := routev1.Route{}
+	err := ctx.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &route)
+
+	return &route, errors.WrapWithDetails(err, "failed finding route in namespace", "name", name, "namespace", namespace)
+}
+
+func getRoutes(ctx model.SessionContext, opts ...client.ListOption) (*routev1.RouteList, error) {
+	routes := routev1.RouteList{}
+	err := ctx.Client.List(ctx, &routes, opts...)
+
+	return &routes, errors.WrapWithDetails(err, "failed listing routes")
+}
@@ -0,0 +1,273 @@
+package openshift_test
+
+import (
+	"context"
+
+	"github.com/maistra/istio-workspace/pkg/istio"
+	"github.com/maistra/istio-workspace/pkg/log"
+	"github.com/maistra/istio-workspace/pkg/model"
+	"github.com/maistra/istio-workspace/pkg/openshift"
+	"github.com/maistra/istio-workspace/pkg/reference"
+	"github.com/maistra/istio-workspace/test/testclient"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	"istio.io/api/networking/v1alpha3"
+	istionetwork "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Operations for openshift Route kind", func() {
+
+	var (
+		schema *runtime.Scheme
+		mapper *meta.DefaultRESTMapper
+		c      client.Client
+		ctx    model.SessionContext
+	)
+
+	customer := model.Ref{KindName: model.ParseRefKindName("customer-v1"), Namespace: "test"}
+	details := model.Ref{KindName: model.ParseRefKindName("details-v1"), Namespace: "test"}
+
+	locate := func(ref model.Ref) *model.LocatorStore {
+		locators := &model.LocatorStore{}
+		if !ref.Remove {
+			locators.Report(model.LocatorStatus{Resource: model.Resource{Kind: istio.GatewayKind, Namespace: "test", Name: "gateway"}, Action: model.ActionModify})
+		}
+		Expect(openshift.RouteLocator(ctx, ref, locators.Store, locators.Report)).To(Succeed())
+
+		return locators
+	}
+
+	modify := func(ref model.Ref, locators *model.LocatorStore) {
+		modificators := model.ModificatorStore{}
+		openshift.RouteModificator(ctx, ref, locators.Store, modificators.Report)
+		for _, stored := range modificators.Stored {
+			Expect(stored.Error).ToNot(HaveOccurred())
+		}
+	}
+
+	getRoute := func(host string) (*routev1.Route, error) {
+		route := routev1.Route{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: "istio-system", Name: openshift.RouteName(host)}, &route)
+
+		return &route, err
+	}
+
+	BeforeEach(func() {
+		schema = runtime.NewScheme()
+		Expect(corev1.AddToScheme(schema)).To(Succeed())
+		Expect(istionetwork.AddToScheme(schema)).To(Succeed())
+		Expect(routev1.Install(schema)).To(Succeed())
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(routev1.GroupVersion.WithKind(openshift.RouteKind), meta.RESTScopeNamespace)
+	})
+
+	JustBeforeEach(func() {
+		c = fake.NewClientBuilder().WithScheme(schema).WithRESTMapper(mapper).WithRuntimeObjects(
+			&istionetwork.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gateway",
+					Namespace: "test",
+				},
+				Spec: v1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*v1alpha3.Server{
+						{
+							Port:  &v1alpha3.Port{Protocol: "HTTP", Name: "http", Number: 80},
+							Hosts: []string{"domain.com"},
+						},
+						{
+							Port:  &v1alpha3.Port{Protocol: "HTTPS", Name: "https", Number: 443},
+							Hosts: []string{"secure.com"},
+							Tls:   &v1alpha3.ServerTLSSettings{Mode: v1alpha3.ServerTLSSettings_SIMPLE, CredentialName: "secure-cert"},
+						},
+					},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "istio-ingressgateway",
+					Namespace: "istio-system",
+					Labels:    map[string]string{"istio": "ingressgateway"},
+				},
+			},
+		).Build()
+		ctx = model.SessionContext{
+			Context:   context.Background(),
+			Name:      "feature-x",
+			Namespace: "test",
+			Log:       log.CreateOperatorAwareLogger("test").WithValues("type", "openshift-route"),
+			Client:    c,
+		}
+	})
+
+	Context("locators", func() {
+
+		It("should report route for every exposed host next to ingress service", func() {
+			routes := locate(customer).Store(openshift.RouteKind)
+
+			Expect(routes).To(HaveLen(2))
+			for _, route := range routes {
+				Expect(route.Action).To(Equal(model.ActionCreate))
+				Expect(route.Namespace).To(Equal("istio-system"))
+			}
+			Expect([]string{routes[0].Labels["host"], routes[1].Labels["host"]}).To(ConsistOf("feature-x.domain.com", "feature-x.secure.com"))
+		})
+
+		It("should not report routes already in place", func() {
+			modify(customer, locate(customer))
+
+			Expect(locate(customer).Store(openshift.RouteKind)).To(BeEmpty())
+		})
+
+		It("should report delete when ref is removed", func() {
+			modify(customer, locate(customer))
+
+			removed := customer
+			removed.Remove = true
+			routes := locate(removed).Store(openshift.RouteKind)
+
+			Expect(routes).To(HaveLen(2))
+			Expect(routes[0].Action).To(Equal(model.ActionDelete))
+			Expect(routes[1].Action).To(Equal(model.ActionDelete))
+		})
+
+		When("routes are not available", func() {
+
+			BeforeEach(func() {
+				schema = runtime.NewScheme()
+				Expect(corev1.AddToScheme(schema)).To(Succeed())
+				Expect(istionetwork.AddToScheme(schema)).To(Succeed())
+				mapper = meta.NewDefaultRESTMapper(nil)
+			})
+
+			It("should not report anything", func() {
+				Expect(locate(customer).Store(openshift.RouteKind)).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("modificators", func() {
+
+		It("should create route to the ingress gateway http port", func() {
+			modify(customer, locate(customer))
+
+			route, err := getRoute("feature-x.domain.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Spec.Host).To(Equal("feature-x.domain.com"))
+			Expect(route.Spec.To.Kind).To(Equal("Service"))
+			Expect(route.Spec.To.Name).To(Equal("istio-ingressgateway"))
+			Expect(route.Spec.Port.TargetPort.String()).To(Equal("http2"))
+			Expect(route.Spec.TLS).To(BeNil())
+		})
+
+		It("should pass TLS traffic through to the ingress gateway", func() {
+			modify(customer, locate(customer))
+
+			route, err := getRoute("feature-x.secure.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Spec.Port.TargetPort.String()).To(Equal("https"))
+			Expect(route.Spec.TLS.Termination).To(Equal(routev1.TLSTerminationPassthrough))
+		})
+
+		It("should keep route until last ref is removed", func() {
+			modify(customer, locate(customer))
+			modify(details, locate(details))
+
+			removedCustomer := customer
+			removedCustomer.Remove = true
+			modify(removedCustomer, locate(removedCustomer))
+			_, err := getRoute("feature-x.domain.com")
+			Expect(err).ToNot(HaveOccurred())
+
+			removedDetails := details
+			removedDetails.Remove = true
+			modify(removedDetails, locate(removedDetails))
+			_, err = getRoute("feature-x.domain.com")
+			Expect(k8sErrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should not take over route of the same name created by someone else", func() {
+			Expect(c.Create(ctx, &routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{Name: openshift.RouteName("feature-x.domain.com"), Namespace: "istio-system"},
+				Spec:       routev1.RouteSpec{Host: "feature-x.domain.com", To: routev1.RouteTargetReference{Kind: "Service", Name: "frontend"}},
+			})).To(Succeed())
+
+			modificators := model.ModificatorStore{}
+			openshift.RouteModificator(ctx, customer, locate(customer).Store, modificators.Report)
+
+			Expect(modificators.Stored).To(ContainElement(And(
+				WithTransform(func(m model.ModificatorStatus) string { return m.Name }, Equal(openshift.RouteName("feature-x.domain.com"))),
+				WithTransform(func(m model.ModificatorStatus) bool { return m.Success }, BeFalse()),
+			)))
+			route, err := getRoute("feature-x.domain.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Labels).To(BeEmpty())
+			Expect(route.Spec.To.Name).To(Equal("frontend"))
+		})
+
+		It("should not delete route which has not been created for the session", func() {
+			Expect(c.Create(ctx, &routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      openshift.RouteName("feature-x.domain.com"),
+					Namespace: "istio-system",
+					// marked by a previous version of the operator which took the route over
+					Labels: map[string]string{"maistra.io." + reference.CreateRefMarker("feature-x", customer.KindName.String()): "create-" + customer.Hash()},
+				},
+				Spec: routev1.RouteSpec{Host: "feature-x.domain.com", To: routev1.RouteTargetReference{Kind: "Service", Name: "frontend"}},
+			})).To(Succeed())
+
+			removed := customer
+			removed.Remove = true
+			modify(removed, locate(removed))
+
+			route, err := getRoute("feature-x.domain.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Labels).To(BeEmpty())
+		})
+
+		It("should mark route created concurrently by other ref of the session", func() {
+			other := ctx
+			sessionClient := &testclient.InterferingClient{Client: c, Interfere: func() {
+				openshift.RouteModificator(other, details, locate(details).Store, (&model.ModificatorStore{}).Report)
+			}}
+			ctx.Client = sessionClient
+
+			modify(customer, locate(customer))
+			Expect(sessionClient.Writes).To(Equal(3))
+
+			ctx.Client = c
+			removedDetails := details
+			removedDetails.Remove = true
+			modify(removedDetails, locate(removedDetails))
+			_, err := getRoute("feature-x.domain.com")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should keep route when other ref of the session is marked concurrently", func() {
+			modify(customer, locate(customer))
+
+			other := ctx
+			sessionClient := &testclient.InterferingClient{Client: c, Interfere: func() {
+				openshift.RouteModificator(other, details, locate(details).Store, (&model.ModificatorStore{}).Report)
+			}}
+			ctx.Client = sessionClient
+			removedCustomer := customer
+			removedCustomer.Remove = true
+			modify(removedCustomer, locate(removedCustomer))
+
+			_, err := getRoute("feature-x.domain.com")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = getRoute("feature-x.secure.com")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -213,6 +213,17 @@ func Get(object client.Object) []types.NamespacedName {
 	return typeNames
 }
 
+// Has checks if the object holds the reference to the given owner, e.g. was created for the session.
+func Has(owner types.NamespacedName, object client.Object) bool {
+	for _, ref := range Get(object) {
+		if ref == owner {
+			return true
+		}
+	}
+
+	return false
+}
+
 // addToQueue adds a slice of Reconcile Requests to the queue.
 func (e *EnqueueRequestForAnnotation) addToQueue(q workqueue.RateLimitingInterface, requests []reconcile.Request) {
 	for _, request := range requests {
@@ -119,4 +119,13 @@ var _ = Describe("Enqueue Annotations", func() {
 		Expect(typeNames[0].String()).To(Equal("test/session1"))
 		Expect(typeNames[1].String()).To(Equal("test/session2"))
 	})
+
+	It("should tell if reference exists", func() {
+		// given
+		reference.Add(types.NamespacedName{Namespace: "test", Name: "session1"}, deployment)
+
+		// then
+		Expect(reference.Has(types.NamespacedName{Namespace: "test", Name: "session1"}, deployment)).To(BeTrue())
+		Expect(reference.Has(types.NamespacedName{Namespace: "test", Name: "session2"}, deployment)).To(BeFalse())
+	})
 })
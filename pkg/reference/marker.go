@@ -55,3 +55,14 @@ func RemoveRefMarker(object client.Object, session string) {
 	delete(labels, prefix+session)
 	object.SetLabels(labels)
 }
+
+// HasRefMarkers checks if any session specific label is still set on a given object.
+func HasRefMarkers(object client.Object) bool {
+	for key := range object.GetLabels() {
+		if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "-X") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -17,6 +17,10 @@ const TemplatePath = "TEMPLATE_PATH"
 
 var (
 	errInvalidPath = fmt.Errorf("given path is not valid")
+
+	// jsonPointerEscaper and jsonPointerUnescaper handle the '~' and '/' characters within a json path segment, see RFC 6901.
+	jsonPointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
 )
 
 func loadPatches(tplFolder string) []Patch {
@@ -85,9 +89,15 @@ type JSON map[string]interface{}
 
 // Context contain the template context used during conversion. Holds template variables and data.
 type Context struct {
-	NewVersion string
-	Data       JSON
-	Vars       map[string]string
+	NewVersion   string
+	VersionLabel string
+	Data         JSON
+	Vars         map[string]string
+}
+
+// VersionLabelPath returns the version label key escaped to be used as a segment of a json path, e.g. app.kubernetes.io~1version.
+func (c Context) VersionLabelPath() string {
+	return jsonPointerEscaper.Replace(c.VersionLabel)
 }
 
 // Patch is a named JSON Patch and it's defined default variables.
@@ -102,7 +112,7 @@ type Patches []Patch
 
 // Engine is a interface that describes a way to prepare the Deployment for cloning.
 type Engine interface {
-	Run(name string, resource []byte, newVersion string, variables map[string]string) ([]byte, error)
+	Run(name string, resource []byte, newVersion, versionLabel string, variables map[string]string) ([]byte, error)
 }
 
 // PatchEngine is a reusable instance with a configured set of patch templates to manipulate the Deployment object via json patches.
@@ -119,6 +129,7 @@ func (t JSON) Value(path string) (interface{}, error) {
 	parts = parts[1:]
 	var level interface{} = t
 	for i, part := range parts {
+		part = jsonPointerUnescaper.Replace(part)
 		var l interface{}
 		switch v := level.(type) {
 		case map[string]interface{}:
@@ -169,7 +180,7 @@ func (t JSON) Equal(path string, compare interface{}) bool {
 }
 
 // Run performs the template transformation of a given json structure.
-func (e patchEngine) Run(name string, resource []byte, newVersion string, variables map[string]string) ([]byte, error) {
+func (e patchEngine) Run(name string, resource []byte, newVersion, versionLabel string, variables map[string]string) ([]byte, error) {
 	t, err := parseTemplate(e.patches)
 	if err != nil {
 		return nil, err
@@ -196,9 +207,10 @@ func (e patchEngine) Run(name string, resource []byte, newVersion string, variab
 	}
 
 	c := Context{
-		Data:       resourceData,
-		NewVersion: newVersion,
-		Vars:       patchVariables,
+		Data:         resourceData,
+		NewVersion:   newVersion,
+		VersionLabel: versionLabel,
+		Vars:         patchVariables,
 	}
 
 	// Run Template
@@ -89,6 +89,11 @@ var _ = Describe("Operations for template system", func() {
 				Expect(v).To(BeTrue())
 			})
 
+			It("should unescape json pointer characters in path", func() {
+				v := tj.Equal("/metadata/annotations/deployment.kubernetes.io~1revision", "1")
+				Expect(v).To(BeTrue())
+			})
+
 			It("should error on non numeric slice index", func() {
 				_, err := tj.Value("/spec/template/spec/containers/X")
 				Expect(err).To(HaveOccurred())
@@ -102,7 +107,7 @@ var _ = Describe("Operations for template system", func() {
 			It("happy, happy, basic DefaultEngine", func() {
 				e := template.NewDefaultEngine()
 
-				o, err := e.Run("telepresence", []byte(testDeployment), "1000", map[string]string{
+				o, err := e.Run("telepresence", []byte(testDeployment), "1000", "version", map[string]string{
 					"version": "x-x-v",
 				})
 				Expect(err).ToNot(HaveOccurred())
@@ -115,7 +120,7 @@ var _ = Describe("Operations for template system", func() {
 			It("should fail when no version is provided", func() {
 				e := template.NewDefaultEngine()
 
-				_, err := e.Run("telepresence", []byte(testDeployment), "1000", map[string]string{})
+				_, err := e.Run("telepresence", []byte(testDeployment), "1000", "version", map[string]string{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("expected version variable to be set"))
 			})
@@ -125,7 +130,7 @@ var _ = Describe("Operations for template system", func() {
 			It("happy, happy, basic DefaultEngine", func() {
 				e := template.NewDefaultEngine()
 
-				o, err := e.Run("prepared-image", []byte(testDeployment), "1000", map[string]string{
+				o, err := e.Run("prepared-image", []byte(testDeployment), "1000", "version", map[string]string{
 					"image": "maistra.org/test-image:test",
 				})
 				Expect(err).ToNot(HaveOccurred())
@@ -134,6 +139,20 @@ var _ = Describe("Operations for template system", func() {
 				Expect(string(o)).To(ContainSubstring("COMMAND"))
 				Expect(string(o)).To(ContainSubstring("ARGS"))
 			})
+
+			It("should set configured version label", func() {
+				e := template.NewDefaultEngine()
+
+				o, err := e.Run("prepared-image", []byte(testDeployment), "1000", "app.kubernetes.io/version", map[string]string{
+					"image": "maistra.org/test-image:test",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				clone, err := template.NewJSON(o)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clone.Equal("/spec/template/metadata/labels/app.kubernetes.io~1version", "1000")).To(BeTrue())
+				Expect(clone.Equal("/spec/template/metadata/labels/version", "v1")).To(BeTrue())
+			})
 		})
 
 		Context("object validation", func() {
@@ -142,7 +161,7 @@ var _ = Describe("Operations for template system", func() {
 					Name:     "test",
 					Template: []byte("{"),
 				}})
-				_, err := e.Run("test", []byte("{}"), "x", map[string]string{})
+				_, err := e.Run("test", []byte("{}"), "x", "version", map[string]string{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unexpected end of JSON input"))
 			})
@@ -152,7 +171,7 @@ var _ = Describe("Operations for template system", func() {
 					Name:     "test",
 					Template: []byte("[]"),
 				}})
-				_, err := e.Run("test", []byte("{"), "x", map[string]string{})
+				_, err := e.Run("test", []byte("{"), "x", "version", map[string]string{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unexpected end of JSON input"))
 			})
@@ -166,7 +185,7 @@ var _ = Describe("Operations for template system", func() {
 					Template:  []byte(`[ {"op": "remove", "path": "/version"} ]`),
 					Variables: map[string]string{},
 				}})
-				o, err := e.Run("test", []byte(`{"version": "100"}`), "x", map[string]string{})
+				o, err := e.Run("test", []byte(`{"version": "100"}`), "x", "version", map[string]string{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(string(o)).ToNot(ContainSubstring("version"))
 			})
@@ -177,7 +196,7 @@ var _ = Describe("Operations for template system", func() {
 					Template:  []byte(`[ {"op": "remove", "path": "/test"} ]`),
 					Variables: map[string]string{},
 				}})
-				_, err := e.Run("test", []byte(`{"version": "100"}`), "x", map[string]string{})
+				_, err := e.Run("test", []byte(`{"version": "100"}`), "x", "version", map[string]string{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("nonexistent key: test"))
 			})
@@ -193,7 +212,7 @@ var _ = Describe("Operations for template system", func() {
 						"Version": "DEFAULT_VERSION",
 					},
 				}})
-				o, err := e.Run("test", []byte(`{"version": "100"}`), "x", map[string]string{})
+				o, err := e.Run("test", []byte(`{"version": "100"}`), "x", "version", map[string]string{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(string(o)).To(ContainSubstring("DEFAULT_VERSION"))
 			})
@@ -206,7 +225,7 @@ var _ = Describe("Operations for template system", func() {
 						"Version": "DEFAULT_VERSION",
 					},
 				}})
-				o, err := e.Run("test", []byte(`{"version": "100"}`), "x", map[string]string{
+				o, err := e.Run("test", []byte(`{"version": "100"}`), "x", "version", map[string]string{
 					"Version": "PROVIDED_VERSION",
 				})
 				Expect(err).ToNot(HaveOccurred())
@@ -26,6 +26,7 @@ func New(c client.Client) *Getters {
 		Gateway:                   Gateway(c),
 		DestinationRule:           DestinationRule(c),
 		DestinationRules:          DestinationRules(c),
+		EnvoyFilter:               EnvoyFilter(c),
 		VirtualService:            VirtualService(c),
 		VirtualServices:           VirtualServices(c),
 		Deployment:                Deployment(c),
@@ -42,6 +43,7 @@ type Getters struct {
 	Gateway                   func(namespace, name string) istionetwork.Gateway
 	DestinationRule           func(namespace, name string) istionetwork.DestinationRule
 	DestinationRules          func(namespace string, predicates ...Predicate) istionetwork.DestinationRuleList
+	EnvoyFilter               func(namespace, name string) istionetwork.EnvoyFilter
 	VirtualService            func(namespace, name string) istionetwork.VirtualService
 	Deployment                func(namespace, name string) appsv1.Deployment
 	DeploymentWithError       func(namespace, name string) (appsv1.Deployment, error)
@@ -93,6 +95,17 @@ func DestinationRule(c client.Client) func(namespace, name string) istionetwork.
 	}
 }
 
+// EnvoyFilter returns an envoyfilter by name in a given namespace.
+func EnvoyFilter(c client.Client) func(namespace, name string) istionetwork.EnvoyFilter {
+	return func(namespace, name string) istionetwork.EnvoyFilter {
+		s := istionetwork.EnvoyFilter{}
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, &s)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return s
+	}
+}
+
 type Predicate func(c client.Object) bool
 
 var HasRefPredicate Predicate = func(c client.Object) bool {
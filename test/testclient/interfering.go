@@ -0,0 +1,40 @@
+package testclient
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InterferingClient changes the resource right before the first write, the same way other sessions, refs of the same
+// session or controllers writing at the same time would.
+type InterferingClient struct {
+	client.Client
+	Interfere func()
+	Writes    int
+}
+
+func (i *InterferingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	i.write()
+
+	return i.Client.Create(ctx, obj, opts...)
+}
+
+func (i *InterferingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	i.write()
+
+	return i.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (i *InterferingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	i.write()
+
+	return i.Client.Delete(ctx, obj, opts...)
+}
+
+func (i *InterferingClient) write() {
+	if i.Writes == 0 {
+		i.Interfere()
+	}
+	i.Writes++
+}